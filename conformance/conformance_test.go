@@ -18,15 +18,18 @@ limitations under the License.
 package conformance_test
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
 	"testing"
 
-	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	"sigs.k8s.io/gateway-api/conformance/tests"
 	"sigs.k8s.io/gateway-api/conformance/utils/flags"
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/suite"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
 
@@ -35,23 +38,71 @@ func TestConformance(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Error loading Kubernetes config: %v", err)
 	}
-	client, err := client.New(cfg, client.Options{})
-	if err != nil {
-		t.Fatalf("Error initializing Kubernetes client: %v", err)
+
+	var skipTests []string
+	if *flags.SkipTests != "" {
+		skipTests = strings.Split(*flags.SkipTests, ",")
 	}
-	v1alpha2.AddToScheme(client.Scheme())
 
-	t.Logf("Running conformance tests with %s GatewayClass", *flags.GatewayClassName)
+	var profiles []suite.ConformanceProfileName
+	if *flags.ConformanceProfiles != "" {
+		for _, profile := range strings.Split(*flags.ConformanceProfiles, ",") {
+			profiles = append(profiles, suite.ConformanceProfileName(profile))
+		}
+	}
 
-	cSuite := suite.New(suite.Options{
-		Client:               client,
+	options := suite.Options{
+		RESTConfig:           cfg,
 		GatewayClassName:     *flags.GatewayClassName,
 		Debug:                *flags.ShowDebug,
 		CleanupBaseResources: *flags.CleanupBaseResources,
+		CleanupOnFailure:     flags.CleanupOnFailure,
+		WaitForCleanup:       *flags.WaitForCleanup,
+		DiffApplied:          *flags.DiffApplied,
+		RunTest:              *flags.RunTest,
+		SkipTests:            skipTests,
+		FailFast:             *flags.FailFast,
+		MaxParallel:          *flags.MaxParallel,
+		Shuffle:              *flags.Shuffle,
+		Seed:                 *flags.Seed,
+		DryRun:               *flags.DryRun,
+		CollectMetrics:       *flags.CollectMetrics,
+		ReportOutput:         *flags.ReportOutput,
+		RerunFailedFrom:      *flags.RerunFailedFrom,
+		AuditLeaks:           *flags.AuditLeaks,
+		AutoDetectFeatures:   *flags.AutoDetectFeatures,
+		SlowestTests:         *flags.SlowestTests,
+		SlowTestThreshold:    *flags.SlowTestThreshold,
+		AddressType:          kubernetes.AddressFamily(*flags.AddressType),
 		SupportedFeatures: []suite.SupportedFeature{
-			suite.SupportReferencePolicy,
+			suite.SupportReferenceGrant,
 		},
-	})
-	cSuite.Setup(t)
-	cSuite.Run(t, tests.ConformanceTests)
+		ConformanceProfiles: profiles,
+	}
+
+	// Interrupting the run aborts it, still cleaning up the resources the
+	// suite created.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *flags.GatewayClassNames != "" {
+		options.GatewayClassNames = strings.Split(*flags.GatewayClassNames, ",")
+		t.Logf("Running conformance tests with %s GatewayClasses", strings.Join(options.GatewayClassNames, ", "))
+
+		suites, err := suite.NewPerGatewayClass(options)
+		if err != nil {
+			t.Fatalf("Error initializing conformance suites: %v", err)
+		}
+		suite.RunPerGatewayClass(ctx, t, suites, tests.ConformanceTests)
+		return
+	}
+
+	t.Logf("Running conformance tests with %s GatewayClass", *flags.GatewayClassName)
+	cSuite, err := suite.New(options)
+	if err != nil {
+		t.Fatalf("Error initializing conformance suite: %v", err)
+	}
+
+	cSuite.SetupWithContext(ctx, t)
+	cSuite.RunWithContext(ctx, t, tests.ConformanceTests)
 }
@@ -17,12 +17,12 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/gateway-api/conformance/utils/http"
-	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/suite"
 )
 
@@ -31,17 +31,19 @@ func init() {
 }
 
 var HTTPRouteCrossNamespace = suite.ConformanceTest{
-	ShortName:   "HTTPRouteCrossNamespace",
-	Description: "A single HTTPRoute in the gateway-conformance-web-backend namespace should attach to Gateway in another namespace",
-	Manifests:   []string{"tests/httproute-cross-namespace.yaml"},
-	MinChannel:  suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteCrossNamespace",
+	Description:           "A single HTTPRoute in the gateway-conformance-web-backend namespace should attach to Gateway in another namespace",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-cross-namespace.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		routeNN := types.NamespacedName{Name: "cross-namespace", Namespace: "gateway-conformance-web-backend"}
 		gwNN := types.NamespacedName{Name: "backend-namespaces", Namespace: "gateway-conformance-infra"}
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, suite.Client, suite.ControllerName, gwNN, routeNN)
+		gwAddr := suite.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routeNN)
 
 		t.Run("Simple HTTP request should reach web-backend", func(t *testing.T) {
-			http.MakeRequestAndExpectEventuallyConsistentResponse(t, suite.RoundTripper, gwAddr, http.ExpectedResponse{
+			http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, suite.RoundTripper, gwAddr, http.ExpectedResponse{
 				Request:    http.ExpectedRequest{Path: "/"},
 				StatusCode: 200,
 				Backend:    "web-backend",
@@ -33,14 +33,16 @@ func init() {
 }
 
 var HTTPRouteDisallowedKind = suite.ConformanceTest{
-	ShortName:   "HTTPRouteDisallowedKind",
-	Description: "A single HTTPRoute in the gateway-conformance-infra namespace should fail to attach to a Listener that does not allow the HTTPRoute kind",
-	Manifests:   []string{"tests/httproute-disallowed-kind.yaml"},
-	MinChannel:  suite.ExperimentalChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteDisallowedKind",
+	Description:           "A single HTTPRoute in the gateway-conformance-infra namespace should fail to attach to a Listener that does not allow the HTTPRoute kind",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-disallowed-kind.yaml"},
+	MinChannel:            suite.ExperimentalChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		// This test creates an additional Gateway in the gateway-conformance-infra
 		// namespace so we have to wait for it to be ready.
-		kubernetes.NamespacesMustBeReady(t, suite.Client, []string{"gateway-conformance-infra"}, 300)
+		kubernetes.NamespacesMustBeReadyWithContext(ctx, t, suite.APIReader, suite.TimeoutConfig, []string{"gateway-conformance-infra"})
 
 		routeName := types.NamespacedName{Name: "disallowed-kind", Namespace: "gateway-conformance-infra"}
 		gwName := types.NamespacedName{Name: "tlsroutes-only", Namespace: "gateway-conformance-infra"}
@@ -50,12 +52,12 @@ var HTTPRouteDisallowedKind = suite.ConformanceTest{
 		// but that is also unlikely to be universally achievable.
 		t.Run("Route should not have Parents set in status", func(t *testing.T) {
 			parents := []v1alpha2.RouteParentStatus{}
-			kubernetes.HTTPRouteMustHaveParents(t, suite.Client, routeName, parents, true, 60)
+			kubernetes.HTTPRouteMustHaveParentsWithContext(ctx, t, suite.APIReader, suite.TimeoutConfig, routeName, parents, true)
 		})
 
 		t.Run("Gateway should have 0 Routes attached", func(t *testing.T) {
 			gw := &v1alpha2.Gateway{}
-			err := suite.Client.Get(context.TODO(), gwName, gw)
+			err := suite.APIReader.Get(context.TODO(), gwName, gw)
 			require.NoError(t, err, "error fetching Gateway")
 			// There are two valid ways to represent this:
 			// 1. No listeners in status
@@ -17,6 +17,7 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -34,12 +35,14 @@ func init() {
 var HTTPRouteInvalidCrossNamespaceBackendRef = suite.ConformanceTest{
 	ShortName:   "HTTPRouteInvalidCrossNamespaceBackendRef",
 	Description: "A single HTTPRoute in the gateway-conformance-infra namespace should set a ResolvedRefs status False with reason RefNotPermitted when attempting to bind to a Gateway in the same namespace if the route has a BackendRef Service in the gateway-conformance-web-backend namespace and a ReferencePolicy granting permission to route to that Service does not exist",
+	Resources:   []string{"HTTPRoute", "ReferencePolicy"},
 	Exemptions: []suite.ExemptFeature{
-		suite.ExemptReferencePolicy,
+		suite.ExemptReferenceGrant,
 	},
-	Manifests:  []string{"tests/httproute-invalid-cross-namespace-backend-ref.yaml"},
-	MinChannel: suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	Manifests:             []string{"tests/httproute-invalid-cross-namespace-backend-ref.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		routeNN := types.NamespacedName{Name: "invalid-cross-namespace-backend-ref", Namespace: "gateway-conformance-infra"}
 		gwNN := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
 
@@ -65,7 +68,7 @@ var HTTPRouteInvalidCrossNamespaceBackendRef = suite.ConformanceTest{
 				}},
 			}}
 
-			kubernetes.HTTPRouteMustHaveParents(t, suite.Client, routeNN, parents, false, 60)
+			kubernetes.HTTPRouteMustHaveParentsWithContext(ctx, t, suite.APIReader, suite.TimeoutConfig, routeNN, parents, false)
 		})
 
 		// TODO(mikemorris): Add check for Listener attached routes or
@@ -33,11 +33,13 @@ func init() {
 }
 
 var HTTPRouteInvalidCrossNamespaceParentRef = suite.ConformanceTest{
-	ShortName:   "HTTPRouteInvalidCrossNamespaceParentRef",
-	Description: "A single HTTPRoute in the gateway-conformance-web-backend namespace should fail to attach to a Gateway in another namespace that it is not allowed to",
-	Manifests:   []string{"tests/httproute-invalid-cross-namespace-parent-ref.yaml"},
-	MinChannel:  suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteInvalidCrossNamespaceParentRef",
+	Description:           "A single HTTPRoute in the gateway-conformance-web-backend namespace should fail to attach to a Gateway in another namespace that it is not allowed to",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-invalid-cross-namespace-parent-ref.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		routeName := types.NamespacedName{Name: "invalid-cross-namespace-parent-ref", Namespace: "gateway-conformance-web-backend"}
 		gwName := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
 
@@ -46,12 +48,12 @@ var HTTPRouteInvalidCrossNamespaceParentRef = suite.ConformanceTest{
 		// but that is also unlikely to be universally achievable.
 		t.Run("Route should not have Parents set in status", func(t *testing.T) {
 			parents := []v1alpha2.RouteParentStatus{}
-			kubernetes.HTTPRouteMustHaveParents(t, suite.Client, routeName, parents, true, 60)
+			kubernetes.HTTPRouteMustHaveParentsWithContext(ctx, t, suite.APIReader, suite.TimeoutConfig, routeName, parents, true)
 		})
 
 		t.Run("Gateway should have 0 Routes attached", func(t *testing.T) {
 			gw := &v1alpha2.Gateway{}
-			err := suite.Client.Get(context.TODO(), gwName, gw)
+			err := suite.APIReader.Get(context.TODO(), gwName, gw)
 			require.NoError(t, err, "error fetching Gateway")
 			// There are two valid ways to represent this:
 			// 1. No listeners in status
@@ -17,6 +17,7 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,12 +36,14 @@ func init() {
 var HTTPRouteInvalidReferencePolicy = suite.ConformanceTest{
 	ShortName:   "HTTPRouteInvalidReferencePolicy",
 	Description: "A single HTTPRoute in the gateway-conformance-infra namespace should fail to attach to a Gateway in the same namespace if the route has a backendRef Service in the gateway-conformance-app-backend namespace and a ReferencePolicy exists but does not grant permission to route to that specific Service",
+	Resources:   []string{"HTTPRoute", "ReferencePolicy"},
 	Features: []suite.SupportedFeature{
-		suite.SupportReferencePolicy,
+		suite.SupportReferenceGrant,
 	},
-	Manifests:  []string{"tests/httproute-invalid-reference-policy.yaml"},
-	MinChannel: suite.StandardChannel,
-	Test: func(t *testing.T, s *suite.ConformanceTestSuite) {
+	Manifests:             []string{"tests/httproute-invalid-reference-policy.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, s *suite.ConformanceTestSuite) {
 		routeNN := types.NamespacedName{Name: "invalid-reference-policy", Namespace: "gateway-conformance-infra"}
 		gwNN := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
 
@@ -66,7 +69,7 @@ var HTTPRouteInvalidReferencePolicy = suite.ConformanceTest{
 				}},
 			}}
 
-			kubernetes.HTTPRouteMustHaveParents(t, s.Client, routeNN, parents, false, 60)
+			kubernetes.HTTPRouteMustHaveParentsWithContext(ctx, t, s.APIReader, s.TimeoutConfig, routeNN, parents, false)
 		})
 
 		// TODO(mikemorris): Un-skip check for Listener ResolvedRefs
@@ -88,10 +91,10 @@ var HTTPRouteInvalidReferencePolicy = suite.ConformanceTest{
 				}},
 			}}
 
-			kubernetes.GatewayStatusMustHaveListeners(t, s.Client, gwNN, listeners, 60)
+			kubernetes.GatewayStatusMustHaveListeners(t, s.APIReader, gwNN, listeners, 60)
 		})
 
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, s.Client, s.ControllerName, gwNN, routeNN)
+		gwAddr := s.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routeNN)
 
 		// TODO(mikemorris): Add check for HTTP requests successfully reaching
 		// app-backend-v1 at path "/" if it is determined that a Route with at
@@ -99,7 +102,7 @@ var HTTPRouteInvalidReferencePolicy = suite.ConformanceTest{
 		// and partially configured.
 
 		t.Run("Simple HTTP request should not reach app-backend-v2", func(t *testing.T) {
-			http.MakeRequestAndExpectEventuallyConsistentResponse(t, s.RoundTripper, gwAddr, http.ExpectedResponse{
+			http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, s.RoundTripper, gwAddr, http.ExpectedResponse{
 				Request: http.ExpectedRequest{
 					Method: "GET",
 					Path:   "/v2",
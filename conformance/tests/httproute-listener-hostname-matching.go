@@ -17,6 +17,7 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -31,16 +32,18 @@ func init() {
 }
 
 var HTTPRouteListenerHostnameMatching = suite.ConformanceTest{
-	ShortName:   "HTTPRouteListenerHostnameMatching",
-	Description: "Multiple HTTP listeners with the same port and different hostnames, each with a different HTTPRoute",
-	Manifests:   []string{"tests/httproute-listener-hostname-matching.yaml"},
-	MinChannel:  suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteListenerHostnameMatching",
+	Description:           "Multiple HTTP listeners with the same port and different hostnames, each with a different HTTPRoute",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-listener-hostname-matching.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		ns := "gateway-conformance-infra"
 
 		// This test creates an additional Gateway in the gateway-conformance-infra
 		// namespace so we have to wait for it to be ready.
-		kubernetes.NamespacesMustBeReady(t, suite.Client, []string{ns}, 300)
+		kubernetes.NamespacesMustBeReadyWithContext(ctx, t, suite.APIReader, suite.TimeoutConfig, []string{ns})
 
 		gwNN := types.NamespacedName{Name: "httproute-listener-hostname-matching", Namespace: ns}
 		routes := []types.NamespacedName{
@@ -48,7 +51,7 @@ var HTTPRouteListenerHostnameMatching = suite.ConformanceTest{
 			{Namespace: ns, Name: "backend-v2"},
 			{Namespace: ns, Name: "backend-v3"},
 		}
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, suite.Client, suite.ControllerName, gwNN, routes...)
+		gwAddr := suite.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routes...)
 
 		testCases := []http.ExpectedResponse{{
 			Request:   http.ExpectedRequest{Host: "bar.com", Path: "/"},
@@ -75,12 +78,9 @@ var HTTPRouteListenerHostnameMatching = suite.ConformanceTest{
 		}}
 
 		for i := range testCases {
-			// Declare tc here to avoid loop variable
-			// reuse issues across parallel tests.
 			tc := testCases[i]
 			t.Run(testName(tc, i), func(t *testing.T) {
-				t.Parallel()
-				http.MakeRequestAndExpectEventuallyConsistentResponse(t, suite.RoundTripper, gwAddr, tc)
+				http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, suite.RoundTripper, gwAddr, tc)
 			})
 		}
 	},
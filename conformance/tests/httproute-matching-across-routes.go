@@ -17,13 +17,13 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/gateway-api/conformance/utils/http"
-	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/suite"
 )
 
@@ -32,16 +32,18 @@ func init() {
 }
 
 var HTTPRouteMatchingAcrossRoutes = suite.ConformanceTest{
-	ShortName:   "HTTPRouteMatchingAcrossRoutes",
-	Description: "Two HTTPRoutes with path matching for different backends",
-	Manifests:   []string{"tests/httproute-matching-across-routes.yaml"},
-	MinChannel:  suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteMatchingAcrossRoutes",
+	Description:           "Two HTTPRoutes with path matching for different backends",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-matching-across-routes.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		ns := "gateway-conformance-infra"
 		routeNN1 := types.NamespacedName{Name: "matching-part1", Namespace: ns}
 		routeNN2 := types.NamespacedName{Name: "matching-part2", Namespace: ns}
 		gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, suite.Client, suite.ControllerName, gwNN, routeNN1, routeNN2)
+		gwAddr := suite.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routeNN1, routeNN2)
 
 		testCases := []http.ExpectedResponse{{
 			Request: http.ExpectedRequest{
@@ -105,12 +107,9 @@ var HTTPRouteMatchingAcrossRoutes = suite.ConformanceTest{
 		}}
 
 		for i := range testCases {
-			// Declare tc here to avoid loop variable
-			// reuse issues across parallel tests.
 			tc := testCases[i]
 			t.Run(testName(tc, i), func(t *testing.T) {
-				t.Parallel()
-				http.MakeRequestAndExpectEventuallyConsistentResponse(t, suite.RoundTripper, gwAddr, tc)
+				http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, suite.RoundTripper, gwAddr, tc)
 			})
 		}
 	},
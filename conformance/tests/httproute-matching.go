@@ -17,12 +17,12 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/gateway-api/conformance/utils/http"
-	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/suite"
 )
 
@@ -31,15 +31,17 @@ func init() {
 }
 
 var HTTPRouteMatching = suite.ConformanceTest{
-	ShortName:   "HTTPRouteMatching",
-	Description: "A single HTTPRoute with path and header matching for different backends",
-	Manifests:   []string{"tests/httproute-matching.yaml"},
-	MinChannel:  suite.StandardChannel,
-	Test: func(t *testing.T, suite *suite.ConformanceTestSuite) {
+	ShortName:             "HTTPRouteMatching",
+	Description:           "A single HTTPRoute with path and header matching for different backends",
+	Resources:             []string{"HTTPRoute"},
+	Manifests:             []string{"tests/httproute-matching.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, suite *suite.ConformanceTestSuite) {
 		ns := "gateway-conformance-infra"
 		routeNN := types.NamespacedName{Name: "matching", Namespace: ns}
 		gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, suite.Client, suite.ControllerName, gwNN, routeNN)
+		gwAddr := suite.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routeNN)
 
 		testCases := []http.ExpectedResponse{{
 			Request:   http.ExpectedRequest{Path: "/"},
@@ -68,12 +70,9 @@ var HTTPRouteMatching = suite.ConformanceTest{
 		}}
 
 		for i := range testCases {
-			// Declare tc here to avoid loop variable
-			// reuse issues across parallel tests.
 			tc := testCases[i]
 			t.Run(testName(tc, i), func(t *testing.T) {
-				t.Parallel()
-				http.MakeRequestAndExpectEventuallyConsistentResponse(t, suite.RoundTripper, gwAddr, tc)
+				http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, suite.RoundTripper, gwAddr, tc)
 			})
 		}
 	},
@@ -17,12 +17,12 @@ limitations under the License.
 package tests
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/gateway-api/conformance/utils/http"
-	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/suite"
 )
 
@@ -33,18 +33,20 @@ func init() {
 var HTTPRouteReferencePolicy = suite.ConformanceTest{
 	ShortName:   "HTTPRouteReferencePolicy",
 	Description: "A single HTTPRoute in the gateway-conformance-infra namespace, with a backendRef in the gateway-conformance-web-backend namespace, should attach to Gateway in the gateway-conformance-infra namespace",
+	Resources:   []string{"HTTPRoute", "ReferencePolicy"},
 	Features: []suite.SupportedFeature{
-		suite.SupportReferencePolicy,
+		suite.SupportReferenceGrant,
 	},
-	Manifests:  []string{"tests/httproute-reference-policy.yaml"},
-	MinChannel: suite.StandardChannel,
-	Test: func(t *testing.T, s *suite.ConformanceTestSuite) {
+	Manifests:             []string{"tests/httproute-reference-policy.yaml"},
+	MinChannel:            suite.StandardChannel,
+	RequiresBaseManifests: true,
+	Test: func(ctx context.Context, t *testing.T, s *suite.ConformanceTestSuite) {
 		routeNN := types.NamespacedName{Name: "reference-policy", Namespace: "gateway-conformance-infra"}
 		gwNN := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
-		gwAddr := kubernetes.GatewayAndHTTPRoutesMustBeReady(t, s.Client, s.ControllerName, gwNN, routeNN)
+		gwAddr := s.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, gwNN, routeNN)
 
 		t.Run("Simple HTTP request should reach web-backend", func(t *testing.T) {
-			http.MakeRequestAndExpectEventuallyConsistentResponse(t, s.RoundTripper, gwAddr, http.ExpectedResponse{
+			http.MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, s.RoundTripper, gwAddr, http.ExpectedResponse{
 				Request: http.ExpectedRequest{
 					Method: "GET",
 					Path:   "/",
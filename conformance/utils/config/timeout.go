@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "time"
+
+// TimeoutConfig holds how long the conformance suite waits for resources to
+// become ready and for tests to complete. All fields are durations, e.g. 3 * time.Minute; zero fields
+// are set to their default by SetupTimeoutConfig.
+type TimeoutConfig struct {
+	// GatewayClassMustBeAccepted is how long to wait for the GatewayClass
+	// under test to be Accepted. Defaults to 180s.
+	GatewayClassMustBeAccepted time.Duration
+	// NamespacesMustBeReady is how long to wait for the Gateways and Pods of
+	// namespaces to be ready. Defaults to 300s.
+	NamespacesMustBeReady time.Duration
+	// NamespacesMustBeDeleted is how long to wait for the namespaces created
+	// by the suite to be gone once deleted. Defaults to 300s.
+	NamespacesMustBeDeleted time.Duration
+	// ResourcesMustBeDeleted is how long cleanup waits for each resource to
+	// be gone once deleted, when waiting for deletions, and how long a
+	// deleted route may take to be detached from its Gateway. Defaults to
+	// 60s.
+	ResourcesMustBeDeleted time.Duration
+	// GatewayMustHaveAddress is how long to wait for a Gateway to publish an
+	// address requests can be sent to. Defaults to 180s.
+	GatewayMustHaveAddress time.Duration
+	// RouteMustHaveParents is how long to wait for a route to have the
+	// expected parents in its status. Defaults to 60s.
+	RouteMustHaveParents time.Duration
+	// ManifestsMustBeApplied is how long applying a resource of a manifest
+	// is retried for when it fails with a transient error, e.g. while CRDs
+	// or admission webhooks aren't ready yet. Defaults to 60s.
+	ManifestsMustBeApplied time.Duration
+	// DeploymentsMustBeReady is how long to wait for Deployments to have all
+	// of their replicas available. Defaults to 300s.
+	DeploymentsMustBeReady time.Duration
+	// PollInterval is how long to wait between two checks of the readiness
+	// of resources. Defaults to 1s.
+	PollInterval time.Duration
+	// TestTimeout is how long a conformance test may run for if it doesn't
+	// set its own Timeout. Defaults to 600s.
+	TestTimeout time.Duration
+	// RequestTimeout is how long a request made over a raw connection, such
+	// as a WebSocket handshake and its messages, may take, from dialing the
+	// Gateway to reading the last reply. Defaults to 10s.
+	RequestTimeout time.Duration
+}
+
+// DefaultTimeoutConfig returns the default TimeoutConfig.
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		GatewayClassMustBeAccepted: 180 * time.Second,
+		NamespacesMustBeReady:      300 * time.Second,
+		NamespacesMustBeDeleted:    300 * time.Second,
+		ResourcesMustBeDeleted:     60 * time.Second,
+		GatewayMustHaveAddress:     180 * time.Second,
+		RouteMustHaveParents:       60 * time.Second,
+		ManifestsMustBeApplied:     60 * time.Second,
+		DeploymentsMustBeReady:     300 * time.Second,
+		PollInterval:               1 * time.Second,
+		TestTimeout:                600 * time.Second,
+		RequestTimeout:             10 * time.Second,
+	}
+}
+
+// SetupTimeoutConfig sets every zero field of timeoutConfig to its default.
+func SetupTimeoutConfig(timeoutConfig *TimeoutConfig) {
+	defaults := DefaultTimeoutConfig()
+	if timeoutConfig.GatewayClassMustBeAccepted == 0 {
+		timeoutConfig.GatewayClassMustBeAccepted = defaults.GatewayClassMustBeAccepted
+	}
+	if timeoutConfig.NamespacesMustBeReady == 0 {
+		timeoutConfig.NamespacesMustBeReady = defaults.NamespacesMustBeReady
+	}
+	if timeoutConfig.NamespacesMustBeDeleted == 0 {
+		timeoutConfig.NamespacesMustBeDeleted = defaults.NamespacesMustBeDeleted
+	}
+	if timeoutConfig.ResourcesMustBeDeleted == 0 {
+		timeoutConfig.ResourcesMustBeDeleted = defaults.ResourcesMustBeDeleted
+	}
+	if timeoutConfig.GatewayMustHaveAddress == 0 {
+		timeoutConfig.GatewayMustHaveAddress = defaults.GatewayMustHaveAddress
+	}
+	if timeoutConfig.RouteMustHaveParents == 0 {
+		timeoutConfig.RouteMustHaveParents = defaults.RouteMustHaveParents
+	}
+	if timeoutConfig.ManifestsMustBeApplied == 0 {
+		timeoutConfig.ManifestsMustBeApplied = defaults.ManifestsMustBeApplied
+	}
+	if timeoutConfig.DeploymentsMustBeReady == 0 {
+		timeoutConfig.DeploymentsMustBeReady = defaults.DeploymentsMustBeReady
+	}
+	if timeoutConfig.PollInterval == 0 {
+		timeoutConfig.PollInterval = defaults.PollInterval
+	}
+	if timeoutConfig.TestTimeout == 0 {
+		timeoutConfig.TestTimeout = defaults.TestTimeout
+	}
+	if timeoutConfig.RequestTimeout == 0 {
+		timeoutConfig.RequestTimeout = defaults.RequestTimeout
+	}
+}
@@ -21,11 +21,32 @@ package flags
 
 import (
 	"flag"
+	"time"
 )
 
 var (
 	GatewayClassName     = flag.String("gateway-class", "gateway-conformance", "Name of GatewayClass to use for tests")
+	GatewayClassNames    = flag.String("gateway-classes", "", "Comma-separated list of GatewayClasses to run the tests against in turn, overriding gateway-class")
 	ShowDebug            = flag.Bool("debug", false, "Whether to print debug logs")
 	CleanupBaseResources = flag.Bool("cleanup-base-resources", true, "Whether to cleanup base test resources after the run")
+	CleanupOnFailure     = flag.Bool("cleanup-on-failure", true, "Whether to cleanup the resources of a test when it fails")
+	WaitForCleanup       = flag.Bool("wait-for-cleanup", false, "Whether to wait for each resource deleted on cleanup to be gone before deleting the next one")
+	DiffApplied          = flag.Bool("diff-applied", false, "Whether to log how the applied resources stored by the apiserver differ from the manifests, implied by debug")
 	Experimental         = flag.Bool("experimental", false, "Designed to run in experimental mode")
+	RunTest              = flag.String("run-test", "", "Name of a single test to run, or a glob pattern like HTTPRoute*")
+	SkipTests            = flag.String("skip-tests", "", "Comma-separated list of names of tests to skip, which may be glob patterns")
+	ConformanceProfiles  = flag.String("conformance-profiles", "", "Comma-separated list of names of conformance profiles to claim, e.g. HTTP")
+	FailFast             = flag.Bool("fail-fast", false, "Whether to skip the remaining tests once a test failed")
+	MaxParallel          = flag.Int("max-parallel", 0, "Maximum number of parallel tests running at once, 0 for no limit beyond the -parallel flag of go test")
+	Shuffle              = flag.Bool("shuffle", false, "Whether to run the tests in a random order")
+	Seed                 = flag.Int64("seed", 0, "Seed of the order of shuffled tests, picked at random if 0")
+	AuditLeaks           = flag.Bool("audit-leaks", false, "Whether to fail the run for Gateway API resources left behind in the base namespaces once all tests are done")
+	ReportOutput         = flag.String("report-output", "", "Path of the file to write the JSON report of the run to, suffixed with the name of each GatewayClass when running against several")
+	RerunFailedFrom      = flag.String("rerun-failed-from", "", "Path of the JSON report of a previous run, as written with report-output, whose failed tests are the only ones to run")
+	CollectMetrics       = flag.Bool("collect-metrics", false, "Whether to report the latencies and status codes of the requests of each test")
+	DryRun               = flag.Bool("dry-run", false, "Whether to only log which tests would run, without touching the cluster")
+	AutoDetectFeatures   = flag.Bool("auto-detect-features", false, "Whether to add the supported features published by the GatewayClass")
+	SlowestTests         = flag.Int("slowest-tests", 10, "Number of the slowest tests to log once all tests are done, 0 to disable")
+	AddressType          = flag.String("address-type", "", "Address family of Gateways advertising both, IPv4 or IPv6 to require it, PreferIPv6 to prefer IPv6, or empty for the first usable address")
+	SlowTestThreshold    = flag.Duration("slow-test-threshold", 2*time.Minute, "Duration above which tests not marked Slow are flagged in the slowest tests log, 0 to disable")
 )
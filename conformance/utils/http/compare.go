@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// HeaderMatchType specifies how the value of a HeaderMatch is compared.
+type HeaderMatchType string
+
+const (
+	// HeaderMatchExact requires one of the values of the header to be equal
+	// to the expected value. This is the default.
+	HeaderMatchExact HeaderMatchType = "Exact"
+	// HeaderMatchContains requires one of the values of the header to contain
+	// the expected value.
+	HeaderMatchContains HeaderMatchType = "Contains"
+	// HeaderMatchAbsent requires the header not to be set at all.
+	HeaderMatchAbsent HeaderMatchType = "Absent"
+)
+
+// HeaderMatch describes a header expected on a response or on the request
+// seen by the backend. Header names are matched case-insensitively.
+type HeaderMatch struct {
+	Name  string
+	Value string
+	Type  HeaderMatchType
+}
+
+// CompareResponse returns an error listing every way in which the captured
+// request and response differ from the provided ExpectedResponse, or nil if
+// they match. As with ExpectResponse, the request seen by the backend is only
+// verified if the response has a 200 status code.
+func CompareResponse(cReq *roundtripper.CapturedRequest, cRes *roundtripper.CapturedResponse, expected ExpectedResponse) error {
+	if cRes == nil {
+		return fmt.Errorf("no response captured")
+	}
+
+	var diffs []string
+	if expected.StatusCode != cRes.StatusCode {
+		diffs = append(diffs, fmt.Sprintf("status code: expected %d, got %d", expected.StatusCode, cRes.StatusCode))
+	}
+	diffs = append(diffs, compareHeaders("response header", cRes.Headers, expected.Headers)...)
+
+	if cRes.StatusCode == 200 {
+		diffs = append(diffs, compareBackendRequest(cReq, expected)...)
+	}
+
+	if len(diffs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("response doesn't match the expected response:\n  - %s", strings.Join(diffs, "\n  - "))
+}
+
+// compareBackendRequest returns the differences between the request echoed by
+// the backend and the expected one.
+func compareBackendRequest(cReq *roundtripper.CapturedRequest, expected ExpectedResponse) []string {
+	if cReq == nil {
+		return []string{"no request captured by the backend"}
+	}
+
+	var diffs []string
+	if expected.Request.Path != cReq.Path {
+		diffs = append(diffs, fmt.Sprintf("path: expected %q, got %q", expected.Request.Path, cReq.Path))
+	}
+	if expected.Request.Method != cReq.Method {
+		diffs = append(diffs, fmt.Sprintf("method: expected %q, got %q", expected.Request.Method, cReq.Method))
+	}
+	if expected.Namespace != cReq.Namespace {
+		diffs = append(diffs, fmt.Sprintf("namespace: expected %q, got %q", expected.Namespace, cReq.Namespace))
+	}
+	if !strings.HasPrefix(cReq.Pod, expected.Backend) {
+		diffs = append(diffs, fmt.Sprintf("backend: expected pod name to start with %q, got %q", expected.Backend, cReq.Pod))
+	}
+
+	if expected.Request.Headers != nil && cReq.Headers == nil {
+		diffs = append(diffs, "request header: no headers captured by the backend")
+	} else {
+		for name, value := range expected.Request.Headers {
+			actual, ok := headerValues(cReq.Headers, name)
+			switch {
+			case !ok:
+				diffs = append(diffs, fmt.Sprintf("request header %s: expected to be set, actual headers: %v", name, cReq.Headers))
+			case len(actual) == 0 || actual[0] != value:
+				diffs = append(diffs, fmt.Sprintf("request header %s: expected %q, got %q", name, value, actual))
+			}
+		}
+	}
+	return append(diffs, compareHeaders("request header", cReq.Headers, expected.BackendHeaders)...)
+}
+
+// compareHeaders returns the differences between the headers and the
+// expected matches. The kind of header is used to prefix each difference.
+func compareHeaders(kind string, headers map[string][]string, matches []HeaderMatch) []string {
+	var diffs []string
+	for _, match := range matches {
+		actual, ok := headerValues(headers, match.Name)
+		switch match.Type {
+		case HeaderMatchAbsent:
+			if ok {
+				diffs = append(diffs, fmt.Sprintf("%s %s: expected to be absent, got %q", kind, match.Name, actual))
+			}
+		case HeaderMatchContains:
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s %s: expected to contain %q, but it isn't set", kind, match.Name, match.Value))
+			} else if !anyValue(actual, func(v string) bool { return strings.Contains(v, match.Value) }) {
+				diffs = append(diffs, fmt.Sprintf("%s %s: expected to contain %q, got %q", kind, match.Name, match.Value, actual))
+			}
+		case HeaderMatchExact, "":
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s %s: expected %q, but it isn't set", kind, match.Name, match.Value))
+			} else if !anyValue(actual, func(v string) bool { return v == match.Value }) {
+				diffs = append(diffs, fmt.Sprintf("%s %s: expected %q, got %q", kind, match.Name, match.Value, actual))
+			}
+		default:
+			diffs = append(diffs, fmt.Sprintf("%s %s: unknown match type %q", kind, match.Name, match.Type))
+		}
+	}
+	return diffs
+}
+
+// headerValues returns the values of the named header, ignoring case.
+func headerValues(headers map[string][]string, name string) ([]string, bool) {
+	for key, values := range headers {
+		if strings.EqualFold(key, name) {
+			return values, true
+		}
+	}
+	return nil, false
+}
+
+// anyValue returns true if the predicate holds for one of the values.
+func anyValue(values []string, predicate func(string) bool) bool {
+	for _, v := range values {
+		if predicate(v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestCompareResponse(t *testing.T) {
+	cReq := &roundtripper.CapturedRequest{
+		Path:      "/",
+		Method:    "GET",
+		Namespace: "gateway-conformance-infra",
+		Pod:       "infra-backend-v1-abc",
+		Headers:   map[string][]string{"X-Echo": {"hello"}},
+	}
+	cRes := &roundtripper.CapturedResponse{
+		StatusCode: 200,
+		Headers:    map[string][]string{"Content-Type": {"text/plain; charset=utf-8"}},
+	}
+	base := ExpectedResponse{
+		Request:    ExpectedRequest{Path: "/", Method: "GET"},
+		StatusCode: 200,
+		Backend:    "infra-backend-v1",
+		Namespace:  "gateway-conformance-infra",
+	}
+
+	matching := base
+	matching.Request.Headers = map[string]string{"x-echo": "hello"}
+	matching.Headers = []HeaderMatch{{Name: "content-type", Value: "text/plain", Type: HeaderMatchContains}}
+	matching.BackendHeaders = []HeaderMatch{
+		{Name: "X-Echo", Value: "hello"},
+		{Name: "X-Removed", Type: HeaderMatchAbsent},
+	}
+	require.NoError(t, CompareResponse(cReq, cRes, matching))
+
+	mismatching := base
+	mismatching.Backend = "infra-backend-v2"
+	mismatching.Headers = []HeaderMatch{{Name: "Content-Type", Value: "application/json"}}
+	mismatching.BackendHeaders = []HeaderMatch{{Name: "x-echo", Type: HeaderMatchAbsent}}
+	require.EqualError(t, CompareResponse(cReq, cRes, mismatching), `response doesn't match the expected response:
+  - response header Content-Type: expected "application/json", got ["text/plain; charset=utf-8"]
+  - backend: expected pod name to start with "infra-backend-v2", got "infra-backend-v1-abc"
+  - request header x-echo: expected to be absent, got ["hello"]`)
+
+	notFound := base
+	notFound.Backend = "infra-backend-v2"
+	require.EqualError(t, CompareResponse(nil, &roundtripper.CapturedResponse{StatusCode: 404}, notFound),
+		"response doesn't match the expected response:\n  - status code: expected 200, got 404",
+		"expected the backend not to be verified without a 200 response")
+}
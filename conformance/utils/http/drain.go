@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectRouteDeletionDrain starts the request, which must be routed to a
+// backend trickling its body as configured by trickle, and calls deleteRoute
+// to delete the route while the body is streamed. If drains is true, the
+// Gateway guarantees in-flight requests are drained, so the request must
+// complete with a 200 and its whole body. Otherwise the Gateway may cut the
+// request short, but must still end it rather than leaving it hanging. Either
+// way, new requests must then get a 404. The route must already be
+// programmed.
+func ExpectRouteDeletionDrain(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, trickle Trickle, deleteRoute func() error, drains bool) {
+	t.Helper()
+
+	type result struct {
+		cRes *roundtripper.CapturedResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		_, cRes, err := r.CaptureRoundTrip(trickle.request(gwAddr, req))
+		done <- result{cRes: cRes, err: err}
+	}()
+
+	// Wait for the first chunk to be due so that the request is in flight.
+	t.Logf("Deleting route while a body trickled over %s is streamed from %s", trickle.Duration(), gwAddr)
+	time.Sleep(trickle.Interval)
+	require.NoError(t, deleteRoute(), "error deleting route")
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(trickle.Duration() + maxTimeToConsistency):
+		t.Fatalf("Expected in-flight request to end after the route was deleted, still running after %s", trickle.Duration()+maxTimeToConsistency)
+	}
+
+	switch {
+	case drains:
+		require.NoErrorf(t, res.err, "expected in-flight request to be drained after the route was deleted")
+		require.Equalf(t, http.StatusOK, res.cRes.StatusCode, "expected in-flight request to be drained after the route was deleted, got %d", res.cRes.StatusCode)
+	case res.err != nil:
+		t.Logf("In-flight request was cut short after the route was deleted: %v", res.err)
+	default:
+		t.Logf("In-flight request completed with status %d after the route was deleted", res.cRes.StatusCode)
+	}
+
+	ExpectNotServed(t, r, gwAddr, req)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeDrainingGateway starts a fake Gateway forwarding to a backend trickling
+// its body as requested, until the returned function deletes the route. Once
+// it is deleted, new requests get a 404 while in-flight requests are drained
+// if drains is true, and aborted otherwise.
+func fakeDrainingGateway(t *testing.T, drains bool) (string, func() error) {
+	var deleted int32
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&deleted) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		chunks, _ := strconv.Atoi(r.URL.Query().Get("chunks"))
+		interval, _ := time.ParseDuration(r.URL.Query().Get("interval"))
+
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for i := 0; i < chunks; i++ {
+			time.Sleep(interval)
+			if !drains && atomic.LoadInt32(&deleted) == 1 {
+				panic(http.ErrAbortHandler)
+			}
+			_, _ = w.Write([]byte("chunk\n"))
+			w.(http.Flusher).Flush()
+		}
+	})
+	return gwAddr, func() error {
+		atomic.StoreInt32(&deleted, 1)
+		return nil
+	}
+}
+
+func TestExpectRouteDeletionDrain(t *testing.T) {
+	req := ExpectedRequest{Path: "/stream"}
+	trickle := Trickle{Chunks: 4, Interval: 100 * time.Millisecond}
+
+	t.Run("drained", func(t *testing.T) {
+		gwAddr, deleteRoute := fakeDrainingGateway(t, true)
+		ExpectRouteDeletionDrain(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, trickle, deleteRoute, true)
+	})
+
+	t.Run("cut short", func(t *testing.T) {
+		gwAddr, deleteRoute := fakeDrainingGateway(t, false)
+		ExpectRouteDeletionDrain(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, trickle, deleteRoute, false)
+	})
+}
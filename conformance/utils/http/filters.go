@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectFiltersAppliedInOrder makes the expected request to a rule configured
+// with the provided filters and verifies that the headers observed by the
+// backend reflect the filters being applied in the declared order. Only
+// RequestHeaderModifier filters are taken into account; within one filter,
+// set, add and remove are applied in that order.
+func ExpectFiltersAppliedInOrder(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, filters []v1alpha2.HTTPRouteFilter) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
+	// The sent headers are expected to be modified, so they are verified
+	// against the filters below rather than by ExpectResponse.
+	unfiltered := expected
+	unfiltered.Request.Headers = nil
+	ExpectResponse(t, cReq, cRes, unfiltered)
+
+	sent := http.Header{}
+	for name, value := range expected.Request.Headers {
+		sent.Set(name, value)
+	}
+	want := applyHeaderFilters(sent, filters)
+
+	got := http.Header{}
+	for name, values := range cReq.Headers {
+		got[http.CanonicalHeaderKey(name)] = values
+	}
+
+	for _, name := range filteredHeaderNames(filters) {
+		assert.Equalf(t, want.Values(name), got.Values(name), "expected %s header to reflect filters applied in declared order", name)
+	}
+}
+
+// applyHeaderFilters returns a copy of the headers with the RequestHeaderModifier
+// filters applied in order.
+func applyHeaderFilters(headers http.Header, filters []v1alpha2.HTTPRouteFilter) http.Header {
+	result := headers.Clone()
+	for _, filter := range filters {
+		if filter.Type != v1alpha2.HTTPRouteFilterRequestHeaderModifier || filter.RequestHeaderModifier == nil {
+			continue
+		}
+		for _, header := range filter.RequestHeaderModifier.Set {
+			result.Set(string(header.Name), header.Value)
+		}
+		for _, header := range filter.RequestHeaderModifier.Add {
+			result.Add(string(header.Name), header.Value)
+		}
+		for _, name := range filter.RequestHeaderModifier.Remove {
+			result.Del(name)
+		}
+	}
+	return result
+}
+
+// filteredHeaderNames returns the canonical names of every header modified by
+// the RequestHeaderModifier filters.
+func filteredHeaderNames(filters []v1alpha2.HTTPRouteFilter) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		name = http.CanonicalHeaderKey(name)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, filter := range filters {
+		if filter.Type != v1alpha2.HTTPRouteFilterRequestHeaderModifier || filter.RequestHeaderModifier == nil {
+			continue
+		}
+		for _, header := range filter.RequestHeaderModifier.Set {
+			add(string(header.Name))
+		}
+		for _, header := range filter.RequestHeaderModifier.Add {
+			add(string(header.Name))
+		}
+		for _, name := range filter.RequestHeaderModifier.Remove {
+			add(name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectFiltersAppliedInOrder(t *testing.T) {
+	setFilter := v1alpha2.HTTPRouteFilter{
+		Type: v1alpha2.HTTPRouteFilterRequestHeaderModifier,
+		RequestHeaderModifier: &v1alpha2.HTTPRequestHeaderFilter{
+			Set: []v1alpha2.HTTPHeader{{Name: "X-Order", Value: "set"}},
+		},
+	}
+	removeFilter := v1alpha2.HTTPRouteFilter{
+		Type: v1alpha2.HTTPRouteFilterRequestHeaderModifier,
+		RequestHeaderModifier: &v1alpha2.HTTPRequestHeaderFilter{
+			Remove: []string{"X-Order"},
+		},
+	}
+
+	// The fake Gateway has a rule setting then removing X-Order on /set-remove
+	// and one removing then setting it on /remove-set.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/set-remove":
+			r.Header.Set("X-Order", "set")
+			r.Header.Del("X-Order")
+		case "/remove-set":
+			r.Header.Del("X-Order")
+			r.Header.Set("X-Order", "set")
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	for _, tc := range []struct {
+		path    string
+		filters []v1alpha2.HTTPRouteFilter
+	}{
+		{path: "/set-remove", filters: []v1alpha2.HTTPRouteFilter{setFilter, removeFilter}},
+		{path: "/remove-set", filters: []v1alpha2.HTTPRouteFilter{removeFilter, setFilter}},
+	} {
+		tc := tc
+		t.Run(tc.path, func(t *testing.T) {
+			ExpectFiltersAppliedInOrder(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+				Request:   ExpectedRequest{Path: tc.path, Headers: map[string]string{"X-Order": "original"}},
+				Backend:   "infra-backend-v1",
+				Namespace: fakeNamespace,
+			}, tc.filters)
+		})
+	}
+
+	headers := http.Header{"X-Order": []string{"original"}}
+	require.Empty(t, applyHeaderFilters(headers, []v1alpha2.HTTPRouteFilter{setFilter, removeFilter}).Values("X-Order"))
+	require.Equal(t, []string{"set"}, applyHeaderFilters(headers, []v1alpha2.HTTPRouteFilter{removeFilter, setFilter}).Values("X-Order"))
+}
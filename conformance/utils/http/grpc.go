@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// grpcUnaryRoundTripper returns the GRPCUnaryRoundTripper implementation of r,
+// failing the test if r doesn't support gRPC calls.
+func grpcUnaryRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.GRPCUnaryRoundTripper {
+	t.Helper()
+
+	grpcRT, ok := r.(roundtripper.GRPCUnaryRoundTripper)
+	require.Truef(t, ok, "%T does not support gRPC calls", r)
+	return grpcRT
+}
+
+// ExpectGRPCTrailers makes a unary gRPC call through the Gateway, understanding
+// that the call may fail for some amount of time, and verifies that the
+// Gateway preserved the "TE: trailers" header the call relies on: the backend
+// must have received it and the grpc-status trailer must have flowed back.
+func ExpectGRPCTrailers(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req roundtripper.GRPCRequest, backend string) {
+	t.Helper()
+
+	req.Address = gwAddr
+	grpcRT := grpcUnaryRoundTripper(t, r)
+
+	var (
+		cReq *roundtripper.CapturedRequest
+		cRes *roundtripper.CapturedGRPCResponse
+	)
+	require.Eventually(t, func() bool {
+		var err error
+		cReq, cRes, err = grpcRT.CaptureGRPCUnaryCall(req)
+		if err != nil {
+			t.Logf("gRPC call failed, not ready yet: %v", err)
+			return false
+		}
+		if cRes.GRPCStatus != 0 {
+			t.Logf("Expected gRPC call to succeed but got status %d (%s), not ready yet", cRes.GRPCStatus, cRes.GRPCMessage)
+			return false
+		}
+		return true
+	}, maxTimeToConsistency, 1*time.Second, "error making gRPC call, never got an OK status")
+
+	require.NotEmpty(t, http.Header(cRes.Trailers).Get("Grpc-Status"), "expected grpc-status to be sent as a trailer")
+	require.Truef(t, strings.HasPrefix(cReq.Pod, backend), "expected pod name to start with %s, got %s", backend, cReq.Pod)
+	require.Equal(t, []string{"trailers"}, http.Header(cReq.Headers).Values("Te"), "expected backend to receive the TE: trailers header")
+}
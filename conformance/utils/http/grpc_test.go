@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeGRPCGateway starts a cleartext HTTP/2 server standing in for a Gateway
+// routing gRPC calls to a backend that responds with the request metadata it
+// received, serialized as JSON, and an OK grpc-status trailer.
+func fakeGRPCGateway(t *testing.T, backend string) string {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		message, _ := json.Marshal(roundtripper.CapturedRequest{
+			Path:      r.URL.Path,
+			Host:      r.Host,
+			Method:    r.Method,
+			Protocol:  r.Proto,
+			Headers:   r.Header,
+			Namespace: fakeNamespace,
+			Pod:       backend + "-7f9c8d6b5-x2x9z",
+		})
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		frame := make([]byte, 5, 5+len(message))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+		_, _ = w.Write(append(frame, message...))
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "")
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+func TestExpectGRPCTrailers(t *testing.T) {
+	gwAddr := fakeGRPCGateway(t, "grpc-infra-backend-v1")
+
+	ExpectGRPCTrailers(t, &roundtripper.DefaultRoundTripper{}, gwAddr, roundtripper.GRPCRequest{
+		Host:    "grpc.example.com",
+		Service: "gateway_api_conformance.echo_basic.grpcecho.GrpcEcho",
+		Method:  "Echo",
+	}, "grpc-infra-backend-v1")
+}
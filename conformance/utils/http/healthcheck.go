@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// HealthToggle marks a backend as healthy or unhealthy.
+type HealthToggle func(healthy bool) error
+
+// EchoHealthToggle returns a HealthToggle flipping the health of an echo
+// backend reachable at addr by requesting its toggle endpoint at path with a
+// healthy=true|false query parameter.
+func EchoHealthToggle(r roundtripper.RoundTripper, addr, path string) HealthToggle {
+	return func(healthy bool) error {
+		_, cRes, err := r.CaptureRoundTrip(roundtripper.Request{
+			Method: "POST",
+			URL: url.URL{
+				Scheme:   "http",
+				Host:     addr,
+				Path:     path,
+				RawQuery: url.Values{"healthy": []string{strconv.FormatBool(healthy)}}.Encode(),
+			},
+			Protocol: "HTTP",
+		})
+		if err != nil {
+			return err
+		}
+		if cRes.StatusCode != 200 {
+			return fmt.Errorf("expected toggle endpoint to respond with 200, got %d", cRes.StatusCode)
+		}
+		return nil
+	}
+}
+
+// ExpectHealthCheckDrivenRouting verifies that a Gateway performing active
+// health checks stops routing to a backend once it is unhealthy and resumes
+// routing to it once it recovers. The backend is made unhealthy through
+// toggle, after which the Gateway has the unhealthy window to stop sending
+// requests to it. Once it is healthy again, the Gateway has the same window
+// to route to it again.
+func ExpectHealthCheckDrivenRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, backend string, toggle HealthToggle, window time.Duration) {
+	t.Helper()
+
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	rtReq := roundtripper.Request{
+		Method:   req.Method,
+		Host:     req.Host,
+		URL:      url.URL{Scheme: "http", Host: gwAddr, Path: req.Path},
+		Protocol: "HTTP",
+	}
+
+	require.NoErrorf(t, toggle(false), "error marking %s backend unhealthy", backend)
+	t.Cleanup(func() {
+		if err := toggle(true); err != nil {
+			t.Logf("Error restoring health of %s backend: %v", backend, err)
+		}
+	})
+
+	numAvoided := 0
+	require.Eventually(t, func() bool {
+		cReq, cRes, err := r.CaptureRoundTrip(rtReq)
+		if err != nil || cRes.StatusCode != 200 || strings.HasPrefix(cReq.Pod, backend) {
+			numAvoided = 0
+			t.Logf("Request was not served by a healthy backend yet")
+			return false
+		}
+		numAvoided++
+		return numAvoided >= requiredConsecutiveSuccesses
+	}, window, 1*time.Second, "Gateway kept routing to unhealthy %s backend", backend)
+
+	require.NoErrorf(t, toggle(true), "error marking %s backend healthy", backend)
+
+	require.Eventually(t, func() bool {
+		cReq, cRes, err := r.CaptureRoundTrip(rtReq)
+		return err == nil && cRes.StatusCode == 200 && strings.HasPrefix(cReq.Pod, backend)
+	}, window, 1*time.Second, "Gateway never routed to recovered %s backend", backend)
+}
+
+// ExpectUnavailableUntilReady verifies that while the backend of the expected
+// response is still starting, the Gateway answers with a 503 rather than
+// failing the connection, and that the expected response is served once the
+// backend is ready. A 503 must be observed at least once, and the backend has
+// the startup window to become ready.
+func ExpectUnavailableUntilReady(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, startup time.Duration) {
+	t.Helper()
+
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+
+	// The condition runs in its own goroutine, so failures are reported once
+	// polling is done.
+	var unexpected error
+	numUnavailable := 0
+	require.Eventually(t, func() bool {
+		_, cRes, err := r.CaptureRoundTrip(req)
+		switch {
+		case err != nil:
+			unexpected = fmt.Errorf("expected the Gateway to accept connections while the backend is starting: %w", err)
+		case cRes.StatusCode == 503:
+			numUnavailable++
+			t.Logf("Backend still starting, got 503")
+			return false
+		case cRes.StatusCode != expected.StatusCode:
+			unexpected = fmt.Errorf("expected %d or %d while the backend is starting, got %d", 503, expected.StatusCode, cRes.StatusCode)
+		}
+		return true
+	}, startup, 1*time.Second, "backend never became ready")
+	require.NoError(t, unexpected)
+	require.NotZero(t, numUnavailable, "expected the Gateway to respond with 503 while the backend is starting")
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectHealthCheckDrivenRouting(t *testing.T) {
+	var mu sync.Mutex
+	healthy := true
+	next := 0
+
+	// The fake Gateway round-robins across both backends, skipping the
+	// toggleable one while it is unhealthy.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		backends := []string{"infra-backend-v1", "infra-backend-v2"}
+		backend := backends[next%2]
+		next++
+		if backend == "infra-backend-v1" && !healthy {
+			backend = "infra-backend-v2"
+		}
+		echo(w, r, backend)
+	})
+
+	// The toggle endpoint of the fake echo backend.
+	toggleAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		healthy = r.URL.Query().Get("healthy") == "true"
+	})
+
+	rt := &roundtripper.DefaultRoundTripper{}
+	ExpectHealthCheckDrivenRouting(t, rt, gwAddr, ExpectedRequest{Path: "/"}, "infra-backend-v1", EchoHealthToggle(rt, toggleAddr, "/health"), 10*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.True(t, healthy, "expected backend to be restored")
+}
+
+func TestEchoHealthToggle(t *testing.T) {
+	var queries []string
+	addr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+	})
+
+	toggle := EchoHealthToggle(&roundtripper.DefaultRoundTripper{}, addr, "/health")
+	require.NoError(t, toggle(false))
+	require.NoError(t, toggle(true))
+	require.Equal(t, "POST /health?healthy=false,POST /health?healthy=true", strings.Join(queries, ","))
+}
+
+func TestExpectUnavailableUntilReady(t *testing.T) {
+	// The fake Gateway responds with 503 until its backend has started.
+	ready := time.Now().Add(1500 * time.Millisecond)
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if time.Now().Before(ready) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectUnavailableUntilReady(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, 10*time.Second)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeNamespace is the namespace reported by echo responses of fake backends.
+const fakeNamespace = "gateway-conformance-infra"
+
+// fakeGateway starts an HTTP server standing in for a Gateway and returns its
+// address. The handler decides how each request is routed, typically by
+// calling echo for the backend that should serve it.
+func fakeGateway(t *testing.T, handler http.HandlerFunc) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+// echo responds to the request the way echoserver does, reporting a Pod of
+// the provided backend.
+func echo(w http.ResponseWriter, r *http.Request, backend string) {
+	w.Header().Set("Content-type", "application/json")
+	_ = json.NewEncoder(w).Encode(roundtripper.CapturedRequest{
+		Path:      r.URL.Path,
+		Host:      r.Host,
+		Method:    r.Method,
+		Protocol:  r.Proto,
+		Headers:   r.Header,
+		Namespace: fakeNamespace,
+		Pod:       backend + "-7f9c8d6b5-x2x9z",
+	})
+}
+
+// fakeRawGateway starts a TCP server standing in for a Gateway that needs to
+// observe requests exactly as they were sent. For every connection, the
+// request head (up to and including the empty line) is sent on the returned
+// channel and respond decides on the status code of the response.
+func fakeRawGateway(t *testing.T, respond func(head []byte) int) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	heads := make(chan []byte, 16)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+
+				var head bytes.Buffer
+				reader := bufio.NewReader(conn)
+				for {
+					line, err := reader.ReadBytes('\n')
+					head.Write(line)
+					if err != nil || bytes.Equal(line, []byte("\r\n")) {
+						break
+					}
+				}
+				heads <- head.Bytes()
+
+				status := respond(head.Bytes())
+				fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", status, http.StatusText(status))
+			}()
+		}
+	}()
+
+	return listener.Addr().String(), heads
+}
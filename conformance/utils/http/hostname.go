@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectCaseInsensitiveHostnameMatch sends the expected request with
+// upper-case, lower-case and mixed-case variants of its Host header and
+// expects every variant to receive the expected response, since hostnames are
+// case-insensitive.
+func ExpectCaseInsensitiveHostnameMatch(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+	t.Helper()
+
+	host := expected.Request.Host
+	variants := []string{strings.ToUpper(host), strings.ToLower(host), mixedCase(host)}
+	for i := range variants {
+		variant := variants[i]
+		t.Run(variant, func(t *testing.T) {
+			tc := expected
+			tc.Request.Host = variant
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, tc)
+		})
+	}
+}
+
+// ExpectHostPortIgnored sends the expected request with its Host header
+// suffixed by each of the provided ports, e.g. example.com:8080, and expects
+// every variant to receive the expected response, since only the hostname
+// portion of the Host header is matched against Listener and HTTPRoute
+// hostnames. If no ports are provided, 80 and 8080 are used.
+func ExpectHostPortIgnored(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, ports ...string) {
+	t.Helper()
+
+	if len(ports) == 0 {
+		ports = []string{"80", "8080"}
+	}
+	for i := range ports {
+		hostPort := net.JoinHostPort(expected.Request.Host, ports[i])
+		t.Run(hostPort, func(t *testing.T) {
+			tc := expected
+			tc.Request.Host = hostPort
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, tc)
+		})
+	}
+}
+
+// ExpectIPLiteralHostNotMatched verifies that a request with an IP literal as
+// Host header doesn't match a Listener with a hostname. The expected request,
+// whose Host matches the Listener hostname, must first get the expected
+// response, then the same request with each of the provided IP literals as
+// Host must get a 404. IPv6 literals must be enclosed in brackets, e.g.
+// [2001:db8::1]. If no IP literals are provided, 192.0.2.1 and [2001:db8::1]
+// are used.
+func ExpectIPLiteralHostNotMatched(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, ipHosts ...string) {
+	t.Helper()
+
+	if len(ipHosts) == 0 {
+		ipHosts = []string{"192.0.2.1", "[2001:db8::1]"}
+	}
+	for _, ipHost := range ipHosts {
+		ip := strings.TrimSuffix(strings.TrimPrefix(ipHost, "["), "]")
+		require.NotNilf(t, net.ParseIP(ip), "expected %s to be an IP literal", ipHost)
+	}
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+	for i := range ipHosts {
+		ipHost := ipHosts[i]
+		t.Run(ipHost, func(t *testing.T) {
+			req := expected.Request
+			req.Host = ipHost
+			ExpectNotServed(t, r, gwAddr, req)
+		})
+	}
+}
+
+// ExpectHTTP2AuthorityRouting sends the expected request over cleartext
+// HTTP/2 with its Host as the :authority pseudo-header and verifies that the
+// Gateway used it for hostname matching, routing the request to the expected
+// backend.
+func ExpectHTTP2AuthorityRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	req.Protocol = roundtripper.H2CPriorKnowledgeProtocol
+
+	t.Logf("Making HTTP/2 request with :authority %s to %s", expected.Request.Host, gwAddr)
+	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
+	require.Equalf(t, "HTTP/2.0", cRes.Protocol, "expected response over HTTP/2, got %s", cRes.Protocol)
+
+	ExpectResponse(t, cReq, cRes, expected)
+	assert.Equalf(t, expected.Request.Host, cReq.Host, "expected backend to observe :authority %s, got %s", expected.Request.Host, cReq.Host)
+}
+
+// ExpectHostOverride makes the expected request and verifies that the Gateway
+// applied the hostname configured by a HeaderModifier or URLRewrite filter:
+// the backend must have observed overriddenHost as Host rather than the host
+// sent by the client. If the Gateway responds with a redirect instead (and the
+// RoundTripper doesn't follow it), the Location header must point at
+// overriddenHost.
+func ExpectHostOverride(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, overriddenHost string) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
+
+	if cRes.StatusCode >= 300 && cRes.StatusCode < 400 {
+		location, ok := cRes.Headers["Location"]
+		require.Truef(t, ok && len(location) > 0, "expected Location header in %d response", cRes.StatusCode)
+		locationURL, err := url.Parse(location[0])
+		require.NoErrorf(t, err, "error parsing Location header %s", location[0])
+		assert.Equalf(t, overriddenHost, locationURL.Host, "expected redirect to overridden host %s, got %s", overriddenHost, location[0])
+		return
+	}
+
+	ExpectResponse(t, cReq, cRes, expected)
+	assert.Equalf(t, overriddenHost, cReq.Host, "expected backend to observe overridden host %s, got %s", overriddenHost, cReq.Host)
+}
+
+// ExpectHostnameIntersectionServed sends the expected request with each of
+// the provided hosts as Host header, given a Listener with listenerHostname
+// and a route attached to it with routeHostnames. Empty hostnames match any
+// host. Only the hosts in the intersection of both must get the expected
+// response, while every other host must get a 404.
+func ExpectHostnameIntersectionServed(t *testing.T, r roundtripper.RoundTripper, gwAddr, listenerHostname string, routeHostnames []string, expected ExpectedResponse, hosts ...string) {
+	t.Helper()
+
+	for i := range hosts {
+		host := hosts[i]
+		t.Run(host, func(t *testing.T) {
+			tc := expected
+			tc.Request.Host = host
+			if !hostnameIntersects(listenerHostname, routeHostnames, host) {
+				t.Logf("Expecting %s not to be served by listener hostname %q and route hostnames %v", host, listenerHostname, routeHostnames)
+				ExpectNotServed(t, r, gwAddr, tc.Request)
+				return
+			}
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, tc)
+		})
+	}
+}
+
+// ExpectListenerHostnameTransition verifies that the Gateway converges once
+// the hostname of a Listener changes from oldHost to newHost. The expected
+// request, with oldHost as Host, must be served before update is called to
+// change the Listener hostname. Within budget, requests to newHost must then
+// get the expected response while requests to oldHost must get a 404.
+func ExpectListenerHostnameTransition(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, oldHost, newHost string, update func() error, budget time.Duration) {
+	t.Helper()
+
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+	oldExpected, newExpected := expected, expected
+	oldExpected.Request.Host = oldHost
+	newExpected.Request.Host = newHost
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, oldExpected)
+
+	t.Logf("Updating listener hostname from %s to %s", oldHost, newHost)
+	require.NoError(t, update(), "error updating listener hostname")
+
+	oldReq := toRoundTripperRequest(gwAddr, oldExpected.Request)
+	newReq := toRoundTripperRequest(gwAddr, newExpected.Request)
+	require.Eventually(t, func() bool {
+		_, cRes, err := r.CaptureRoundTrip(newReq)
+		if err != nil || cRes.StatusCode != expected.StatusCode {
+			t.Logf("Request to new hostname %s not served yet", newHost)
+			return false
+		}
+		_, cRes, err = r.CaptureRoundTrip(oldReq)
+		if err != nil || cRes.StatusCode != http.StatusNotFound {
+			t.Logf("Request to old hostname %s still served", oldHost)
+			return false
+		}
+		return true
+	}, budget, 1*time.Second, "routing never converged from hostname %s to %s", oldHost, newHost)
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, newExpected)
+	ExpectNotServed(t, r, gwAddr, oldExpected.Request)
+}
+
+// hostnameIntersects returns true if host matches both the listener hostname
+// and one of the route hostnames.
+func hostnameIntersects(listenerHostname string, routeHostnames []string, host string) bool {
+	if !hostnameMatches(listenerHostname, host) {
+		return false
+	}
+	if len(routeHostnames) == 0 {
+		return true
+	}
+	for _, hostname := range routeHostnames {
+		if hostnameMatches(hostname, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostnameMatches returns true if host matches the hostname, which may be
+// empty to match any host or prefixed with a wildcard label to match any host
+// with the same suffix and at least one more label.
+func hostnameMatches(hostname, host string) bool {
+	host = strings.ToLower(host)
+	hostname = strings.ToLower(hostname)
+	switch {
+	case hostname == "":
+		return true
+	case strings.HasPrefix(hostname, "*."):
+		suffix := hostname[1:]
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	default:
+		return host == hostname
+	}
+}
+
+// mixedCase alternates the case of the letters in s, starting with upper case.
+func mixedCase(s string) string {
+	upper := true
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) {
+			return r
+		}
+		defer func() { upper = !upper }()
+		if upper {
+			return unicode.ToUpper(r)
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
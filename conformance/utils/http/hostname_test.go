@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectCaseInsensitiveHostnameMatch(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.ToLower(r.Host) != "example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectCaseInsensitiveHostnameMatch(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "example.com", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	})
+}
+
+func TestExpectHostPortIgnored(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if host != "example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectHostPortIgnored(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "example.com", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, "80", "8080", "443")
+}
+
+func TestExpectIPLiteralHostNotMatched(t *testing.T) {
+	// The fake Gateway has a single listener with the example.com hostname.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectIPLiteralHostNotMatched(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "example.com", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	})
+}
+
+func TestExpectHTTP2AuthorityRouting(t *testing.T) {
+	// The fake Gateway only speaks cleartext HTTP/2 and routes on the
+	// :authority, which Go servers expose as the request Host.
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+		switch r.Host {
+		case "foo.example.com":
+			echo(w, r, "infra-backend-v1")
+		case "bar.example.com":
+			echo(w, r, "infra-backend-v2")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	ExpectHTTP2AuthorityRouting(t, &roundtripper.DefaultRoundTripper{}, server.Listener.Addr().String(), ExpectedResponse{
+		Request:   ExpectedRequest{Host: "bar.example.com", Path: "/"},
+		Backend:   "infra-backend-v2",
+		Namespace: fakeNamespace,
+	})
+}
+
+func TestExpectHostOverride(t *testing.T) {
+	// The fake Gateway rewrites the Host header before echoing the request
+	// back, as a URLRewrite filter would, for requests matching the route
+	// on a header.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Route") != "host-override" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.Host = "override.example.com"
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectHostOverride(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "original.example.com", Path: "/", Headers: map[string]string{"X-Route": "host-override"}},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, "override.example.com")
+}
+
+func TestMixedCase(t *testing.T) {
+	if got := mixedCase("example.com"); got != "ExAmPlE.cOm" {
+		t.Errorf("expected ExAmPlE.cOm, got %s", got)
+	}
+}
+
+func TestExpectHostnameIntersectionServed(t *testing.T) {
+	tests := []struct {
+		name             string
+		listenerHostname string
+		routeHostnames   []string
+		served           []string
+		hosts            []string
+	}{{
+		name:             "partial overlap",
+		listenerHostname: "*.example.com",
+		routeHostnames:   []string{"foo.example.com", "bar.example.net"},
+		served:           []string{"foo.example.com"},
+		hosts:            []string{"foo.example.com", "bar.example.com", "bar.example.net"},
+	}, {
+		name:             "full overlap",
+		listenerHostname: "*.example.com",
+		routeHostnames:   []string{"*.example.com"},
+		served:           []string{"foo.example.com", "foo.bar.example.com"},
+		hosts:            []string{"foo.bar.example.com", "example.com"},
+	}, {
+		name:             "disjoint",
+		listenerHostname: "*.example.com",
+		routeHostnames:   []string{"foo.example.net"},
+		hosts:            []string{"foo.example.net"},
+	}}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// The fake Gateway serves exactly the hosts an implementation is
+			// expected to serve.
+			gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+				for _, host := range tc.served {
+					if r.Host == host {
+						echo(w, r, "infra-backend-v1")
+						return
+					}
+				}
+				w.WriteHeader(http.StatusNotFound)
+			})
+
+			ExpectHostnameIntersectionServed(t, &roundtripper.DefaultRoundTripper{}, gwAddr, tc.listenerHostname, tc.routeHostnames, ExpectedResponse{
+				Request:   ExpectedRequest{Path: "/"},
+				Backend:   "infra-backend-v1",
+				Namespace: fakeNamespace,
+			}, tc.hosts...)
+		})
+	}
+}
+
+func TestExpectListenerHostnameTransition(t *testing.T) {
+	// The fake Gateway serves the current listener hostname only.
+	var hostname atomic.Value
+	hostname.Store("old.example.com")
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != hostname.Load().(string) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+	update := func() error {
+		hostname.Store("new.example.com")
+		return nil
+	}
+
+	ExpectListenerHostnameTransition(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, "old.example.com", "new.example.com", update, 5*time.Second)
+}
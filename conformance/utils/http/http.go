@@ -17,13 +17,14 @@ limitations under the License.
 package http
 
 import (
+	"context"
 	"net/url"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
 )
@@ -34,6 +35,12 @@ type ExpectedResponse struct {
 	StatusCode int
 	Backend    string
 	Namespace  string
+
+	// Headers are matched against the headers of the response.
+	Headers []HeaderMatch
+	// BackendHeaders are matched against the request headers echoed by the
+	// backend, e.g. to verify that a header was removed by a filter.
+	BackendHeaders []HeaderMatch
 }
 
 // ExpectedRequest can be used as both the request to make and a means to verify
@@ -55,12 +62,21 @@ const maxTimeToConsistency = 30 * time.Second
 // maxTimeToConsistency, the test will fail.
 const requiredConsecutiveSuccesses = 3
 
-// MakeRequestAndExpectEventuallyConsistentResponse makes a request with the given parameters,
-// understanding that the request may fail for some amount of time.
+// MakeRequestAndExpectEventuallyConsistentResponse is
+// MakeRequestAndExpectEventuallyConsistentResponseWithContext with a
+// background context.
+func MakeRequestAndExpectEventuallyConsistentResponse(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+	t.Helper()
+	MakeRequestAndExpectEventuallyConsistentResponseWithContext(context.Background(), t, r, gwAddr, expected)
+}
+
+// MakeRequestAndExpectEventuallyConsistentResponseWithContext makes a request with the given parameters,
+// understanding that the request may fail for some amount of time. Cancelling ctx aborts the request
+// in flight and fails the test.
 //
 // Once the request succeeds consistently with the response having the expected status code, make
 // additional assertions on the response body using the provided ExpectedResponse.
-func MakeRequestAndExpectEventuallyConsistentResponse(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+func MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx context.Context, t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
 	t.Helper()
 
 	if expected.Request.Method == "" {
@@ -71,92 +87,104 @@ func MakeRequestAndExpectEventuallyConsistentResponse(t *testing.T, r roundtripp
 		expected.StatusCode = 200
 	}
 
-	t.Logf("Making %s request to http://%s%s", expected.Request.Method, gwAddr, expected.Request.Path)
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	req.Context = ctx
 
-	req := roundtripper.Request{
-		Method:   expected.Request.Method,
-		Host:     expected.Request.Host,
-		URL:      url.URL{Scheme: "http", Host: gwAddr, Path: expected.Request.Path},
-		Protocol: "HTTP",
+	t.Logf("Making %s request to %s", req.Method, req.URL.String())
+
+	// The last round trip is logged once, whether the response never became
+	// consistent or didn't match.
+	var trace *roundtripper.Trace
+	failed := t.Failed()
+	defer func() { logTraceOnFailure(t, failed, trace) }()
+	waitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses, &trace)
+	ExpectResponse(t, trace.CapturedRequest, trace.CapturedResponse, expected)
+}
+
+// toRoundTripperRequest converts an ExpectedRequest into the request sent to
+// the Gateway at gwAddr, defaulting the method to GET. A query string in the
+// path is sent as such.
+func toRoundTripperRequest(gwAddr string, req ExpectedRequest) roundtripper.Request {
+	method := req.Method
+	if method == "" {
+		method = "GET"
 	}
 
-	if expected.Request.Headers != nil {
-		req.Headers = map[string][]string{}
-		for name, value := range expected.Request.Headers {
-			req.Headers[name] = []string{value}
-		}
+	path, query := req.Path, ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		path, query = path[:i], path[i+1:]
 	}
 
-	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
-	ExpectResponse(t, cReq, cRes, expected)
+	rtReq := roundtripper.Request{
+		Method:   method,
+		Host:     req.Host,
+		URL:      url.URL{Scheme: "http", Host: gwAddr, Path: path, RawQuery: query},
+		Protocol: "HTTP",
+	}
+	if req.Headers != nil {
+		rtReq.Headers = map[string][]string{}
+		for name, value := range req.Headers {
+			rtReq.Headers[name] = []string{value}
+		}
+	}
+	return rtReq
 }
 
 // WaitForConsistency repeats the provided request until it completes with a response having
 // the expected status code consistently. The provided threshold determines how many times in
-// a row this must occur to be considered "consistent".
+// a row this must occur to be considered "consistent". Cancelling the Context of the request
+// stops the repetitions and fails the test.
 func WaitForConsistency(t *testing.T, r roundtripper.RoundTripper, req roundtripper.Request, expected ExpectedResponse, threshold int) (*roundtripper.CapturedRequest, *roundtripper.CapturedResponse) {
-	var (
-		cReq         *roundtripper.CapturedRequest
-		cRes         *roundtripper.CapturedResponse
-		err          error
-		numSuccesses int
-	)
-
-	require.Eventually(t, func() bool {
-		cReq, cRes, err = r.CaptureRoundTrip(req)
+	var trace *roundtripper.Trace
+	failed := t.Failed()
+	defer func() { logTraceOnFailure(t, failed, trace) }()
+	waitForConsistency(t, r, req, expected, threshold, &trace)
+	return trace.CapturedRequest, trace.CapturedResponse
+}
+
+// waitForConsistency is like WaitForConsistency but sets last to the Trace of
+// every round trip, so that callers can log the last one if the response
+// never becomes consistent.
+func waitForConsistency(t *testing.T, r roundtripper.RoundTripper, req roundtripper.Request, expected ExpectedResponse, threshold int, last **roundtripper.Trace) {
+	ctx := req.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var numSuccesses int
+	waitErr := wait.PollImmediateWithContext(ctx, 1*time.Second, maxTimeToConsistency, func(ctx context.Context) (bool, error) {
+		trace := CaptureRoundTrip(r, req)
+		*last = trace
+		cRes, err := trace.CapturedResponse, trace.Err
 		if err != nil {
 			numSuccesses = 0
 			t.Logf("Request failed, not ready yet: %v", err.Error())
-			return false
+			return false, nil
 		}
 
 		if cRes.StatusCode != expected.StatusCode {
 			numSuccesses = 0
 			t.Logf("Expected response to have status %d but got %d, not ready yet", expected.StatusCode, cRes.StatusCode)
-			return false
+			return false, nil
 		}
 
 		numSuccesses++
 		if numSuccesses < threshold {
 			t.Logf("Request has passed %d times in a row of the desired %d, not ready yet", numSuccesses, threshold)
-			return false
+			return false, nil
 		}
 
 		t.Logf("Request has passed %d times in a row of the desired %d, ready!", numSuccesses, threshold)
-		return true
-	}, maxTimeToConsistency, 1*time.Second, "error making request, never got expected status")
-
-	return cReq, cRes
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error making request, never got expected status")
 }
 
 // ExpectResponse verifies that a captured request and response match the
 // provided ExpectedResponse.
 func ExpectResponse(t *testing.T, cReq *roundtripper.CapturedRequest, cRes *roundtripper.CapturedResponse, expected ExpectedResponse) {
 	t.Helper()
-	assert.Equal(t, expected.StatusCode, cRes.StatusCode, "expected status code to be %d, got %d", expected.StatusCode, cRes.StatusCode)
-	if cRes.StatusCode == 200 {
-		assert.Equal(t, expected.Request.Path, cReq.Path, "expected path to be %s, got %s", expected.Request.Path, cReq.Path)
-		assert.Equal(t, expected.Request.Method, cReq.Method, "expected method to be %s, got %s", expected.Request.Method, cReq.Method)
-		assert.Equal(t, expected.Namespace, cReq.Namespace, "expected namespace to be %s, got %s", expected.Namespace, cReq.Namespace)
-		if expected.Request.Headers != nil {
-			if cReq.Headers == nil {
-				t.Error("No headers captured")
-			} else {
-				for name, val := range cReq.Headers {
-					cReq.Headers[strings.ToLower(name)] = val
-				}
-				for name, expectedVal := range expected.Request.Headers {
-					actualVal, ok := cReq.Headers[strings.ToLower(name)]
-					if !ok {
-						t.Errorf("Expected %s header to be set, actual headers: %v", name, cReq.Headers)
-					} else if actualVal[0] != expectedVal {
-						t.Errorf("Expected %s header to be set to %s, got %s", name, expectedVal, actualVal[0])
-					}
-				}
-			}
-		}
-		if !strings.HasPrefix(cReq.Pod, expected.Backend) {
-			t.Errorf("Expected pod name to start with %s, got %s", expected.Backend, cReq.Pod)
-		}
+	if err := CompareResponse(cReq, cRes, expected); err != nil {
+		t.Error(err)
 	}
 }
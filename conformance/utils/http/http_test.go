@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestToRoundTripperRequestIPv6(t *testing.T) {
+	req := toRoundTripperRequest("[2001:db8::1]:8080", ExpectedRequest{Path: "/v6?family=ipv6"})
+	require.Equal(t, "http://[2001:db8::1]:8080/v6?family=ipv6", req.URL.String())
+
+	parsed, err := url.Parse(req.URL.String())
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8::1", parsed.Hostname())
+	require.Equal(t, "8080", parsed.Port())
+}
+
+// recordingRoundTripper records the requests it receives and answers them
+// with an empty 200 response, echoing their path and query as a backend does.
+type recordingRoundTripper struct {
+	requests []roundtripper.Request
+}
+
+func (r *recordingRoundTripper) CaptureRoundTrip(req roundtripper.Request) (*roundtripper.CapturedRequest, *roundtripper.CapturedResponse, error) {
+	r.requests = append(r.requests, req)
+	return &roundtripper.CapturedRequest{Path: req.URL.RequestURI(), Method: req.Method}, &roundtripper.CapturedResponse{StatusCode: 200}, nil
+}
+
+func TestMakeRequestAndExpectEventuallyConsistentResponseWithContext(t *testing.T) {
+	type contextKey struct{}
+	ctx := context.WithValue(context.Background(), contextKey{}, "test")
+	r := &recordingRoundTripper{}
+
+	MakeRequestAndExpectEventuallyConsistentResponseWithContext(ctx, t, r, "192.0.2.1:80", ExpectedResponse{
+		Request: ExpectedRequest{Path: "/"},
+	})
+
+	require.Len(t, r.requests, requiredConsecutiveSuccesses)
+	for _, req := range r.requests {
+		require.Equal(t, "test", req.Context.Value(contextKey{}), "expected the requests to be made with the provided context")
+	}
+}
+
+func TestMakeRequestAndExpectEventuallyConsistentResponseURL(t *testing.T) {
+	r := &recordingRoundTripper{}
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, "[2001:db8::1]:8080", ExpectedResponse{
+		Request: ExpectedRequest{Path: "/v6?family=ipv6"},
+	})
+
+	require.Len(t, r.requests, requiredConsecutiveSuccesses)
+	for _, req := range r.requests {
+		require.Equal(t, "http://[2001:db8::1]:8080/v6?family=ipv6", req.URL.String(), "expected the query to be sent as such to the IPv6 Gateway")
+	}
+}
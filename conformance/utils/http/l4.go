@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectedL4Response defines the backend expected to echo a payload sent to
+// a TCPRoute or UDPRoute.
+type ExpectedL4Response struct {
+	Protocol  roundtripper.L4Protocol
+	Payload   string
+	Backend   string
+	Namespace string
+}
+
+// l4RoundTripper returns the L4RoundTripper implementation of r, failing the
+// test if r doesn't support L4 round trips.
+func l4RoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.L4RoundTripper {
+	t.Helper()
+
+	l4RT, ok := r.(roundtripper.L4RoundTripper)
+	require.Truef(t, ok, "%T does not support L4 round trips", r)
+	return l4RT
+}
+
+// MakeL4RequestAndExpectEventuallyConsistentResponse sends the payload to the
+// Gateway at gwAddr, understanding that it may not be routed for some amount
+// of time, just like MakeRequestAndExpectEventuallyConsistentResponse does
+// for HTTP requests. Once the payload is echoed back consistently, the
+// backend that echoed it must be the expected one.
+func MakeL4RequestAndExpectEventuallyConsistentResponse(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedL4Response) {
+	t.Helper()
+
+	l4RT := l4RoundTripper(t, r)
+	req := roundtripper.L4Request{Protocol: expected.Protocol, Address: gwAddr, Payload: []byte(expected.Payload)}
+
+	t.Logf("Sending %s payload to %s", expected.Protocol, gwAddr)
+
+	var (
+		cRes         *roundtripper.CapturedL4Response
+		numSuccesses int
+	)
+	require.Eventually(t, func() bool {
+		var err error
+		cRes, err = l4RT.CaptureL4RoundTrip(req)
+		if err != nil {
+			numSuccesses = 0
+			t.Logf("%s round trip failed, not ready yet: %v", expected.Protocol, err)
+			return false
+		}
+		if cRes.Payload != expected.Payload {
+			numSuccesses = 0
+			t.Logf("Expected payload %q to be echoed but got %q, not ready yet", expected.Payload, cRes.Payload)
+			return false
+		}
+
+		numSuccesses++
+		if numSuccesses < requiredConsecutiveSuccesses {
+			t.Logf("%s round trip has passed %d times in a row of the desired %d, not ready yet", expected.Protocol, numSuccesses, requiredConsecutiveSuccesses)
+			return false
+		}
+		return true
+	}, maxTimeToConsistency, 1*time.Second, "error sending %s payload, never got it echoed", expected.Protocol)
+
+	require.Equalf(t, expected.Namespace, cRes.Namespace, "expected namespace to be %s, got %s", expected.Namespace, cRes.Namespace)
+	require.Truef(t, strings.HasPrefix(cRes.Pod, expected.Backend), "expected pod name to start with %s, got %s", expected.Backend, cRes.Pod)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeL4Gateway starts TCP and UDP servers standing in for a Gateway routing
+// to an L4 echo backend and returns their addresses.
+func fakeL4Gateway(t *testing.T, backend string) (tcpAddr, udpAddr string) {
+	echoL4 := func(payload []byte) []byte {
+		data, _ := json.Marshal(roundtripper.CapturedL4Response{Namespace: fakeNamespace, Pod: backend + "-7f9c8d6b5-x2x9z", Payload: string(payload)})
+		return data
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				payload, _ := ioutil.ReadAll(conn)
+				_, _ = conn.Write(echoL4(payload))
+			}()
+		}
+	}()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { packetConn.Close() })
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := packetConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = packetConn.WriteTo(echoL4(buf[:n]), addr)
+		}
+	}()
+
+	return listener.Addr().String(), packetConn.LocalAddr().String()
+}
+
+func TestMakeL4RequestAndExpectEventuallyConsistentResponse(t *testing.T) {
+	tcpAddr, udpAddr := fakeL4Gateway(t, "tcp-backend-v1")
+
+	t.Run("TCP", func(t *testing.T) {
+		MakeL4RequestAndExpectEventuallyConsistentResponse(t, &roundtripper.DefaultRoundTripper{}, tcpAddr, ExpectedL4Response{
+			Protocol:  roundtripper.L4ProtocolTCP,
+			Payload:   "hello over tcp",
+			Backend:   "tcp-backend-v1",
+			Namespace: fakeNamespace,
+		})
+	})
+	t.Run("UDP", func(t *testing.T) {
+		MakeL4RequestAndExpectEventuallyConsistentResponse(t, &roundtripper.DefaultRoundTripper{}, udpAddr, ExpectedL4Response{
+			Protocol:  roundtripper.L4ProtocolUDP,
+			Payload:   "hello over udp",
+			Backend:   "tcp-backend-v1",
+			Namespace: fakeNamespace,
+		})
+	})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectResponseHeaderLimit makes the provided request, which must cause the
+// backend to respond with count headers whose names start with headerPrefix,
+// and verifies the Gateway limits responses to maxHeaders such headers. When
+// count exceeds the limit, the Gateway must either reject the response with a
+// 502 or truncate it to at most maxHeaders headers. Otherwise every header
+// must be forwarded.
+func ExpectResponseHeaderLimit(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, headerPrefix string, count, maxHeaders int) {
+	t.Helper()
+
+	_, cRes, err := r.CaptureRoundTrip(toRoundTripperRequest(gwAddr, req))
+	require.NoError(t, err, "error making request")
+
+	if count <= maxHeaders {
+		require.Equal(t, http.StatusOK, cRes.StatusCode, "expected response within the header limit to be forwarded")
+		require.Equal(t, count, countHeaders(cRes.Headers, headerPrefix), "expected all %d headers to be forwarded", count)
+		return
+	}
+
+	switch cRes.StatusCode {
+	case http.StatusBadGateway:
+		t.Logf("Response with %d headers was rejected", count)
+	case http.StatusOK:
+		forwarded := countHeaders(cRes.Headers, headerPrefix)
+		require.LessOrEqualf(t, forwarded, maxHeaders, "expected response to be truncated to %d headers, got %d", maxHeaders, forwarded)
+		t.Logf("Response with %d headers was truncated to %d", count, forwarded)
+	default:
+		t.Fatalf("Expected response with %d headers to be rejected with %d or truncated, got %d", count, http.StatusBadGateway, cRes.StatusCode)
+	}
+}
+
+// ExpectRequestHeaderCountLimit sends the expected request with count
+// additional headers whose names start with headerPrefix and verifies the
+// Gateway limits requests to maxHeaders such headers. When count exceeds the
+// limit, the Gateway must either reject the request with a 431 or 400, or
+// forward it with at most maxHeaders headers. Otherwise the request must get
+// the expected response and every header must be forwarded. The expected
+// response is awaited first so that the route is known to be programmed.
+func ExpectRequestHeaderCountLimit(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, headerPrefix string, count, maxHeaders int) {
+	t.Helper()
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	if req.Headers == nil {
+		req.Headers = map[string][]string{}
+	}
+	for name, values := range roundtripper.GenerateHeaders(headerPrefix, count) {
+		req.Headers[name] = values
+	}
+
+	t.Logf("Making request with %d headers to %s", count, gwAddr)
+	cReq, cRes, err := r.CaptureRoundTrip(req)
+	require.NoError(t, err, "error making request")
+
+	if count <= maxHeaders {
+		require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected request within the header limit to be forwarded, got %d", cRes.StatusCode)
+		require.Truef(t, strings.HasPrefix(cReq.Pod, expected.Backend), "expected pod name to start with %s, got %s", expected.Backend, cReq.Pod)
+		require.Equal(t, count, countHeaders(cReq.Headers, headerPrefix), "expected all %d headers to be forwarded", count)
+		return
+	}
+
+	switch cRes.StatusCode {
+	case http.StatusRequestHeaderFieldsTooLarge, http.StatusBadRequest:
+		t.Logf("Request with %d headers was rejected with %d", count, cRes.StatusCode)
+	case http.StatusOK:
+		forwarded := countHeaders(cReq.Headers, headerPrefix)
+		require.LessOrEqualf(t, forwarded, maxHeaders, "expected request to be truncated to %d headers, got %d", maxHeaders, forwarded)
+		t.Logf("Request with %d headers was truncated to %d", count, forwarded)
+	default:
+		t.Fatalf("Expected request with %d headers to be rejected with %d or truncated, got %d", count, http.StatusRequestHeaderFieldsTooLarge, cRes.StatusCode)
+	}
+}
+
+// countHeaders returns the number of header values whose name starts with
+// prefix, ignoring case.
+func countHeaders(headers map[string][]string, prefix string) int {
+	n := 0
+	for name, values := range headers {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix)) {
+			n += len(values)
+		}
+	}
+	return n
+}
+
+// ExpectURILengthLimit verifies the Gateway enforces a maximum request URI
+// length of maxLength. The expected request, whose path is padded to exactly
+// maxLength, must receive the expected response while a request whose URI is
+// one byte longer must be rejected with a 414 or have its connection reset.
+func ExpectURILengthLimit(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, maxLength int) {
+	t.Helper()
+
+	require.LessOrEqualf(t, len(expected.Request.Path), maxLength, "expected request path must not exceed %d bytes", maxLength)
+
+	withinLimit := expected
+	withinLimit.Request.Path = padPath(expected.Request.Path, maxLength)
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, withinLimit)
+
+	overLimit := toRoundTripperRequest(gwAddr, expected.Request)
+	overLimit.URL.Path = padPath(expected.Request.Path, maxLength+1)
+	t.Logf("Making request with a %d bytes URI to %s", maxLength+1, gwAddr)
+	_, cRes, err := r.CaptureRoundTrip(overLimit)
+	if err != nil {
+		t.Logf("Request with a URI exceeding the limit failed: %v", err)
+		return
+	}
+	require.Equalf(t, http.StatusRequestURITooLong, cRes.StatusCode, "expected request with a URI exceeding %d bytes to be rejected with %d, got %d", maxLength, http.StatusRequestURITooLong, cRes.StatusCode)
+}
+
+// padPath pads the path with a trailing segment so that it is length bytes
+// long.
+func padPath(path string, length int) string {
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	if len(path) >= length {
+		return path[:length]
+	}
+	return path + strings.Repeat("a", length-len(path))
+}
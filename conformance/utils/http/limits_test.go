@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectResponseHeaderLimit(t *testing.T) {
+	const maxHeaders = 50
+
+	// The fake backend emits as many headers as requested, the fake Gateway
+	// either truncates or rejects responses exceeding the limit.
+	newGateway := func(reject bool) string {
+		return fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			count, _ := strconv.Atoi(r.URL.Query().Get("headers"))
+			if count > maxHeaders {
+				if reject {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				count = maxHeaders
+			}
+			for i := 0; i < count; i++ {
+				w.Header().Set(fmt.Sprintf("X-Many-%d", i), "value")
+			}
+			echo(w, r, "infra-backend-v1")
+		})
+	}
+	r := &roundtripper.DefaultRoundTripper{}
+
+	for _, reject := range []bool{false, true} {
+		gwAddr := newGateway(reject)
+		for _, count := range []int{10, 100} {
+			t.Run(fmt.Sprintf("reject=%t/headers=%d", reject, count), func(t *testing.T) {
+				req := ExpectedRequest{Path: "/?headers=" + strconv.Itoa(count)}
+				ExpectResponseHeaderLimit(t, r, gwAddr, req, "X-Many-", count, maxHeaders)
+			})
+		}
+	}
+}
+
+func TestExpectURILengthLimit(t *testing.T) {
+	const maxLength = 2048
+
+	// The fake Gateway matches on a path prefix and caps URIs.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if len(r.RequestURI) > maxLength {
+			w.WriteHeader(http.StatusRequestURITooLong)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectURILengthLimit(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/long"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, maxLength)
+}
+
+func TestPadPath(t *testing.T) {
+	if got := padPath("/long", 10); got != "/long/aaaa" {
+		t.Errorf("expected /long/aaaa, got %s", got)
+	}
+}
+
+func TestExpectRequestHeaderCountLimit(t *testing.T) {
+	const maxHeaders = 100
+
+	// The fake Gateway rejects requests with more than maxHeaders generated
+	// headers.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if countHeaders(r.Header, "X-Many-") > maxHeaders {
+			w.WriteHeader(http.StatusRequestHeaderFieldsTooLarge)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	expected := ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}
+	for _, count := range []int{maxHeaders, maxHeaders + 1} {
+		count := count
+		t.Run(strconv.Itoa(count), func(t *testing.T) {
+			ExpectRequestHeaderCountLimit(t, &roundtripper.DefaultRoundTripper{}, gwAddr, expected, "X-Many-", count, maxHeaders)
+		})
+	}
+}
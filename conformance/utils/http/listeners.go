@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectMergedListeners verifies that listeners of multiple Gateways merged
+// onto a single address are all served. Every address in gwAddrs must be the
+// same, and each expected response, typically one per listener hostname, must
+// be received through that shared address.
+func ExpectMergedListeners(t *testing.T, r roundtripper.RoundTripper, gwAddrs []string, expected []ExpectedResponse) {
+	t.Helper()
+
+	require.NotEmpty(t, gwAddrs, "at least one Gateway address is required")
+	for _, gwAddr := range gwAddrs[1:] {
+		require.Equalf(t, gwAddrs[0], gwAddr, "expected Gateways with merged listeners to share an address")
+	}
+
+	for i := range expected {
+		tc := expected[i]
+		t.Run(tc.Request.Host, func(t *testing.T) {
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddrs[0], tc)
+		})
+	}
+}
+
+// ExpectDualStackServing probes the Gateway over each IP family among the
+// provided addresses, typically obtained from the status of the Gateway, and
+// expects both IPv4 and IPv6 clients to get the expected response. If only one
+// family is present, only that family is probed.
+func ExpectDualStackServing(t *testing.T, r roundtripper.RoundTripper, gwAddrs []string, expected ExpectedResponse) {
+	t.Helper()
+
+	byFamily := map[string]string{}
+	for _, gwAddr := range gwAddrs {
+		host, _, err := net.SplitHostPort(gwAddr)
+		require.NoErrorf(t, err, "error parsing Gateway address %s", gwAddr)
+		ip := net.ParseIP(host)
+		require.NotNilf(t, ip, "expected Gateway address %s to be an IP address", gwAddr)
+
+		family := "IPv6"
+		if ip.To4() != nil {
+			family = "IPv4"
+		}
+		if _, ok := byFamily[family]; !ok {
+			byFamily[family] = gwAddr
+		}
+	}
+	require.NotEmpty(t, byFamily, "at least one Gateway address is required")
+
+	for _, family := range []string{"IPv4", "IPv6"} {
+		gwAddr, ok := byFamily[family]
+		if !ok {
+			t.Logf("Gateway has no %s address, not probing it", family)
+			continue
+		}
+		t.Run(family, func(t *testing.T) {
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+		})
+	}
+}
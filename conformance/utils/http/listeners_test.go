@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectMergedListeners(t *testing.T) {
+	// One address serving the listeners of two Gateways.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host {
+		case "first.example.com":
+			echo(w, r, "infra-backend-v1")
+		case "second.example.com":
+			echo(w, r, "infra-backend-v2")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ExpectMergedListeners(t, &roundtripper.DefaultRoundTripper{}, []string{gwAddr, gwAddr}, []ExpectedResponse{{
+		Request:   ExpectedRequest{Host: "first.example.com", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, {
+		Request:   ExpectedRequest{Host: "second.example.com", Path: "/"},
+		Backend:   "infra-backend-v2",
+		Namespace: fakeNamespace,
+	}})
+}
+
+func TestExpectDualStackServing(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	newServer := func(family, addr string) string {
+		listener, err := net.Listen("tcp", addr)
+		require.NoError(t, err)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[family]++
+			mu.Unlock()
+			echo(w, r, "infra-backend-v1")
+		}))
+		server.Listener = listener
+		server.Start()
+		t.Cleanup(server.Close)
+		return listener.Addr().String()
+	}
+	gwAddrs := []string{newServer("IPv4", "127.0.0.1:0"), newServer("IPv6", "[::1]:0")}
+
+	t.Run("probe", func(t *testing.T) {
+		ExpectDualStackServing(t, &roundtripper.DefaultRoundTripper{}, gwAddrs, ExpectedResponse{
+			Request:   ExpectedRequest{Path: "/"},
+			Backend:   "infra-backend-v1",
+			Namespace: fakeNamespace,
+		})
+	})
+
+	require.NotZero(t, hits["IPv4"], "expected the IPv4 address to be probed")
+	require.NotZero(t, hits["IPv6"], "expected the IPv6 address to be probed")
+}
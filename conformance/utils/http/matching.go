@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectNoMatchingRule verifies that a request matching the hostname of a
+// route but none of its rules is answered with a 404 by the Gateway. The
+// matched response is first awaited to ensure the route is programmed, then
+// the same request is sent to unmatchedPath, which no rule of the route must
+// match.
+func ExpectNoMatchingRule(t *testing.T, r roundtripper.RoundTripper, gwAddr string, matched ExpectedResponse, unmatchedPath string) {
+	t.Helper()
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, matched)
+
+	unmatched := ExpectedResponse{
+		Request:    matched.Request,
+		StatusCode: http.StatusNotFound,
+	}
+	unmatched.Request.Path = unmatchedPath
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, unmatched)
+}
+
+// ExpectMostSpecificPrefixMatch verifies that, given routes with competing
+// PathPrefix matches, each of the provided paths is routed to the backend of
+// the longest prefix matching it. prefixBackends maps every configured prefix,
+// e.g. "/" or "/api", to the backend it routes to. Prefixes match on path
+// element boundaries, so "/api" matches "/api/v1" but not "/apiv1".
+func ExpectMostSpecificPrefixMatch(t *testing.T, r roundtripper.RoundTripper, gwAddr, host, namespace string, prefixBackends map[string]string, paths ...string) {
+	t.Helper()
+
+	for i := range paths {
+		path := paths[i]
+		prefix, ok := longestMatchingPrefix(prefixBackends, path)
+		require.Truef(t, ok, "no configured prefix matches %s", path)
+
+		t.Run(path, func(t *testing.T) {
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, ExpectedResponse{
+				Request:   ExpectedRequest{Host: host, Path: path},
+				Backend:   prefixBackends[prefix],
+				Namespace: namespace,
+			})
+		})
+	}
+}
+
+// ExpectContentTypeRouting sends the request once per content type of
+// contentTypeBackends with a Content-Type header set to it, and verifies that
+// each request is routed to the backend the content type maps to, or gets a
+// 404 if it maps to an empty backend. As header matches compare the full
+// value, parameterized content types such as "application/json;
+// charset=utf-8" must be listed separately from their base type.
+func ExpectContentTypeRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, namespace string, contentTypeBackends map[string]string) {
+	t.Helper()
+
+	for contentType, backend := range contentTypeBackends {
+		contentType, backend := contentType, backend
+		t.Run(contentType, func(t *testing.T) {
+
+			expected := ExpectedResponse{Request: req, Backend: backend, Namespace: namespace}
+			expected.Request.Headers = map[string]string{"Content-Type": contentType}
+			for name, value := range req.Headers {
+				expected.Request.Headers[name] = value
+			}
+			if backend == "" {
+				expected.StatusCode = http.StatusNotFound
+				expected.Namespace = ""
+			}
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+		})
+	}
+}
+
+// ExpectMethodRouting sends the request once per method of methodBackends,
+// and verifies that each request is routed to the backend the method maps
+// to, or gets a 404 if it maps to an empty backend. Methods are sent as is,
+// so extension methods such as "PURGE", which method matches can't select,
+// may be listed alongside standard ones like "PATCH" to verify they only
+// reach rules without a method match.
+func ExpectMethodRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, namespace string, methodBackends map[string]string) {
+	t.Helper()
+
+	for method, backend := range methodBackends {
+		method, backend := method, backend
+		t.Run(method, func(t *testing.T) {
+
+			expected := ExpectedResponse{Request: req, Backend: backend, Namespace: namespace}
+			expected.Request.Method = method
+			if backend == "" {
+				expected.StatusCode = http.StatusNotFound
+				expected.Namespace = ""
+			}
+			MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+		})
+	}
+}
+
+// longestMatchingPrefix returns the longest of the provided prefixes matching
+// path element-wise, ignoring trailing slashes.
+func longestMatchingPrefix(prefixBackends map[string]string, path string) (string, bool) {
+	longest, found := "", false
+	for prefix := range prefixBackends {
+		trimmed := strings.TrimSuffix(prefix, "/")
+		if path != trimmed && !strings.HasPrefix(path, trimmed+"/") {
+			continue
+		}
+		if !found || len(trimmed) > len(strings.TrimSuffix(longest, "/")) {
+			longest, found = prefix, true
+		}
+	}
+	return longest, found
+}
+
+// identicalMatchSamples is the number of requests ExpectFirstIdenticalRuleWins
+// makes once the first rule is served, to catch Gateways balancing requests
+// between rules with identical matches.
+const identicalMatchSamples = 10
+
+// ExpectFirstIdenticalRuleWins verifies that, given rules of a route with
+// identical matches, the request is only ever routed to expected.Backend, the
+// backend of the first of these rules, and never to any of the shadowed
+// backends of the rules following it.
+func ExpectFirstIdenticalRuleWins(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, shadowed ...string) {
+	t.Helper()
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	for i := 0; i < identicalMatchSamples; i++ {
+		cReq, cRes, err := r.CaptureRoundTrip(req)
+		require.NoError(t, err, "error making request")
+		require.Equal(t, http.StatusOK, cRes.StatusCode, "expected the request to be served")
+		for _, backend := range shadowed {
+			require.Falsef(t, strings.HasPrefix(cReq.Pod, backend), "expected the first of the rules with identical matches to win, got request served by %s", cReq.Pod)
+		}
+		require.Truef(t, strings.HasPrefix(cReq.Pod, expected.Backend), "expected the request to be served by %s, got %s", expected.Backend, cReq.Pod)
+	}
+}
+
+// ExpectQueryParamRouting makes each of the expected requests, whose Path
+// includes a query string, e.g. "/?animal=whale", and verifies that it gets
+// the expected response. Requests without the query parameters a route
+// matches on are typically expected to get a 404. Matching is verified on the
+// path alone since the backend doesn't report the query string.
+func ExpectQueryParamRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected []ExpectedResponse) {
+	t.Helper()
+
+	for i := range expected {
+		tc := expected[i]
+		if tc.Request.Method == "" {
+			tc.Request.Method = "GET"
+		}
+		if tc.StatusCode == 0 {
+			tc.StatusCode = 200
+		}
+
+		t.Run(tc.Request.Path, func(t *testing.T) {
+			req := toRoundTripperRequest(gwAddr, tc.Request)
+			cReq, cRes := WaitForConsistency(t, r, req, tc, requiredConsecutiveSuccesses)
+
+			tc.Request.Path = req.URL.Path
+			ExpectResponse(t, cReq, cRes, tc)
+		})
+	}
+}
+
+// ExpectNotServed verifies that the request is consistently answered with a
+// 404 by the Gateway, as is the case for requests that would only match a
+// route the Gateway rejected.
+func ExpectNotServed(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest) {
+	t.Helper()
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, ExpectedResponse{
+		Request:    req,
+		StatusCode: http.StatusNotFound,
+	})
+}
+
+// ExpectServiceUnavailable verifies that the request is consistently
+// answered with a 503 by the Gateway, as is the case for requests matching a
+// route whose backends have no ready endpoints. Unlike a 404, this tells the
+// route was matched.
+func ExpectServiceUnavailable(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest) {
+	t.Helper()
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, ExpectedResponse{
+		Request:    req,
+		StatusCode: http.StatusServiceUnavailable,
+	})
+}
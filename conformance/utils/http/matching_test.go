@@ -0,0 +1,213 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectNoMatchingRule(t *testing.T) {
+	// The fake Gateway has a single route for example.com with a rule
+	// matching the /match path prefix.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "example.com" || !strings.HasPrefix(r.URL.Path, "/match") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectNoMatchingRule(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "example.com", Path: "/match"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, "/no-rule")
+}
+
+func TestExpectMostSpecificPrefixMatch(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch path := r.URL.Path; {
+		case path == "/api/v1" || strings.HasPrefix(path, "/api/v1/"):
+			echo(w, r, "infra-backend-v3")
+		case path == "/api" || strings.HasPrefix(path, "/api/"):
+			echo(w, r, "infra-backend-v2")
+		default:
+			echo(w, r, "infra-backend-v1")
+		}
+	})
+
+	prefixBackends := map[string]string{
+		"/":       "infra-backend-v1",
+		"/api":    "infra-backend-v2",
+		"/api/v1": "infra-backend-v3",
+	}
+	ExpectMostSpecificPrefixMatch(t, &roundtripper.DefaultRoundTripper{}, gwAddr, "", fakeNamespace, prefixBackends,
+		"/", "/apiv1", "/api", "/api/v2", "/api/v1", "/api/v1/users")
+}
+
+func TestExpectFirstIdenticalRuleWins(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		// Rules are evaluated in order, so the second rule matching the
+		// same path is never reached.
+		rules := []struct{ path, backend string }{
+			{"/identical", "infra-backend-v1"},
+			{"/identical", "infra-backend-v2"},
+		}
+		for _, rule := range rules {
+			if r.URL.Path == rule.path {
+				echo(w, r, rule.backend)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	ExpectFirstIdenticalRuleWins(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/identical"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, "infra-backend-v2")
+}
+
+func TestLongestMatchingPrefix(t *testing.T) {
+	prefixes := map[string]string{"/": "", "/api/": "", "/api/v1": ""}
+	for path, expected := range map[string]string{
+		"/":         "/",
+		"/apiv1":    "/",
+		"/api":      "/api/",
+		"/api/v10":  "/api/",
+		"/api/v1/x": "/api/v1",
+	} {
+		if got, _ := longestMatchingPrefix(prefixes, path); got != expected {
+			t.Errorf("expected %s to match %s, got %s", path, expected, got)
+		}
+	}
+}
+
+func TestExpectQueryParamRouting(t *testing.T) {
+	// The fake Gateway has a rule with an exact match on the animal query
+	// param and one with a regex match on the color one. Only the first value
+	// of a repeated param is matched.
+	color := regexp.MustCompile("^b.*e$")
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case query.Get("animal") == "whale":
+			echo(w, r, "infra-backend-v1")
+		case color.MatchString(query.Get("color")):
+			echo(w, r, "infra-backend-v2")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ExpectQueryParamRouting(t, &roundtripper.DefaultRoundTripper{}, gwAddr, []ExpectedResponse{{
+		Request:   ExpectedRequest{Path: "/?animal=whale"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, {
+		Request:   ExpectedRequest{Path: "/?color=blue"},
+		Backend:   "infra-backend-v2",
+		Namespace: fakeNamespace,
+	}, {
+		Request:   ExpectedRequest{Path: "/?animal=whale&animal=dolphin"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}, {
+		Request:    ExpectedRequest{Path: "/?animal=dolphin&animal=whale"},
+		StatusCode: http.StatusNotFound,
+	}, {
+		Request:    ExpectedRequest{Path: "/"},
+		StatusCode: http.StatusNotFound,
+	}})
+}
+
+func TestExpectNotServed(t *testing.T) {
+	// The only route matching /invalid was rejected, so nothing serves it.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/invalid" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectNotServed(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/invalid"})
+}
+
+func TestExpectServiceUnavailable(t *testing.T) {
+	// The route to the Service without endpoints matches /no-endpoints.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/no-endpoints" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	ExpectServiceUnavailable(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/no-endpoints"})
+}
+
+func TestExpectMethodRouting(t *testing.T) {
+	// The route has a rule matching PATCH and a rule without method match
+	// for /any, which is the only one extension methods can reach.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch:
+			echo(w, r, "infra-backend-v1")
+		case r.URL.Path == "/any":
+			echo(w, r, "infra-backend-v2")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ExpectMethodRouting(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/method"}, fakeNamespace, map[string]string{
+		http.MethodPatch: "infra-backend-v1",
+		"PURGE":          "",
+	})
+	ExpectMethodRouting(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/any"}, fakeNamespace, map[string]string{
+		http.MethodPatch: "infra-backend-v1",
+		"PURGE":          "infra-backend-v2",
+	})
+}
+
+func TestExpectContentTypeRouting(t *testing.T) {
+	// Header matches on Content-Type compare the full value, parameters
+	// included.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Content-Type") {
+		case "application/json":
+			echo(w, r, "infra-backend-v1")
+		case "application/json; charset=utf-8":
+			echo(w, r, "infra-backend-v2")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ExpectContentTypeRouting(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/upload"}, fakeNamespace, map[string]string{
+		"application/json":                "infra-backend-v1",
+		"application/json; charset=utf-8": "infra-backend-v2",
+		"text/plain":                      "",
+	})
+}
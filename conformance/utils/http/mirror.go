@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// MirrorIDHeader is the header ExpectMirroredRequests sets to a value unique
+// to each call on the requests it sends, so that the mirror backend only
+// counts those requests.
+const MirrorIDHeader = "X-Conformance-Mirror-Id"
+
+// mirrorPollInterval is how long ExpectMirroredRequests waits between two
+// counts of the mirror backend.
+var mirrorPollInterval = time.Second
+
+// MirrorCounter returns how many requests the mirror backend of a route
+// received carrying all of the provided headers.
+type MirrorCounter func(headers map[string]string) (int, error)
+
+// EchoCountEndpoint returns a MirrorCounter querying the count endpoint of
+// the backend at mirrorAddr: a GET of /count with a header=<name>:<value>
+// query parameter for each header to match, responding with a JSON object
+// whose count field is the number of matching requests the backend received.
+// The response body is read through CaptureRoundTripTrace, so r must be a
+// roundtripper.TracingRoundTripper.
+func EchoCountEndpoint(r roundtripper.RoundTripper, mirrorAddr string) MirrorCounter {
+	return func(headers map[string]string) (int, error) {
+		tracingRT, ok := r.(roundtripper.TracingRoundTripper)
+		if !ok {
+			return 0, fmt.Errorf("%T can't read the response of the count endpoint, it doesn't implement TracingRoundTripper", r)
+		}
+
+		query := url.Values{}
+		for name, value := range headers {
+			query.Add("header", name+":"+value)
+		}
+		req := roundtripper.Request{
+			Method:   "GET",
+			URL:      url.URL{Scheme: "http", Host: mirrorAddr, Path: "/count", RawQuery: query.Encode()},
+			Protocol: "HTTP",
+		}
+		trace := tracingRT.CaptureRoundTripTrace(req)
+		if trace.Err != nil {
+			return 0, trace.Err
+		}
+		if trace.CapturedResponse.StatusCode != 200 {
+			return 0, fmt.Errorf("expected status 200 from the count endpoint of %s, got %d", mirrorAddr, trace.CapturedResponse.StatusCode)
+		}
+		var count struct {
+			Count *int `json:"count"`
+		}
+		if err := json.Unmarshal([]byte(trace.BodySnippet), &count); err != nil || count.Count == nil {
+			return 0, fmt.Errorf("unexpected response from the count endpoint of %s: %q", mirrorAddr, trace.BodySnippet)
+		}
+		return *count.Count, nil
+	}
+}
+
+// ExpectMirroredRequests sends requests requests through the Gateway at
+// gwAddr, each of which must get the expected response from the primary
+// backend, then polls counter until the mirror backend received as many,
+// carrying the headers of expected.Request. The requests are marked with
+// MirrorIDHeader, which the mirror must receive as well. The test fails with
+// both counts if the mirror didn't receive all of them once timeout elapses,
+// or as soon as it received more.
+func ExpectMirroredRequests(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, requests int, counter MirrorCounter, timeout time.Duration) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+	headers := map[string]string{}
+	for name, value := range expected.Request.Headers {
+		headers[name] = value
+	}
+	headers[MirrorIDHeader] = t.Name() + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	expected.Request.Headers = headers
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+
+	for i := 0; i < requests; i++ {
+		cReq, cRes, err := r.CaptureRoundTrip(req)
+		require.NoErrorf(t, err, "error sending request %d of %d through the mirrored route", i+1, requests)
+		require.NoErrorf(t, CompareResponse(cReq, cRes, expected), "request %d of %d wasn't served by the primary backend", i+1, requests)
+	}
+
+	deadline := time.Now().Add(timeout)
+	last := 0
+	for {
+		mirrored, err := counter(headers)
+		if err == nil {
+			last = mirrored
+		}
+		switch {
+		case err != nil:
+			t.Logf("Error counting the requests received by the mirror backend: %v", err)
+		case mirrored == requests:
+			t.Logf("Mirror backend received the %d requests the primary backend served", requests)
+			return
+		case mirrored > requests:
+			t.Fatalf("mirror backend received %d requests, but the primary backend served %d", mirrored, requests)
+		default:
+			t.Logf("Mirror backend received %d of the %d requests the primary backend served so far", mirrored, requests)
+		}
+
+		if time.Now().Add(mirrorPollInterval).After(deadline) {
+			t.Fatalf("mirror backend received %d requests within %s, but the primary backend served %d", last, timeout, requests)
+		}
+		time.Sleep(mirrorPollInterval)
+	}
+}
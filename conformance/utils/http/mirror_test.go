@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeMirror is a mirror backend counting the requests it receives, with a
+// count endpoint as expected by EchoCountEndpoint.
+type fakeMirror struct {
+	mu       sync.Mutex
+	received []http.Header
+}
+
+func (m *fakeMirror) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.URL.Path != "/count" {
+		m.received = append(m.received, r.Header.Clone())
+		return
+	}
+	count := 0
+	for _, headers := range m.received {
+		matches := true
+		for _, header := range r.URL.Query()["header"] {
+			name, value, _ := strings.Cut(header, ":")
+			matches = matches && headers.Get(name) == value
+		}
+		if matches {
+			count++
+		}
+	}
+	w.Header().Set("Content-type", "application/json")
+	fmt.Fprintf(w, `{"count": %d}`, count)
+}
+
+func TestExpectMirroredRequests(t *testing.T) {
+	defer func(interval time.Duration) { mirrorPollInterval = interval }(mirrorPollInterval)
+	mirrorPollInterval = 10 * time.Millisecond
+
+	mirror := httptest.NewServer(&fakeMirror{})
+	t.Cleanup(mirror.Close)
+	mirrorAddr := mirror.Listener.Addr().String()
+
+	// The fake Gateway mirrors requests asynchronously, as Gateways may.
+	var wg sync.WaitGroup
+	t.Cleanup(wg.Wait)
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		mirrored, err := http.NewRequest(r.Method, "http://"+mirrorAddr+r.URL.Path, nil)
+		require.NoError(t, err)
+		mirrored.Header = r.Header.Clone()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(50 * time.Millisecond)
+			if res, err := http.DefaultClient.Do(mirrored); err == nil {
+				res.Body.Close()
+			}
+		}()
+		echo(w, r, "infra-backend-v1")
+	})
+
+	rt := &roundtripper.DefaultRoundTripper{}
+	expected := ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/mirror", Headers: map[string]string{"X-Echo": "mirrored"}},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}
+	counter := EchoCountEndpoint(rt, mirrorAddr)
+	ExpectMirroredRequests(t, rt, gwAddr, expected, 5, counter, 5*time.Second)
+
+	// Requests of another call aren't counted.
+	ExpectMirroredRequests(t, rt, gwAddr, expected, 2, counter, 5*time.Second)
+
+	count, err := counter(map[string]string{"X-Echo": "mirrored"})
+	require.NoError(t, err)
+	require.Equal(t, 7, count)
+}
+
+func TestEchoCountEndpointErrors(t *testing.T) {
+	addr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("header") == "X-Status:503" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"requests": 1}`)
+	})
+	counter := EchoCountEndpoint(&roundtripper.DefaultRoundTripper{}, addr)
+
+	_, err := counter(map[string]string{"X-Status": "503"})
+	require.EqualError(t, err, fmt.Sprintf("expected status 200 from the count endpoint of %s, got 503", addr))
+	_, err = counter(nil)
+	require.EqualError(t, err, fmt.Sprintf(`unexpected response from the count endpoint of %s: "{\"requests\": 1}"`, addr))
+
+	_, err = EchoCountEndpoint(basicRoundTripper{}, addr)(nil)
+	require.Error(t, err, "expected RoundTrippers unable to read bodies to be rejected")
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectOptionsResponder sends the request with the OPTIONS method and
+// verifies who answered it, understanding that the request may fail for some
+// amount of time. If gatewayHandled is true, the Gateway must have responded
+// itself with a 2xx status, without the request reaching a backend, as
+// Gateways implementing CORS may do. Otherwise the request must have been
+// forwarded to the provided backend, which must have observed the OPTIONS
+// method.
+func ExpectOptionsResponder(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, backend string, gatewayHandled bool) {
+	t.Helper()
+
+	req.Method = "OPTIONS"
+	rtReq := toRoundTripperRequest(gwAddr, req)
+
+	var cReq *roundtripper.CapturedRequest
+	require.Eventually(t, func() bool {
+		var cRes *roundtripper.CapturedResponse
+		var err error
+		cReq, cRes, err = r.CaptureRoundTrip(rtReq)
+		if err != nil {
+			t.Logf("Request failed, not ready yet: %v", err.Error())
+			return false
+		}
+		if cRes.StatusCode < 200 || cRes.StatusCode >= 300 {
+			t.Logf("Expected OPTIONS response to have a 2xx status, got %d, not ready yet", cRes.StatusCode)
+			return false
+		}
+		return true
+	}, maxTimeToConsistency, 1*time.Second, "error making OPTIONS request, never got a 2xx response")
+
+	if gatewayHandled {
+		require.Emptyf(t, cReq.Pod, "expected the Gateway to respond to OPTIONS itself, but it was forwarded to %s", cReq.Pod)
+		return
+	}
+	require.Truef(t, strings.HasPrefix(cReq.Pod, backend), "expected OPTIONS request to be forwarded to %s, got pod %q", backend, cReq.Pod)
+	require.Equalf(t, "OPTIONS", cReq.Method, "expected backend to observe the OPTIONS method, got %s", cReq.Method)
+}
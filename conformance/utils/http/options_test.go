@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectOptionsResponder(t *testing.T) {
+	req := ExpectedRequest{Path: "/"}
+
+	t.Run("gateway handled", func(t *testing.T) {
+		// The fake Gateway answers preflight requests itself.
+		gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Allow", "GET, OPTIONS")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			echo(w, r, "infra-backend-v1")
+		})
+		ExpectOptionsResponder(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, "infra-backend-v1", true)
+	})
+
+	t.Run("backend forwarded", func(t *testing.T) {
+		gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			echo(w, r, "infra-backend-v1")
+		})
+		ExpectOptionsResponder(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, "infra-backend-v1", false)
+	})
+}
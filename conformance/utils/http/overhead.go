@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// defaultOverheadSamples is the number of requests MeasureGatewayOverhead
+// times through each path when no sample count is provided.
+const defaultOverheadSamples = 20
+
+// overheadPercentiles are the percentiles MeasureGatewayOverhead logs.
+var overheadPercentiles = []float64{50, 90, 99}
+
+// Latencies holds the durations of timed requests, sorted in increasing order.
+type Latencies []time.Duration
+
+// newLatencies returns the provided durations as sorted Latencies.
+func newLatencies(durations []time.Duration) Latencies {
+	latencies := make(Latencies, len(durations))
+	copy(latencies, durations)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return latencies
+}
+
+// Percentile returns the nearest-rank p-th percentile of the latencies, with
+// p between 0 and 100, or 0 if there are none.
+func (l Latencies) Percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(l))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(l) {
+		rank = len(l)
+	}
+	return l[rank-1]
+}
+
+// Overhead holds the latencies of the same request made through the Gateway
+// and directly to the backend.
+type Overhead struct {
+	Gateway Latencies
+	// Direct is empty if the backend couldn't be reached directly.
+	Direct Latencies
+}
+
+// Delta returns the latency the Gateway adds at the p-th percentile, i.e. the
+// difference between the percentiles of both paths. It returns false if the
+// backend couldn't be reached directly.
+func (o Overhead) Delta(p float64) (time.Duration, bool) {
+	if len(o.Direct) == 0 {
+		return 0, false
+	}
+	return o.Gateway.Percentile(p) - o.Direct.Percentile(p), true
+}
+
+// MeasureGatewayOverhead times samples requests through the Gateway and, if
+// backendAddr is not empty, as many directly to the backend, then logs the
+// delta the Gateway adds at the 50th, 90th and 99th percentiles. samples
+// defaults to 20 if zero. Requests through the Gateway must succeed with a 200
+// and the route must already be programmed; if the first direct request
+// fails, the backend is considered unreachable and only Gateway latencies are
+// reported.
+func MeasureGatewayOverhead(t *testing.T, r roundtripper.RoundTripper, gwAddr, backendAddr string, req ExpectedRequest, samples int) Overhead {
+	t.Helper()
+
+	if samples == 0 {
+		samples = defaultOverheadSamples
+	}
+
+	overhead := Overhead{}
+	gwDurations, err := timeRequests(r, toRoundTripperRequest(gwAddr, req), samples)
+	require.NoErrorf(t, err, "error timing requests through the Gateway at %s", gwAddr)
+	overhead.Gateway = newLatencies(gwDurations)
+
+	if backendAddr != "" {
+		directDurations, err := timeRequests(r, toRoundTripperRequest(backendAddr, req), samples)
+		if err != nil {
+			t.Logf("Backend at %s is not reachable directly, only reporting Gateway latencies: %v", backendAddr, err)
+		} else {
+			overhead.Direct = newLatencies(directDurations)
+		}
+	}
+
+	for _, p := range overheadPercentiles {
+		if delta, ok := overhead.Delta(p); ok {
+			t.Logf("p%g: %s through the Gateway, %s direct, %s overhead", p, overhead.Gateway.Percentile(p), overhead.Direct.Percentile(p), delta)
+		} else {
+			t.Logf("p%g: %s through the Gateway", p, overhead.Gateway.Percentile(p))
+		}
+	}
+	return overhead
+}
+
+// timeRequests makes the request samples times, returning how long each took.
+// Every request must succeed with a 200.
+func timeRequests(r roundtripper.RoundTripper, req roundtripper.Request, samples int) ([]time.Duration, error) {
+	durations := make([]time.Duration, 0, samples)
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		_, cRes, err := r.CaptureRoundTrip(req)
+		elapsed := time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+		if cRes.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("expected status %d, got %d", http.StatusOK, cRes.StatusCode)
+		}
+		durations = append(durations, elapsed)
+	}
+	return durations, nil
+}
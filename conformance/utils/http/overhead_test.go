@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestLatenciesPercentile(t *testing.T) {
+	ms := time.Millisecond
+	latencies := newLatencies([]time.Duration{5 * ms, 1 * ms, 4 * ms, 2 * ms, 3 * ms, 10 * ms, 9 * ms, 8 * ms, 7 * ms, 6 * ms})
+
+	testCases := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{p: 0, expected: 1 * ms},
+		{p: 10, expected: 1 * ms},
+		{p: 50, expected: 5 * ms},
+		{p: 55, expected: 6 * ms},
+		{p: 90, expected: 9 * ms},
+		{p: 99, expected: 10 * ms},
+		{p: 100, expected: 10 * ms},
+	}
+	for _, tc := range testCases {
+		require.Equalf(t, tc.expected, latencies.Percentile(tc.p), "unexpected p%g", tc.p)
+	}
+	require.Zero(t, Latencies(nil).Percentile(50))
+}
+
+func TestOverheadDelta(t *testing.T) {
+	ms := time.Millisecond
+	overhead := Overhead{
+		Gateway: newLatencies([]time.Duration{12 * ms, 11 * ms, 30 * ms, 13 * ms}),
+		Direct:  newLatencies([]time.Duration{2 * ms, 1 * ms, 3 * ms, 10 * ms}),
+	}
+
+	delta, ok := overhead.Delta(50)
+	require.True(t, ok)
+	require.Equal(t, 10*ms, delta)
+	delta, ok = overhead.Delta(99)
+	require.True(t, ok)
+	require.Equal(t, 20*ms, delta)
+
+	_, ok = Overhead{Gateway: overhead.Gateway}.Delta(50)
+	require.False(t, ok, "expected no delta without direct latencies")
+}
+
+func TestMeasureGatewayOverhead(t *testing.T) {
+	backendAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+	// The fake Gateway adds a fixed delay to every request it proxies.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		echo(w, r, "infra-backend-v1")
+	})
+	r := &roundtripper.DefaultRoundTripper{}
+	req := ExpectedRequest{Path: "/"}
+
+	t.Run("direct backend", func(t *testing.T) {
+		overhead := MeasureGatewayOverhead(t, r, gwAddr, backendAddr, req, 4)
+		require.Len(t, overhead.Gateway, 4)
+		require.Len(t, overhead.Direct, 4)
+		delta, ok := overhead.Delta(50)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, delta, 15*time.Millisecond, "expected delta to account for the delay of the Gateway")
+	})
+
+	t.Run("unreachable backend", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		unreachable := listener.Addr().String()
+		require.NoError(t, listener.Close())
+
+		overhead := MeasureGatewayOverhead(t, r, gwAddr, unreachable, req, 2)
+		require.Len(t, overhead.Gateway, 2)
+		require.Empty(t, overhead.Direct)
+	})
+}
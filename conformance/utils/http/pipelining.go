@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// pipelinedRoundTripper returns the PipelinedRoundTripper implementation of
+// r, failing the test if r doesn't support pipelined round trips.
+func pipelinedRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.PipelinedRoundTripper {
+	t.Helper()
+
+	pipelinedRT, ok := r.(roundtripper.PipelinedRoundTripper)
+	require.Truef(t, ok, "%T does not support pipelined round trips", r)
+	return pipelinedRT
+}
+
+// ExpectPipelinedResponses pipelines the expected requests on a single
+// HTTP/1.1 connection and verifies the Gateway answers them in order, each
+// with its expected response. A Gateway not supporting pipelining may close
+// the connection early instead, in which case the responses it sent before
+// must still be the expected ones. The first expected response is awaited
+// beforehand so that the routes are known to be programmed.
+func ExpectPipelinedResponses(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected []ExpectedResponse) {
+	t.Helper()
+
+	require.NotEmpty(t, expected, "expected at least one request to pipeline")
+	for i := range expected {
+		if expected[i].Request.Method == "" {
+			expected[i].Request.Method = "GET"
+		}
+		if expected[i].StatusCode == 0 {
+			expected[i].StatusCode = 200
+		}
+	}
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected[0])
+
+	requests := make([][]byte, len(expected))
+	for i, tc := range expected {
+		requests[i] = buildKeepAliveRawRequest(gwAddr, tc.Request, nil)
+	}
+	// The last request closes the connection once every response is sent.
+	requests[len(requests)-1] = BuildRawRequest(gwAddr, expected[len(expected)-1].Request, nil, nil)
+
+	t.Logf("Making %d pipelined requests to %s", len(requests), gwAddr)
+	cReqs, cRess, err := pipelinedRoundTripper(t, r).CapturePipelinedRoundTrip(roundtripper.PipelinedRequest{
+		Address:  gwAddr,
+		Requests: requests,
+	})
+	require.NoErrorf(t, err, "error making pipelined requests")
+
+	if len(cRess) < len(expected) {
+		t.Logf("Gateway closed the connection after %d of %d pipelined requests", len(cRess), len(expected))
+	}
+	for i := range cRess {
+		ExpectResponse(t, cReqs[i], cRess[i], expected[i])
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectPipelinedResponses(t *testing.T) {
+	// The fake Gateway answers pipelined requests in order, the first one
+	// being the slowest to make out of order responses observable.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		echo(w, r, "infra-backend-"+strings.TrimPrefix(r.URL.Path, "/"))
+	})
+
+	ExpectPipelinedResponses(t, &roundtripper.DefaultRoundTripper{}, gwAddr, []ExpectedResponse{{
+		Request:   ExpectedRequest{Path: "/slow"},
+		Backend:   "infra-backend-slow",
+		Namespace: fakeNamespace,
+	}, {
+		Request:   ExpectedRequest{Path: "/v2"},
+		Backend:   "infra-backend-v2",
+		Namespace: fakeNamespace,
+	}, {
+		Request:   ExpectedRequest{Path: "/v3"},
+		Backend:   "infra-backend-v3",
+		Namespace: fakeNamespace,
+	}})
+}
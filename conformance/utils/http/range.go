@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectRangeResponsePassedThrough sends the expected request with the
+// provided Range header, e.g. "bytes=0-9", to a range-capable backend and
+// verifies that the Gateway passes its 206 Partial Content response through
+// with the expected Content-Range header, e.g. "bytes 0-9/100".
+func ExpectRangeResponsePassedThrough(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, byteRange, contentRange string) {
+	t.Helper()
+
+	rtReq := toRoundTripperRequest(gwAddr, req)
+	if rtReq.Headers == nil {
+		rtReq.Headers = map[string][]string{}
+	}
+	rtReq.Headers["Range"] = []string{byteRange}
+
+	t.Logf("Making request with Range %s to %s", byteRange, gwAddr)
+	_, cRes := WaitForConsistency(t, r, rtReq, ExpectedResponse{Request: req, StatusCode: http.StatusPartialContent}, requiredConsecutiveSuccesses)
+	require.Equalf(t, []string{contentRange}, http.Header(cRes.Headers).Values("Content-Range"), "expected Content-Range of the backend response to be preserved")
+}
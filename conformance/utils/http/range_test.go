@@ -0,0 +1,36 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectRangeResponsePassedThrough(t *testing.T) {
+	content := bytes.NewReader([]byte(strings.Repeat("0123456789", 10)))
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "content", time.Time{}, content)
+	})
+
+	ExpectRangeResponsePassedThrough(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/content"}, "bytes=10-19", "bytes 10-19/100")
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// BuildRawRequest serializes the expected request as an HTTP/1.1 request
+// intended for gwAddr, followed by the provided raw header lines (without
+// trailing CRLF) and body. Nothing is validated or escaped.
+func BuildRawRequest(gwAddr string, req ExpectedRequest, rawHeaders [][]byte, body []byte) []byte {
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+	host := req.Host
+	if host == "" {
+		host = gwAddr
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\nHost: %s\r\n", method, req.Path, host)
+
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, req.Headers[name])
+	}
+	for _, header := range rawHeaders {
+		buf.Write(header)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("Connection: close\r\n\r\n")
+	buf.Write(body)
+
+	return buf.Bytes()
+}
+
+// buildKeepAliveRawRequest serializes the request like BuildRawRequest, but
+// without a body and without asking for the connection to be closed.
+func buildKeepAliveRawRequest(gwAddr string, req ExpectedRequest, rawHeaders [][]byte) []byte {
+	data := BuildRawRequest(gwAddr, req, rawHeaders, nil)
+	data = bytes.TrimSuffix(data, []byte("Connection: close\r\n\r\n"))
+	return append(data, "\r\n"...)
+}
+
+// rawRoundTripper returns the RawRoundTripper implementation of r, failing
+// the test if r doesn't support raw round trips.
+func rawRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.RawRoundTripper {
+	t.Helper()
+
+	rawRT, ok := r.(roundtripper.RawRoundTripper)
+	require.Truef(t, ok, "%T does not support raw round trips", r)
+	return rawRT
+}
+
+// rawResponseRoundTripper returns the RawResponseRoundTripper implementation
+// of r, failing the test if r doesn't support capturing raw responses.
+func rawResponseRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.RawResponseRoundTripper {
+	t.Helper()
+
+	rawRT, ok := r.(roundtripper.RawResponseRoundTripper)
+	require.Truef(t, ok, "%T does not support capturing raw responses", r)
+	return rawRT
+}
+
+// ExpectRawHeaderValueHandled sends the expected request with an additional
+// header carrying the raw, possibly invalid, value provided. The Gateway must
+// handle it gracefully by either rejecting the request with a 400 or
+// forwarding it to the expected backend. A regular request must still get the
+// expected response afterwards, so that a Gateway crashing on such input is
+// detected.
+func ExpectRawHeaderValueHandled(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, headerName string, value []byte) {
+	t.Helper()
+
+	header := append([]byte(headerName+": "), value...)
+	data := BuildRawRequest(gwAddr, expected.Request, [][]byte{header}, nil)
+
+	t.Logf("Making raw request with %s header value %q to %s", headerName, value, gwAddr)
+	cReq, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoErrorf(t, err, "error making raw request, the Gateway must respond to invalid header values")
+
+	switch cRes.StatusCode {
+	case http.StatusBadRequest:
+		t.Logf("Request with invalid %s header value was rejected", headerName)
+	case http.StatusOK:
+		require.Truef(t, strings.HasPrefix(cReq.Pod, expected.Backend), "expected pod name to start with %s, got %s", expected.Backend, cReq.Pod)
+		t.Logf("Request with invalid %s header value was forwarded", headerName)
+	default:
+		t.Fatalf("Expected request with invalid %s header value to be rejected with %d or forwarded, got %d", headerName, http.StatusBadRequest, cRes.StatusCode)
+	}
+
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+}
+
+// ExpectAmbiguousFramingRejected sends the expected request with both a
+// Content-Length and a chunked Transfer-Encoding header, which would allow
+// request smuggling if the Gateway and backend disagreed on which one takes
+// precedence. The Gateway must reject such a request with a 400.
+func ExpectAmbiguousFramingRejected(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest) {
+	t.Helper()
+
+	if req.Method == "" {
+		req.Method = "POST"
+	}
+	data := BuildRawRequest(gwAddr, req, [][]byte{
+		[]byte("Content-Length: 5"),
+		[]byte("Transfer-Encoding: chunked"),
+	}, []byte("0\r\n\r\n"))
+
+	t.Logf("Making request with both Content-Length and Transfer-Encoding to %s", gwAddr)
+	_, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoErrorf(t, err, "error making raw request")
+	require.Equalf(t, http.StatusBadRequest, cRes.StatusCode, "expected request with ambiguous framing to be rejected with %d, got %d", http.StatusBadRequest, cRes.StatusCode)
+}
+
+// ExpectUnsupportedExpectationRejected sends the expected request with an
+// Expect header carrying the provided expectation, which the Gateway must not
+// support, e.g. "something-unsupported". The Gateway must reject such a
+// request with a 417. The request is sent raw since HTTP clients typically
+// handle the Expect header themselves.
+func ExpectUnsupportedExpectationRejected(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, expectation string) {
+	t.Helper()
+
+	data := BuildRawRequest(gwAddr, req, [][]byte{[]byte("Expect: " + expectation)}, nil)
+
+	t.Logf("Making request with Expect: %s to %s", expectation, gwAddr)
+	_, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoErrorf(t, err, "error making raw request")
+	require.Equalf(t, http.StatusExpectationFailed, cRes.StatusCode, "expected request with unsupported expectation to be rejected with %d, got %d", http.StatusExpectationFailed, cRes.StatusCode)
+}
+
+// ExpectHTTP09Rejected sends an HTTP/0.9 request for path, a bare request
+// line without version nor headers, which the Gateway must reject cleanly:
+// either by closing the connection without a response, or with a 400 or 505
+// HTTP/1.x response. Serving the request, e.g. with an HTTP/0.9 response
+// made of the body alone, fails the test.
+func ExpectHTTP09Rejected(t *testing.T, r roundtripper.RoundTripper, gwAddr, path string) {
+	t.Helper()
+
+	t.Logf("Making HTTP/0.9 request for %s to %s", path, gwAddr)
+	data, err := rawResponseRoundTripper(t, r).CaptureRawResponse(roundtripper.RawRequest{
+		Address: gwAddr,
+		Data:    []byte("GET " + path + "\r\n"),
+	})
+	require.NoErrorf(t, err, "error making HTTP/0.9 request, the Gateway must reject it and close the connection")
+	if len(data) == 0 {
+		t.Logf("HTTP/0.9 request was rejected by closing the connection")
+		return
+	}
+
+	require.Truef(t, bytes.HasPrefix(data, []byte("HTTP/1.")), "expected HTTP/0.9 request to be rejected, got a response without an HTTP/1.x status line: %q", data)
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	require.NoErrorf(t, err, "error parsing the response to the HTTP/0.9 request: %q", data)
+	resp.Body.Close()
+	require.Containsf(t, []int{http.StatusBadRequest, http.StatusHTTPVersionNotSupported}, resp.StatusCode, "expected HTTP/0.9 request to be rejected with %d or %d, got %d", http.StatusBadRequest, http.StatusHTTPVersionNotSupported, resp.StatusCode)
+}
+
+// ExpectEmptyBodyForwarded sends the expected request, defaulting to a POST,
+// with an explicit "Content-Length: 0" and verifies that the expected backend
+// received it with the same method and an empty body. The expected response
+// is awaited first so that the route is known to be programmed.
+func ExpectEmptyBodyForwarded(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "POST"
+	}
+	MakeRequestAndExpectEventuallyConsistentResponse(t, r, gwAddr, expected)
+
+	data := BuildRawRequest(gwAddr, expected.Request, [][]byte{[]byte("Content-Length: 0")}, nil)
+
+	t.Logf("Making %s request with an empty body to %s", expected.Request.Method, gwAddr)
+	cReq, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoErrorf(t, err, "error making raw request")
+	require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected request with an empty body to be forwarded, got %d", cRes.StatusCode)
+	require.Equalf(t, expected.Request.Method, cReq.Method, "expected method to be %s, got %s", expected.Request.Method, cReq.Method)
+	require.Truef(t, strings.HasPrefix(cReq.Pod, expected.Backend), "expected pod name to start with %s, got %s", expected.Backend, cReq.Pod)
+
+	for name, values := range cReq.Headers {
+		switch strings.ToLower(name) {
+		case "content-length":
+			require.Equalf(t, []string{"0"}, values, "expected backend to receive an empty body")
+		case "transfer-encoding":
+			t.Fatalf("Expected backend to receive an empty body without Transfer-Encoding, got %v", values)
+		}
+	}
+}
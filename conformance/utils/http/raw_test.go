@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestBuildRawRequest(t *testing.T) {
+	data := BuildRawRequest("1.2.3.4:80", ExpectedRequest{
+		Path:    "/raw",
+		Headers: map[string]string{"B": "2", "A": "1"},
+	}, [][]byte{[]byte("X-Raw: \xff\x01")}, []byte("body"))
+
+	require.Equal(t, "GET /raw HTTP/1.1\r\nHost: 1.2.3.4:80\r\nA: 1\r\nB: 2\r\nX-Raw: \xff\x01\r\nConnection: close\r\n\r\nbody", string(data))
+}
+
+func TestExpectRawHeaderValueHandled(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+	expected := ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}
+	r := &roundtripper.DefaultRoundTripper{}
+
+	t.Run("control characters are rejected", func(t *testing.T) {
+		ExpectRawHeaderValueHandled(t, r, gwAddr, expected, "X-Invalid", []byte("a\x01b"))
+	})
+
+	t.Run("invalid UTF-8 is forwarded", func(t *testing.T) {
+		ExpectRawHeaderValueHandled(t, r, gwAddr, expected, "X-Invalid", []byte{0xff, 0xfe})
+	})
+}
+
+func TestExpectAmbiguousFramingRejected(t *testing.T) {
+	// The fake Gateway rejects any request declaring both framings.
+	gwAddr, heads := fakeRawGateway(t, func(head []byte) int {
+		lower := strings.ToLower(string(head))
+		if strings.Contains(lower, "\r\ncontent-length:") && strings.Contains(lower, "\r\ntransfer-encoding:") {
+			return http.StatusBadRequest
+		}
+		return http.StatusOK
+	})
+
+	ExpectAmbiguousFramingRejected(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/smuggle"})
+
+	// The crafted request must reach the server as constructed for the
+	// assertion above to be meaningful.
+	head := string(<-heads)
+	require.Equal(t, "POST /smuggle HTTP/1.1\r\nHost: "+gwAddr+"\r\nContent-Length: 5\r\nTransfer-Encoding: chunked\r\nConnection: close\r\n\r\n", head)
+}
+
+func TestExpectUnsupportedExpectationRejected(t *testing.T) {
+	// Go HTTP servers reject any expectation other than 100-continue with a
+	// 417 before calling the handler.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectUnsupportedExpectationRejected(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/"}, "something-unsupported")
+}
+
+// fakeHTTP09Gateway starts a TCP server standing in for a Gateway receiving
+// HTTP/0.9 requests. For every connection, the request line is read and the
+// provided response, possibly empty, is written before closing it.
+func fakeHTTP09Gateway(t *testing.T, response string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if _, err := bufio.NewReader(conn).ReadBytes('\n'); err == nil {
+					conn.Write([]byte(response))
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestExpectHTTP09Rejected(t *testing.T) {
+	r := &roundtripper.DefaultRoundTripper{}
+
+	t.Run("rejected with a status", func(t *testing.T) {
+		gwAddr := fakeHTTP09Gateway(t, "HTTP/1.1 400 Bad Request\r\nContent-Length: 0\r\nConnection: close\r\n\r\n")
+		ExpectHTTP09Rejected(t, r, gwAddr, "/")
+	})
+
+	t.Run("rejected by closing the connection", func(t *testing.T) {
+		gwAddr := fakeHTTP09Gateway(t, "")
+		ExpectHTTP09Rejected(t, r, gwAddr, "/")
+	})
+}
+
+func TestExpectEmptyBodyForwarded(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectEmptyBodyForwarded(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Method: "POST", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	})
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectRedirectMethodSemantics verifies that a request redirected by the
+// Gateway gets the configured redirect status code, and that for 307 and 308
+// redirects, which must preserve the request method, the backend reached by
+// following the redirect observes the original method. Clients may change the
+// method when following 301 and 302 redirects, so it is not verified for
+// those.
+func ExpectRedirectMethodSemantics(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, statusCode int) {
+	t.Helper()
+
+	if req.Method == "" {
+		req.Method = "POST"
+	}
+
+	// Raw round trips are never redirected, so the status code configured on
+	// the redirect is observed as is.
+	data := BuildRawRequest(gwAddr, req, nil, nil)
+	_, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoError(t, err, "error making request")
+	require.Equalf(t, statusCode, cRes.StatusCode, "expected redirect status code %d, got %d", statusCode, cRes.StatusCode)
+	require.NotEmpty(t, cRes.Headers["Location"], "expected redirect response to have a Location header")
+
+	if statusCode != http.StatusTemporaryRedirect && statusCode != http.StatusPermanentRedirect {
+		return
+	}
+
+	rtReq := toRoundTripperRequest(gwAddr, req)
+	rtReq.FollowRedirects = true
+	cReq, cRes, err := r.CaptureRoundTrip(rtReq)
+	require.NoError(t, err, "error making request following the redirect")
+	require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected redirect target to respond with %d, got %d", http.StatusOK, cRes.StatusCode)
+	require.Equalf(t, req.Method, cReq.Method, "expected %d redirect to preserve the %s method", statusCode, req.Method)
+}
+
+// ExpectBackendRedirectPassedThrough verifies that a redirect returned by the
+// backend, rather than configured on the Gateway, reaches the client
+// unchanged: the status code must be the one returned by the backend and the
+// Location header must be preserved as is.
+func ExpectBackendRedirectPassedThrough(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, statusCode int, location string) {
+	t.Helper()
+
+	// Raw round trips are never redirected, so the redirect is observed as
+	// returned by the Gateway.
+	data := BuildRawRequest(gwAddr, req, nil, nil)
+	_, cRes, err := rawRoundTripper(t, r).CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+	require.NoError(t, err, "error making request")
+	require.Equalf(t, statusCode, cRes.StatusCode, "expected backend redirect status code %d, got %d", statusCode, cRes.StatusCode)
+	require.Equalf(t, []string{location}, cRes.Headers["Location"], "expected Location header of the backend redirect to be preserved")
+}
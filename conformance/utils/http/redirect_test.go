@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectRedirectMethodSemantics(t *testing.T) {
+	// The fake Gateway redirects /redirect/<code> to /target with the given
+	// status code.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if code, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/")); err == nil {
+			http.Redirect(w, r, "/target", code)
+			return
+		}
+		echo(w, r, "infra-backend-v1")
+	})
+
+	for _, code := range []int{301, 302, 307, 308} {
+		code := code
+		t.Run(strconv.Itoa(code), func(t *testing.T) {
+			req := ExpectedRequest{Method: "POST", Path: "/redirect/" + strconv.Itoa(code)}
+			ExpectRedirectMethodSemantics(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, code)
+		})
+	}
+}
+
+func TestExpectBackendRedirectPassedThrough(t *testing.T) {
+	// The fake Gateway forwards every request to a backend redirecting to
+	// another host.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "http://other.example.com/moved?from=backend")
+		w.WriteHeader(http.StatusFound)
+	})
+
+	ExpectBackendRedirectPassedThrough(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/old"},
+		http.StatusFound, "http://other.example.com/moved?from=backend")
+}
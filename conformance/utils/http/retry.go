@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// MakeRequestWithRetry makes a request with the given parameters, retrying
+// with backoff while the Gateway responds transiently as configured, and
+// then asserts the response matches the expected one. Unlike
+// MakeRequestAndExpectEventuallyConsistentResponse, a response that isn't
+// transient fails the test straight away, as does exhausting the budget.
+func MakeRequestWithRetry(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, config roundtripper.RetryConfig) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	t.Logf("Making %s request to http://%s%s with retries", expected.Request.Method, gwAddr, expected.Request.Path)
+
+	cReq, cRes, err := roundtripper.CaptureRoundTripWithRetry(r, toRoundTripperRequest(gwAddr, expected.Request), expected.StatusCode, config)
+	require.NoError(t, err, "error making request to %s", gwAddr)
+	ExpectResponse(t, cReq, cRes, expected)
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestMakeRequestWithRetry(t *testing.T) {
+	var requests int32
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case atomic.AddInt32(&requests, 1) <= 2:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			echo(w, r, "infra-backend-v1")
+		}
+	})
+	r := &roundtripper.DefaultRoundTripper{}
+	config := roundtripper.RetryConfig{Budget: time.Second, InitialBackoff: 10 * time.Millisecond}
+
+	t.Run("transient responses are retried", func(t *testing.T) {
+		MakeRequestWithRetry(t, r, gwAddr, ExpectedResponse{
+			Request:   ExpectedRequest{Path: "/"},
+			Backend:   "infra-backend-v1",
+			Namespace: fakeNamespace,
+		}, config)
+		require.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("a wrong response fails promptly", func(t *testing.T) {
+		start := time.Now()
+		_, cRes, err := roundtripper.CaptureRoundTripWithRetry(r, toRoundTripperRequest(gwAddr, ExpectedRequest{Path: "/broken"}), http.StatusOK, config)
+		require.Error(t, err)
+		require.Equal(t, http.StatusInternalServerError, cRes.StatusCode)
+		require.Less(t, int64(time.Since(start)), int64(config.Budget))
+	})
+}
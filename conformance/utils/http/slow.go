@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// Trickle configures how slowly a backend sends the body of its response once
+// its headers are sent: in Chunks chunks, Interval apart. It is passed to the
+// backend through the chunks and interval query parameters.
+type Trickle struct {
+	Chunks   int
+	Interval time.Duration
+}
+
+// Duration returns how long the backend takes to send the whole body.
+func (tr Trickle) Duration() time.Duration {
+	return time.Duration(tr.Chunks) * tr.Interval
+}
+
+// query returns the query parameters passing the trickle to the backend.
+func (tr Trickle) query() url.Values {
+	return url.Values{
+		"chunks":   []string{strconv.Itoa(tr.Chunks)},
+		"interval": []string{tr.Interval.String()},
+	}
+}
+
+// request converts an ExpectedRequest into the request sent to the Gateway at
+// gwAddr, asking the backend to trickle its response body.
+func (tr Trickle) request(gwAddr string, req ExpectedRequest) roundtripper.Request {
+	rtReq := toRoundTripperRequest(gwAddr, req)
+	query := rtReq.URL.Query()
+	for name, values := range tr.query() {
+		query[name] = values
+	}
+	rtReq.URL.RawQuery = query.Encode()
+	return rtReq
+}
+
+// ExpectSlowBodyHandled makes the request, which must be routed to a backend
+// sending its headers immediately and its body as configured by trickle, and
+// verifies the Gateway enforces its response timeout on the body. If the
+// trickle completes within timeout, the whole response must be passed
+// through. Otherwise the Gateway must end the response once timeout elapses,
+// either with a 504 or by cutting the body short, rather than waiting for the
+// backend to finish. The route must already be programmed.
+func ExpectSlowBodyHandled(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, trickle Trickle, timeout time.Duration) {
+	t.Helper()
+
+	rtReq := trickle.request(gwAddr, req)
+
+	t.Logf("Making request for a body trickled over %s to %s", trickle.Duration(), gwAddr)
+	start := time.Now()
+	_, cRes, err := r.CaptureRoundTrip(rtReq)
+	elapsed := time.Since(start)
+
+	if trickle.Duration() < timeout {
+		require.NoErrorf(t, err, "expected body trickled within the %s timeout to be passed through", timeout)
+		require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected body trickled within the %s timeout to be passed through, got %d", timeout, cRes.StatusCode)
+		return
+	}
+
+	switch {
+	case err != nil:
+		t.Logf("Slow body was cut short after %s: %v", elapsed, err)
+	case cRes.StatusCode == http.StatusGatewayTimeout:
+		t.Logf("Slow body was rejected with %d after %s", cRes.StatusCode, elapsed)
+	default:
+		t.Fatalf("Expected body trickled over %s to exceed the %s timeout, got a complete response with status %d", trickle.Duration(), timeout, cRes.StatusCode)
+	}
+	require.Lessf(t, int64(elapsed), int64(trickle.Duration()), "expected the Gateway to enforce its %s timeout rather than waiting %s for the backend", timeout, elapsed)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectSlowBodyHandled(t *testing.T) {
+	const timeout = 500 * time.Millisecond
+
+	// The fake Gateway forwards to a backend trickling its body as requested
+	// and aborts responses once its timeout elapses.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		chunks, _ := strconv.Atoi(r.URL.Query().Get("chunks"))
+		interval, _ := time.ParseDuration(r.URL.Query().Get("interval"))
+
+		start := time.Now()
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for i := 0; i < chunks; i++ {
+			time.Sleep(interval)
+			if time.Since(start) > timeout {
+				panic(http.ErrAbortHandler)
+			}
+			_, _ = w.Write([]byte("chunk\n"))
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	req := ExpectedRequest{Path: "/slow"}
+	t.Run("pass-through", func(t *testing.T) {
+		ExpectSlowBodyHandled(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, Trickle{Chunks: 3, Interval: 50 * time.Millisecond}, timeout)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		ExpectSlowBodyHandled(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, Trickle{Chunks: 10, Interval: 200 * time.Millisecond}, timeout)
+	})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// SourceIPMode is how a Gateway propagates the IP of clients to backends.
+type SourceIPMode string
+
+const (
+	// SourceIPHeader propagates the client IP as the last entry of a request
+	// header, X-Forwarded-For by default.
+	SourceIPHeader SourceIPMode = "Header"
+	// SourceIPProxyProtocol propagates the client IP through a PROXY
+	// protocol header sent to backends, which report it as their
+	// RemoteAddr.
+	SourceIPProxyProtocol SourceIPMode = "ProxyProtocol"
+)
+
+// ExpectSourceIPPropagated makes the request and verifies the backend
+// observed the IP the request was sent from, propagated by the Gateway as
+// configured by mode. For SourceIPHeader, header names the request header
+// carrying the client IP, defaulting to X-Forwarded-For; it is ignored for
+// SourceIPProxyProtocol. The client IP is the local IP used to reach gwAddr,
+// so clients must not be behind NAT.
+func ExpectSourceIPPropagated(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse, mode SourceIPMode, header string) {
+	t.Helper()
+
+	clientIP, err := sourceIP(gwAddr)
+	require.NoErrorf(t, err, "error determining the client IP used to reach %s", gwAddr)
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	t.Logf("Making %s request from %s to http://%s%s", req.Method, clientIP, gwAddr, expected.Request.Path)
+	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
+	ExpectResponse(t, cReq, cRes, expected)
+
+	var observed string
+	switch mode {
+	case SourceIPHeader:
+		if header == "" {
+			header = "X-Forwarded-For"
+		}
+		got := http.Header{}
+		for name, values := range cReq.Headers {
+			got[http.CanonicalHeaderKey(name)] = values
+		}
+		entries := strings.Split(strings.Join(got.Values(header), ","), ",")
+		observed = strings.TrimSpace(entries[len(entries)-1])
+	case SourceIPProxyProtocol:
+		require.NotEmpty(t, cReq.RemoteAddr, "expected the backend to report its RemoteAddr")
+		host, _, err := net.SplitHostPort(cReq.RemoteAddr)
+		require.NoErrorf(t, err, "error parsing RemoteAddr %q reported by the backend", cReq.RemoteAddr)
+		observed = host
+	default:
+		t.Fatalf("Unknown source IP mode %q", mode)
+	}
+
+	require.Truef(t, clientIP.Equal(net.ParseIP(observed)), "expected the backend to observe client IP %s through %s, got %q", clientIP, mode, observed)
+}
+
+// sourceIP returns the local IP used to reach addr. Connecting a UDP socket
+// selects the route without sending anything.
+func sourceIP(addr string) (net.IP, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	local, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("unexpected local address %s", conn.LocalAddr())
+	}
+	return local.IP, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// proxyProtocolListener accepts connections starting with a PROXY protocol v1
+// header, whose source address becomes the remote address of the connection.
+type proxyProtocolListener struct {
+	net.Listener
+}
+
+// proxyProtocolConn is a connection whose PROXY protocol header was read.
+type proxyProtocolConn struct {
+	net.Conn
+	reader *bufio.Reader
+	source net.Addr
+}
+
+func (c *proxyProtocolConn) Read(b []byte) (int, error) { return c.reader.Read(b) }
+func (c *proxyProtocolConn) RemoteAddr() net.Addr       { return c.source }
+
+func (l proxyProtocolListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// PROXY TCP4 <source> <destination> <source port> <destination port>
+	fields := strings.Fields(line)
+	if len(fields) != 6 || fields[0] != "PROXY" {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected PROXY protocol header %q", line)
+	}
+	source, err := net.ResolveTCPAddr("tcp", net.JoinHostPort(fields[2], fields[4]))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &proxyProtocolConn{Conn: conn, reader: reader, source: source}, nil
+}
+
+// fakeProxyProtocolGateway starts a TCP proxy standing in for a Gateway that
+// sends a PROXY protocol header to a backend reporting its RemoteAddr.
+func fakeProxyProtocolGateway(t *testing.T) string {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/json")
+		_ = json.NewEncoder(w).Encode(roundtripper.CapturedRequest{
+			Path:       r.URL.Path,
+			Host:       r.Host,
+			Method:     r.Method,
+			Protocol:   r.Proto,
+			Headers:    r.Header,
+			Namespace:  fakeNamespace,
+			Pod:        "infra-backend-v1-7f9c8d6b5-x2x9z",
+			RemoteAddr: r.RemoteAddr,
+		})
+	}))
+	backend.Listener = proxyProtocolListener{Listener: backend.Listener}
+	backend.Start()
+	t.Cleanup(backend.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				upstream, err := net.Dial("tcp", backend.Listener.Addr().String())
+				if err != nil {
+					return
+				}
+				defer upstream.Close()
+
+				source := conn.RemoteAddr().(*net.TCPAddr)
+				destination := conn.LocalAddr().(*net.TCPAddr)
+				fmt.Fprintf(upstream, "PROXY TCP4 %s %s %d %d\r\n", source.IP, destination.IP, source.Port, destination.Port)
+				go func() { _, _ = io.Copy(upstream, conn) }()
+				_, _ = io.Copy(conn, upstream)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestExpectSourceIPPropagated(t *testing.T) {
+	expected := ExpectedResponse{
+		Request:   ExpectedRequest{Path: "/source-ip"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	}
+
+	t.Run("header", func(t *testing.T) {
+		// The fake Gateway appends the IP of its peer to X-Forwarded-For.
+		gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			r.Header.Add("X-Forwarded-For", host)
+			echo(w, r, "infra-backend-v1")
+		})
+
+		ExpectSourceIPPropagated(t, &roundtripper.DefaultRoundTripper{}, gwAddr, expected, SourceIPHeader, "")
+	})
+
+	t.Run("proxy protocol", func(t *testing.T) {
+		gwAddr := fakeProxyProtocolGateway(t)
+		ExpectSourceIPPropagated(t, &roundtripper.DefaultRoundTripper{}, gwAddr, expected, SourceIPProxyProtocol, "")
+	})
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// streamingRoundTripper returns r as a StreamingRoundTripper, failing the test
+// if it doesn't support capturing streamed responses.
+func streamingRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.StreamingRoundTripper {
+	t.Helper()
+
+	streamingRT, ok := r.(roundtripper.StreamingRoundTripper)
+	require.Truef(t, ok, "%T does not support capturing streamed responses", r)
+	return streamingRT
+}
+
+// ExpectStreamedResponse makes the request, which must be routed to a backend
+// streaming its response body as configured by trickle, e.g. as server-sent
+// events, and verifies the Gateway passes the body through as it arrives
+// rather than buffering it. The whole body must be received, with no more
+// than maxGap between the request and the first chunk nor between
+// consecutive chunks. maxGap must be shorter than the trickle so that a
+// buffered response is told apart. The route must already be programmed.
+func ExpectStreamedResponse(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, trickle Trickle, maxGap time.Duration) {
+	t.Helper()
+
+	require.Lessf(t, int64(maxGap), int64(trickle.Duration()), "expected the maximum gap between chunks to be shorter than the %s trickle", trickle.Duration())
+	streamingRT := streamingRoundTripper(t, r)
+	rtReq := trickle.request(gwAddr, req)
+
+	t.Logf("Making request for a body streamed over %s to %s", trickle.Duration(), gwAddr)
+	cRes, err := streamingRT.CaptureStreamingRoundTrip(rtReq)
+	require.NoError(t, err, "expected the streamed body to be received in full")
+	require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected streamed response to have status %d", http.StatusOK)
+	require.NotEmpty(t, cRes.Chunks, "expected streamed response to have a body")
+
+	var previous time.Duration
+	for i, chunk := range cRes.Chunks {
+		gap := chunk.Elapsed - previous
+		require.LessOrEqualf(t, int64(gap), int64(maxGap), "expected chunk %d of streamed response within %s of the previous one, got it after %s; is the Gateway buffering the response?", i, maxGap, gap)
+		previous = chunk.Elapsed
+	}
+	t.Logf("Streamed response was received in %d chunks over %s", len(cRes.Chunks), previous)
+}
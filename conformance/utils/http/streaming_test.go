@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectStreamedResponse(t *testing.T) {
+	// The fake Gateway forwards to a backend sending server-sent events as
+	// requested, flushing each one.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		chunks, _ := strconv.Atoi(r.URL.Query().Get("chunks"))
+		interval, _ := time.ParseDuration(r.URL.Query().Get("interval"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for i := 0; i < chunks; i++ {
+			time.Sleep(interval)
+			_, _ = w.Write([]byte("data: " + strconv.Itoa(i) + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	trickle := Trickle{Chunks: 5, Interval: 100 * time.Millisecond}
+	ExpectStreamedResponse(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/events"}, trickle, 300*time.Millisecond)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// RouteTimeouts are the timeouts a route is configured with. Request bounds
+// the whole request, including any retries made by the Gateway, while
+// BackendRequest bounds each request made to a backend. BackendRequest must
+// be shorter than Request.
+type RouteTimeouts struct {
+	Request        time.Duration
+	BackendRequest time.Duration
+}
+
+// ExpectRouteTimeoutsEnforced makes requests, which must be routed to a
+// backend delaying its response by the duration passed in the delay query
+// parameter, and verifies that the Gateway enforces each of the configured
+// timeouts on its own:
+//
+//   - a backend responding within BackendRequest must be passed through;
+//   - a backend responding after BackendRequest but within Request must time
+//     out, since waiting for it would mean only Request is enforced;
+//   - no matter how slow the backend is, or how many times the Gateway
+//     retries it, the request must end within Request.
+//
+// Timed out requests must end with a 504 or an error. The route must
+// already be programmed.
+func ExpectRouteTimeoutsEnforced(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, timeouts RouteTimeouts) {
+	t.Helper()
+
+	require.Lessf(t, int64(timeouts.BackendRequest), int64(timeouts.Request), "expected backend request timeout %s to be shorter than request timeout %s", timeouts.BackendRequest, timeouts.Request)
+
+	// Gateways may take a little longer than Request to end a request, but
+	// not as long as the slowest backend.
+	slack := timeouts.Request / 2
+
+	delay := timeouts.BackendRequest / 2
+	_, cRes, err := r.CaptureRoundTrip(delayedRequest(gwAddr, req, delay))
+	require.NoErrorf(t, err, "expected backend responding after %s to be passed through", delay)
+	require.Equalf(t, http.StatusOK, cRes.StatusCode, "expected backend responding after %s, within the %s backend request timeout, to be passed through", delay, timeouts.BackendRequest)
+
+	for _, delay := range []time.Duration{(timeouts.BackendRequest + timeouts.Request) / 2, 2*timeouts.Request + slack} {
+		t.Logf("Making request for a backend responding after %s to %s", delay, gwAddr)
+		start := time.Now()
+		_, cRes, err := r.CaptureRoundTrip(delayedRequest(gwAddr, req, delay))
+		elapsed := time.Since(start)
+
+		switch {
+		case err != nil:
+			t.Logf("Request for a backend responding after %s ended after %s: %v", delay, elapsed, err)
+		case cRes.StatusCode == http.StatusGatewayTimeout:
+			t.Logf("Request for a backend responding after %s timed out with %d after %s", delay, cRes.StatusCode, elapsed)
+		default:
+			t.Fatalf("Expected request for a backend responding after %s to exceed the %s backend request timeout, got status %d", delay, timeouts.BackendRequest, cRes.StatusCode)
+		}
+		require.GreaterOrEqualf(t, int64(elapsed), int64(timeouts.BackendRequest), "expected the Gateway to wait for the %s backend request timeout, request ended after %s", timeouts.BackendRequest, elapsed)
+		require.Lessf(t, int64(elapsed), int64(timeouts.Request+slack), "expected the Gateway to end the request within the %s request timeout, request ended after %s", timeouts.Request, elapsed)
+	}
+}
+
+// delayedRequest converts an ExpectedRequest into the request sent to the
+// Gateway at gwAddr, asking the backend to delay its response by delay.
+func delayedRequest(gwAddr string, req ExpectedRequest, delay time.Duration) roundtripper.Request {
+	rtReq := toRoundTripperRequest(gwAddr, req)
+	query := rtReq.URL.Query()
+	query.Set("delay", delay.String())
+	rtReq.URL.RawQuery = query.Encode()
+	return rtReq
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectRouteTimeoutsEnforced(t *testing.T) {
+	timeouts := RouteTimeouts{Request: 400 * time.Millisecond, BackendRequest: 150 * time.Millisecond}
+
+	// The fake Gateway forwards to a backend delaying its response as
+	// requested, gives up on each attempt after the backend request timeout
+	// and retries it until the request timeout elapses.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		delay, _ := time.ParseDuration(r.URL.Query().Get("delay"))
+
+		start := time.Now()
+		for {
+			attempt := timeouts.BackendRequest
+			if remaining := timeouts.Request - time.Since(start); remaining < attempt {
+				attempt = remaining
+			}
+			if delay <= attempt {
+				time.Sleep(delay)
+				echo(w, r, "infra-backend-v1")
+				return
+			}
+			time.Sleep(attempt)
+			if time.Since(start) >= timeouts.Request {
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+		}
+	})
+
+	ExpectRouteTimeoutsEnforced(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/timeouts"}, timeouts)
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// tlsHandshakeRoundTripper returns the TLSHandshakeRoundTripper
+// implementation of r, failing the test if r doesn't support TLS handshakes.
+func tlsHandshakeRoundTripper(t *testing.T, r roundtripper.RoundTripper) roundtripper.TLSHandshakeRoundTripper {
+	t.Helper()
+
+	tlsRT, ok := r.(roundtripper.TLSHandshakeRoundTripper)
+	require.Truef(t, ok, "%T does not support TLS handshakes", r)
+	return tlsRT
+}
+
+// ExpectTLSSessionResumption performs two TLS handshakes with the Gateway
+// sharing a session cache and expects the second one to resume the session
+// established by the first, through either session tickets or session IDs.
+func ExpectTLSSessionResumption(t *testing.T, r roundtripper.RoundTripper, gwAddr, serverName string, config *tls.Config) {
+	t.Helper()
+
+	t.Logf("Making TLS connections to %s with SNI %s", gwAddr, serverName)
+	states, err := tlsHandshakeRoundTripper(t, r).CaptureTLSHandshakes(roundtripper.TLSHandshakeRequest{
+		Address:    gwAddr,
+		ServerName: serverName,
+		Config:     config,
+		Handshakes: 2,
+	})
+	require.NoError(t, err, "error performing TLS handshakes")
+	require.False(t, states[0].DidResume, "expected first TLS handshake to establish a new session")
+	require.True(t, states[1].DidResume, "expected second TLS handshake to resume the session")
+}
+
+// ExpectOCSPStapling performs a TLS handshake with the Gateway, requesting
+// OCSP stapling, and expects the Gateway to staple an OCSP response, which is
+// returned for further assertions.
+func ExpectOCSPStapling(t *testing.T, r roundtripper.RoundTripper, gwAddr, serverName string, config *tls.Config) []byte {
+	t.Helper()
+
+	t.Logf("Making TLS connection to %s with SNI %s, expecting a stapled OCSP response", gwAddr, serverName)
+	states, err := tlsHandshakeRoundTripper(t, r).CaptureTLSHandshakes(roundtripper.TLSHandshakeRequest{
+		Address:           gwAddr,
+		ServerName:        serverName,
+		Config:            config,
+		RequireOCSPStaple: true,
+	})
+	require.NoError(t, err, "error performing TLS handshake with OCSP stapling")
+	return states[0].OCSPResponse
+}
+
+// ExpectTLSMode performs a TLS handshake with the Gateway and verifies the
+// listener honors its TLS mode through the certificate presented: in
+// Terminate mode the Gateway presents gatewayCert, in Passthrough mode the
+// connection is forwarded and backendCert, served by the backend, is seen.
+func ExpectTLSMode(t *testing.T, r roundtripper.RoundTripper, gwAddr, serverName string, config *tls.Config, mode v1alpha2.TLSModeType, gatewayCert, backendCert *x509.Certificate) {
+	t.Helper()
+
+	expected, unexpected := gatewayCert, backendCert
+	if mode == v1alpha2.TLSModePassthrough {
+		expected, unexpected = backendCert, gatewayCert
+	}
+
+	t.Logf("Making TLS connection to %s with SNI %s, expecting %s mode", gwAddr, serverName, mode)
+	states, err := tlsHandshakeRoundTripper(t, r).CaptureTLSHandshakes(roundtripper.TLSHandshakeRequest{
+		Address:    gwAddr,
+		ServerName: serverName,
+		Config:     config,
+	})
+	require.NoError(t, err, "error performing TLS handshake")
+	require.NotEmpty(t, states[0].PeerCertificates, "expected a certificate to be presented")
+
+	leaf := states[0].PeerCertificates[0]
+	require.Falsef(t, leaf.Equal(unexpected), "%s mode is not honored, got the certificate expected for the other mode", mode)
+	require.Truef(t, leaf.Equal(expected), "expected certificate for %s mode, got one for %s", mode, leaf.Subject)
+}
+
+// ExpectTLSRouteSNIRouting performs a TLS handshake with the Gateway for each
+// SNI of backendCerts and verifies the TLSRoute matching it forwarded the
+// connection to the expected backend, identified by the certificate it
+// presents, as is the case with Passthrough listeners. Handshakes with each
+// of the unmatched SNIs, not matching any TLSRoute hostname, must fail.
+func ExpectTLSRouteSNIRouting(t *testing.T, r roundtripper.RoundTripper, gwAddr string, config *tls.Config, backendCerts map[string]*x509.Certificate, unmatched ...string) {
+	t.Helper()
+
+	tlsRT := tlsHandshakeRoundTripper(t, r)
+	for serverName, backendCert := range backendCerts {
+		serverName, backendCert := serverName, backendCert
+		t.Run(serverName, func(t *testing.T) {
+			t.Logf("Making TLS connection to %s with SNI %s", gwAddr, serverName)
+			states, err := tlsRT.CaptureTLSHandshakes(roundtripper.TLSHandshakeRequest{
+				Address:    gwAddr,
+				ServerName: serverName,
+				Config:     config,
+			})
+			require.NoErrorf(t, err, "error performing TLS handshake with SNI %s", serverName)
+			require.NotEmpty(t, states[0].PeerCertificates, "expected a certificate to be presented")
+
+			leaf := states[0].PeerCertificates[0]
+			require.Truef(t, leaf.Equal(backendCert), "expected SNI %s to be routed to the backend presenting %s, got %s", serverName, backendCert.Subject, leaf.Subject)
+		})
+	}
+
+	for _, serverName := range unmatched {
+		serverName := serverName
+		t.Run(serverName, func(t *testing.T) {
+			t.Logf("Making TLS connection to %s with unmatched SNI %s", gwAddr, serverName)
+			_, err := tlsRT.CaptureTLSHandshakes(roundtripper.TLSHandshakeRequest{
+				Address:    gwAddr,
+				ServerName: serverName,
+				Config:     config,
+			})
+			require.Errorf(t, err, "expected TLS handshake with SNI %s not matching any TLSRoute to fail", serverName)
+		})
+	}
+}
+
+// ExpectForwardedProtoHTTPS makes an https:// request to a Gateway
+// terminating TLS and verifies the backend observed it as such, through the
+// X-Forwarded-Proto header set to https. r must negotiate TLS with the
+// Gateway, e.g. a roundtripper.DefaultRoundTripper with a TLSConfig trusting
+// its serving certificate.
+func ExpectForwardedProtoHTTPS(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedResponse) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	req := toRoundTripperRequest(gwAddr, expected.Request)
+	req.URL.Scheme = "https"
+	t.Logf("Making %s request to https://%s%s", req.Method, gwAddr, expected.Request.Path)
+	cReq, cRes := WaitForConsistency(t, r, req, expected, requiredConsecutiveSuccesses)
+	ExpectResponse(t, cReq, cRes, expected)
+	require.NotNil(t, cRes.TLS, "expected the request to be made over TLS")
+
+	got := http.Header{}
+	for name, values := range cReq.Headers {
+		got[http.CanonicalHeaderKey(name)] = values
+	}
+	require.Equal(t, "https", got.Get("X-Forwarded-Proto"), "expected the backend to observe X-Forwarded-Proto: https, got headers %v", cReq.Headers)
+}
+
+// ExpectSharedHostnamePerProtocol verifies that a hostname served by both an
+// HTTP listener at httpAddr and an HTTPS listener at httpsAddr of the same
+// Gateway is routed per protocol: cleartext requests get httpExpected and
+// requests over TLS get httpsExpected, which typically name the backends of
+// distinct routes attached to each listener. Cleartext requests to the HTTPS
+// listener must not be served. r must negotiate TLS with the Gateway, see
+// ExpectForwardedProtoHTTPS.
+func ExpectSharedHostnamePerProtocol(t *testing.T, r roundtripper.RoundTripper, httpAddr, httpsAddr string, httpExpected, httpsExpected ExpectedResponse) {
+	t.Helper()
+
+	require.Equal(t, httpExpected.Request.Host, httpsExpected.Request.Host, "expected both protocols to be requested for the same hostname")
+	for _, expected := range []*ExpectedResponse{&httpExpected, &httpsExpected} {
+		if expected.Request.Method == "" {
+			expected.Request.Method = "GET"
+		}
+		if expected.StatusCode == 0 {
+			expected.StatusCode = 200
+		}
+	}
+
+	req := toRoundTripperRequest(httpAddr, httpExpected.Request)
+	t.Logf("Making %s request to http://%s%s for %s", req.Method, httpAddr, httpExpected.Request.Path, httpExpected.Request.Host)
+	cReq, cRes := WaitForConsistency(t, r, req, httpExpected, requiredConsecutiveSuccesses)
+	ExpectResponse(t, cReq, cRes, httpExpected)
+	require.Nil(t, cRes.TLS, "expected the request to the HTTP listener to be made in cleartext")
+
+	req = toRoundTripperRequest(httpsAddr, httpsExpected.Request)
+	req.URL.Scheme = "https"
+	t.Logf("Making %s request to https://%s%s for %s", req.Method, httpsAddr, httpsExpected.Request.Path, httpsExpected.Request.Host)
+	cReq, cRes = WaitForConsistency(t, r, req, httpsExpected, requiredConsecutiveSuccesses)
+	ExpectResponse(t, cReq, cRes, httpsExpected)
+	require.NotNil(t, cRes.TLS, "expected the request to the HTTPS listener to be made over TLS")
+
+	// TLS servers typically answer cleartext requests with a 400 before
+	// closing the connection.
+	req = toRoundTripperRequest(httpsAddr, httpsExpected.Request)
+	_, cRes, err := r.CaptureRoundTrip(req)
+	if err == nil {
+		require.Equalf(t, http.StatusBadRequest, cRes.StatusCode, "expected cleartext request to the HTTPS listener not to be served, got status %d", cRes.StatusCode)
+	}
+}
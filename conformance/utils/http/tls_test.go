@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// fakeTLSGateway starts an HTTPS server standing in for a TLS-terminating
+// Gateway and returns its address along with a client configuration trusting
+// its certificate, which is valid for example.com.
+func fakeTLSGateway(t *testing.T, handler http.HandlerFunc) (string, *tls.Config) {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return server.Listener.Addr().String(), &tls.Config{RootCAs: roots}
+}
+
+func TestExpectTLSSessionResumption(t *testing.T) {
+	gwAddr, config := fakeTLSGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+
+	ExpectTLSSessionResumption(t, &roundtripper.DefaultRoundTripper{}, gwAddr, "example.com", config)
+}
+
+// newCertificate returns a self-signed certificate for the provided DNS name.
+func newCertificate(t *testing.T, commonName, dnsName string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+// fakeTLSServer starts an HTTPS server presenting the provided certificate
+// and returns its address.
+func fakeTLSServer(t *testing.T, cert tls.Certificate, handler http.HandlerFunc) string {
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().String()
+}
+
+func TestExpectTLSMode(t *testing.T) {
+	gatewayCert := newCertificate(t, "gateway", "example.com")
+	backendCert := newCertificate(t, "backend", "example.com")
+
+	handler := func(w http.ResponseWriter, r *http.Request) { echo(w, r, "infra-backend-v1") }
+	// A terminating Gateway presents its own certificate while a passthrough
+	// one lets the backend certificate through.
+	terminateAddr := fakeTLSServer(t, gatewayCert, handler)
+	passthroughAddr := fakeTLSServer(t, backendCert, handler)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(gatewayCert.Leaf)
+	roots.AddCert(backendCert.Leaf)
+	config := &tls.Config{RootCAs: roots}
+	r := &roundtripper.DefaultRoundTripper{}
+
+	t.Run("Terminate", func(t *testing.T) {
+		ExpectTLSMode(t, r, terminateAddr, "example.com", config, v1alpha2.TLSModeTerminate, gatewayCert.Leaf, backendCert.Leaf)
+	})
+
+	t.Run("Passthrough", func(t *testing.T) {
+		ExpectTLSMode(t, r, passthroughAddr, "example.com", config, v1alpha2.TLSModePassthrough, gatewayCert.Leaf, backendCert.Leaf)
+	})
+}
+
+func TestExpectOCSPStapling(t *testing.T) {
+	cert := newCertificate(t, "gateway", "example.com")
+	cert.OCSPStaple = []byte("stapled OCSP response")
+	gwAddr := fakeTLSServer(t, cert, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.Leaf)
+	staple := ExpectOCSPStapling(t, &roundtripper.DefaultRoundTripper{}, gwAddr, "example.com", &tls.Config{RootCAs: roots})
+	require.Equal(t, cert.OCSPStaple, staple)
+}
+
+func TestExpectTLSRouteSNIRouting(t *testing.T) {
+	fooCert := newCertificate(t, "foo-backend", "foo.example.com")
+	barCert := newCertificate(t, "bar-backend", "bar.example.com")
+
+	// The fake Gateway passes connections through to the backend keyed by
+	// their SNI, which presents its own certificate.
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "tls-backend")
+	}))
+	server.TLS = &tls.Config{GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		switch hello.ServerName {
+		case "foo.example.com":
+			return &fooCert, nil
+		case "bar.example.com":
+			return &barCert, nil
+		}
+		return nil, fmt.Errorf("no TLSRoute matches SNI %s", hello.ServerName)
+	}}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(fooCert.Leaf)
+	roots.AddCert(barCert.Leaf)
+
+	ExpectTLSRouteSNIRouting(t, &roundtripper.DefaultRoundTripper{}, server.Listener.Addr().String(), &tls.Config{RootCAs: roots}, map[string]*x509.Certificate{
+		"foo.example.com": fooCert.Leaf,
+		"bar.example.com": barCert.Leaf,
+	}, "baz.example.com")
+}
+
+func TestExpectForwardedProtoHTTPS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("X-Forwarded-Proto", "https")
+		echo(w, r, "infra-backend-v1")
+	}))
+	t.Cleanup(server.Close)
+	gwAddr := server.Listener.Addr().String()
+	r := &roundtripper.DefaultRoundTripper{TLSConfig: &roundtripper.TLSConfig{
+		CACertificates: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+		ServerName:     "example.com",
+	}}
+
+	ExpectForwardedProtoHTTPS(t, r, gwAddr, ExpectedResponse{
+		Request:   ExpectedRequest{Host: "example.com", Path: "/"},
+		Backend:   "infra-backend-v1",
+		Namespace: fakeNamespace,
+	})
+}
+
+func TestExpectSharedHostnamePerProtocol(t *testing.T) {
+	httpAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v2")
+	}))
+	t.Cleanup(server.Close)
+	r := &roundtripper.DefaultRoundTripper{TLSConfig: &roundtripper.TLSConfig{
+		CACertificates: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}),
+		ServerName:     "example.com",
+	}}
+
+	req := ExpectedRequest{Host: "shared.example.com", Path: "/"}
+	ExpectSharedHostnamePerProtocol(t, r, httpAddr, server.Listener.Addr().String(),
+		ExpectedResponse{Request: req, Backend: "infra-backend-v1", Namespace: fakeNamespace},
+		ExpectedResponse{Request: req, Backend: "infra-backend-v2", Namespace: fakeNamespace},
+	)
+}
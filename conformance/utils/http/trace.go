@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// CaptureRoundTrip makes the request and returns its Trace so that tests can
+// log what was actually sent and received. RoundTrippers that don't
+// implement roundtripper.TracingRoundTripper are traced without the body of
+// the response.
+func CaptureRoundTrip(r roundtripper.RoundTripper, req roundtripper.Request) *roundtripper.Trace {
+	if tracingRT, ok := r.(roundtripper.TracingRoundTripper); ok {
+		return tracingRT.CaptureRoundTripTrace(req)
+	}
+	cReq, cRes, err := r.CaptureRoundTrip(req)
+	return &roundtripper.Trace{Request: req, CapturedRequest: cReq, CapturedResponse: cRes, Err: err}
+}
+
+// logTraceOnFailure logs the trace if the test has failed since failed was
+// recorded, so that the failure shows the actual request and response.
+func logTraceOnFailure(t *testing.T, failed bool, trace *roundtripper.Trace) {
+	t.Helper()
+
+	if trace != nil && !failed && t.Failed() {
+		t.Logf("Last round trip:\n%s", trace)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// basicRoundTripper only implements roundtripper.RoundTripper.
+type basicRoundTripper struct {
+	roundtripper.RoundTripper
+}
+
+func TestCaptureRoundTrip(t *testing.T) {
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		echo(w, r, "infra-backend-v1")
+	})
+	req := toRoundTripperRequest(gwAddr, ExpectedRequest{Path: "/trace"})
+
+	t.Run("tracing", func(t *testing.T) {
+		trace := CaptureRoundTrip(&roundtripper.DefaultRoundTripper{}, req)
+		require.NoError(t, trace.Err)
+		require.Equal(t, http.StatusOK, trace.CapturedResponse.StatusCode)
+		require.Contains(t, trace.CapturedRequest.Pod, "infra-backend-v1")
+		require.Contains(t, trace.BodySnippet, `"path":"/trace"`)
+	})
+	t.Run("basic", func(t *testing.T) {
+		trace := CaptureRoundTrip(basicRoundTripper{&roundtripper.DefaultRoundTripper{}}, req)
+		require.NoError(t, trace.Err)
+		require.Equal(t, http.StatusOK, trace.CapturedResponse.StatusCode)
+		require.Contains(t, trace.CapturedRequest.Pod, "infra-backend-v1")
+		require.Empty(t, trace.BodySnippet)
+	})
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectTruncatedResponse makes the request, which must be routed to a backend
+// closing the connection after sending part of the response body, and
+// verifies the truncation is visible to the client. Either the response body
+// must be truncated as well, causing the round trip to fail, or the Gateway
+// must respond with a 502 if it buffers the response. A response that seems
+// complete is never acceptable, and the outcome must be the same for every
+// attempt. The route must already be programmed.
+func ExpectTruncatedResponse(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest) {
+	t.Helper()
+
+	rtReq := toRoundTripperRequest(gwAddr, req)
+
+	var first string
+	for i := 0; i < requiredConsecutiveSuccesses; i++ {
+		var outcome string
+		_, cRes, err := r.CaptureRoundTrip(rtReq)
+		switch {
+		case err != nil:
+			t.Logf("Truncated response was observed as an error: %v", err)
+			outcome = "error"
+		case cRes.StatusCode == 502:
+			t.Logf("Truncated response was rejected by the Gateway")
+			outcome = fmt.Sprintf("status %d", cRes.StatusCode)
+		default:
+			t.Fatalf("Expected truncated response to fail or be rejected with 502, got a complete response with status %d", cRes.StatusCode)
+		}
+
+		if i == 0 {
+			first = outcome
+		}
+		require.Equalf(t, first, outcome, "expected truncated responses to be handled consistently")
+	}
+}
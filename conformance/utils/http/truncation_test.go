@@ -0,0 +1,44 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectTruncatedResponse(t *testing.T) {
+	t.Run("forwarded truncated", func(t *testing.T) {
+		// The fake Gateway streams the response of a backend that closes the
+		// connection after 10 of the 100 announced bytes.
+		gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("0123456789"))
+		})
+		ExpectTruncatedResponse(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/truncated"})
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		// The fake Gateway buffers responses and rejects truncated ones.
+		gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+		ExpectTruncatedResponse(t, &roundtripper.DefaultRoundTripper{}, gwAddr, ExpectedRequest{Path: "/truncated"})
+	})
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ExpectProtocolUpgrade sends the expected request asking for an upgrade to
+// the provided protocol, which is not WebSocket, e.g. "custom-proto/1".
+//
+// If switched is true, the Gateway must forward the upgrade to the backend
+// and relay its 101 Switching Protocols response, understanding that this may
+// fail for some amount of time. Otherwise, the Gateway must not switch
+// protocols and respond without a server error, either by rejecting the
+// upgrade or by forwarding the request as a regular one; the route must
+// already be programmed in that case.
+func ExpectProtocolUpgrade(t *testing.T, r roundtripper.RoundTripper, gwAddr string, req ExpectedRequest, protocol string, switched bool) {
+	t.Helper()
+
+	rawRT := rawRoundTripper(t, r)
+	data := buildKeepAliveRawRequest(gwAddr, req, [][]byte{
+		[]byte("Connection: Upgrade"),
+		[]byte("Upgrade: " + protocol),
+	})
+
+	t.Logf("Making request with Upgrade: %s to %s", protocol, gwAddr)
+	if !switched {
+		_, cRes, err := rawRT.CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+		require.NoErrorf(t, err, "error making raw request")
+		require.NotEqualf(t, http.StatusSwitchingProtocols, cRes.StatusCode, "expected Gateway not to switch to %s", protocol)
+		require.Lessf(t, cRes.StatusCode, 500, "expected upgrade to %s to be handled without a server error, got %d", protocol, cRes.StatusCode)
+		return
+	}
+
+	var cRes *roundtripper.CapturedResponse
+	require.Eventually(t, func() bool {
+		var err error
+		_, cRes, err = rawRT.CaptureRawRoundTrip(roundtripper.RawRequest{Address: gwAddr, Data: data})
+		if err != nil {
+			t.Logf("Request failed, not ready yet: %v", err.Error())
+			return false
+		}
+		if cRes.StatusCode != http.StatusSwitchingProtocols {
+			t.Logf("Expected response to have status %d but got %d, not ready yet", http.StatusSwitchingProtocols, cRes.StatusCode)
+			return false
+		}
+		return true
+	}, maxTimeToConsistency, 1*time.Second, "error making request, protocol was never switched to %s", protocol)
+
+	upgrade := http.Header(cRes.Headers).Get("Upgrade")
+	require.Truef(t, strings.EqualFold(protocol, upgrade), "expected switch to %s, got Upgrade: %s", protocol, upgrade)
+}
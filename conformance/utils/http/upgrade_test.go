@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestExpectProtocolUpgrade(t *testing.T) {
+	// The fake Gateway forwards requests to a backend that only switches to
+	// custom-proto/1 and refuses other upgrades with a 426.
+	gwAddr := fakeGateway(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(r.Header.Get("Connection"), "upgrade") {
+			echo(w, r, "infra-backend-v1")
+			return
+		}
+		if r.Header.Get("Upgrade") != "custom-proto/1" {
+			w.WriteHeader(http.StatusUpgradeRequired)
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("error hijacking connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: custom-proto/1\r\n\r\n")
+		_ = buf.Flush()
+	})
+
+	req := ExpectedRequest{Path: "/upgrade"}
+	t.Run("switched", func(t *testing.T) {
+		ExpectProtocolUpgrade(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, "custom-proto/1", true)
+	})
+	t.Run("rejected", func(t *testing.T) {
+		ExpectProtocolUpgrade(t, &roundtripper.DefaultRoundTripper{}, gwAddr, req, "other-proto/2", false)
+	})
+}
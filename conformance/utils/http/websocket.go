@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// MakeWebSocketRequestAndExpectEcho performs a WebSocket handshake through the
+// Gateway, understanding that the upgrade may fail for some amount of time.
+//
+// Once the connection is upgraded (101 Switching Protocols), it verifies that
+// every provided message was echoed back by the backend.
+func MakeWebSocketRequestAndExpectEcho(t *testing.T, r roundtripper.RoundTripper, gwAddr string, expected ExpectedRequest, messages []string) {
+	t.Helper()
+
+	wsRoundTripper, ok := r.(roundtripper.WebSocketRoundTripper)
+	require.Truef(t, ok, "%T does not support WebSocket round trips", r)
+
+	t.Logf("Making WebSocket request to http://%s%s", gwAddr, expected.Path)
+
+	req := roundtripper.WebSocketRequest{
+		URL:      url.URL{Scheme: "http", Host: gwAddr, Path: expected.Path},
+		Host:     expected.Host,
+		Messages: messages,
+	}
+	if expected.Headers != nil {
+		req.Headers = map[string][]string{}
+		for name, value := range expected.Headers {
+			req.Headers[name] = []string{value}
+		}
+	}
+
+	var cRes *roundtripper.CapturedWebSocketResponse
+	require.Eventually(t, func() bool {
+		var err error
+		cRes, err = wsRoundTripper.CaptureWebSocketRoundTrip(req)
+		if err != nil {
+			t.Logf("WebSocket request failed, not ready yet: %v", err.Error())
+			return false
+		}
+		if cRes.StatusCode != http.StatusSwitchingProtocols {
+			t.Logf("Expected handshake response to have status %d but got %d, %s, not ready yet", http.StatusSwitchingProtocols, cRes.StatusCode, describeWebSocketHandshake(cRes))
+			return false
+		}
+		return true
+	}, maxTimeToConsistency, 1*time.Second, "error making WebSocket request, connection was never upgraded")
+
+	upgrade := http.Header(cRes.Headers).Get("Upgrade")
+	assert.Truef(t, strings.EqualFold("websocket", upgrade), "expected handshake response to switch to websocket, got Upgrade: %s", upgrade)
+	assert.Equal(t, messages, cRes.Messages, "expected all messages to be echoed back over the upgraded connection")
+}
+
+// describeWebSocketHandshake tells, for a handshake that didn't switch
+// protocols, whether the upgrade headers reached the backend.
+func describeWebSocketHandshake(cRes *roundtripper.CapturedWebSocketResponse) string {
+	if cRes.BackendRequest == nil {
+		return "the response wasn't echoed by the backend"
+	}
+	headers := http.Header{}
+	for name, values := range cRes.BackendRequest.Headers {
+		headers[http.CanonicalHeaderKey(name)] = values
+	}
+	if headers.Get("Upgrade") == "" {
+		return "the backend received the request without its Upgrade header, which was stripped by the Gateway"
+	}
+	return fmt.Sprintf("the backend received the request with Upgrade: %s but didn't switch protocols", headers.Get("Upgrade"))
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestDescribeWebSocketHandshake(t *testing.T) {
+	require.Equal(t, "the response wasn't echoed by the backend", describeWebSocketHandshake(&roundtripper.CapturedWebSocketResponse{StatusCode: 404}))
+
+	stripped := &roundtripper.CapturedWebSocketResponse{
+		StatusCode:     200,
+		BackendRequest: &roundtripper.CapturedRequest{Headers: map[string][]string{"Connection": {"close"}}},
+	}
+	require.Equal(t, "the backend received the request without its Upgrade header, which was stripped by the Gateway", describeWebSocketHandshake(stripped))
+
+	refused := &roundtripper.CapturedWebSocketResponse{
+		StatusCode:     400,
+		BackendRequest: &roundtripper.CapturedRequest{Headers: map[string][]string{"upgrade": {"websocket"}}},
+	}
+	require.Equal(t, "the backend received the request with Upgrade: websocket but didn't switch protocols", describeWebSocketHandshake(refused))
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// AddressFamily selects which IP addresses of a Gateway requests are sent
+// to, e.g. when a Gateway advertises both an IPv4 and an IPv6 address.
+type AddressFamily string
+
+const (
+	// AnyAddressFamily selects the first usable address of a Gateway.
+	AnyAddressFamily AddressFamily = ""
+	// IPv4AddressFamily only selects IPv4 addresses.
+	IPv4AddressFamily AddressFamily = "IPv4"
+	// IPv6AddressFamily only selects IPv6 addresses.
+	IPv6AddressFamily AddressFamily = "IPv6"
+	// PreferIPv6AddressFamily selects IPv6 addresses, falling back to the
+	// first usable address if the Gateway has none.
+	PreferIPv6AddressFamily AddressFamily = "PreferIPv6"
+)
+
+// Valid returns true if the AddressFamily is one of the defined ones.
+func (f AddressFamily) Valid() bool {
+	switch f {
+	case AnyAddressFamily, IPv4AddressFamily, IPv6AddressFamily, PreferIPv6AddressFamily:
+		return true
+	}
+	return false
+}
+
+// matches returns true if the IP belongs to the AddressFamily.
+func (f AddressFamily) matches(ip net.IP) bool {
+	switch f {
+	case IPv4AddressFamily:
+		return ip.To4() != nil
+	case IPv6AddressFamily:
+		return ip.To4() == nil && ip.To16() != nil
+	}
+	return ip != nil
+}
+
+// GatewayAddress is an address published in the status of a Gateway, along
+// with the target requests to its first listener are dialed to.
+type GatewayAddress struct {
+	Type  v1alpha2.AddressType
+	Value string
+	// Dial is the ip:port requests are sent to. For Hostname addresses, the
+	// IP is the one the hostname resolved to.
+	Dial string
+}
+
+// lookupIPAddr resolves Hostname addresses, it's replaced in tests.
+var lookupIPAddr = net.DefaultResolver.LookupIPAddr
+
+// GatewayMustHaveAddressWithContext waits until the Gateway publishes an
+// address of the provided family in its status and returns it. IPAddress
+// addresses are used as is, Hostname addresses are resolved through DNS and
+// other types of addresses are ignored. This will cause the test to halt if
+// the GatewayMustHaveAddress timeout is exceeded or ctx is cancelled.
+func GatewayMustHaveAddressWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, gwNN types.NamespacedName, family AddressFamily) GatewayAddress {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	var (
+		address   GatewayAddress
+		published []v1alpha2.GatewayAddress
+	)
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.GatewayMustHaveAddress, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			t.Logf("Error fetching %s Gateway: %v", gwNN, err)
+			return false, nil
+		}
+		if len(gw.Spec.Listeners) == 0 {
+			return false, fmt.Errorf("%s Gateway has no listeners", gwNN)
+		}
+		published = gw.Status.Addresses
+
+		var ok bool
+		address, ok = gatewayDialAddress(ctx, t, gw, family)
+		return ok, nil
+	})
+	require.NoErrorf(t, waitErr, "no usable %s address published by %s Gateway within %s, published addresses: %s",
+		familyName(family), gwNN, timeoutConfig.GatewayMustHaveAddress, formatAddresses(published))
+	return address
+}
+
+// GatewaysMustHaveAddressWithContext waits until every Gateway in the provided
+// namespaces publishes an address of the provided family, e.g. so that an
+// IPv6 run fails early if Gateways only got IPv4 addresses. This will cause
+// the test to halt if the GatewayMustHaveAddress timeout is exceeded for a
+// Gateway or ctx is cancelled.
+func GatewaysMustHaveAddressWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespaces []string, family AddressFamily) {
+	t.Helper()
+
+	for _, ns := range namespaces {
+		gwList := &v1alpha2.GatewayList{}
+		require.NoErrorf(t, c.List(ctx, gwList, client.InNamespace(ns)), "error listing Gateways in %s namespace", ns)
+		for _, gw := range gwList.Items {
+			gwNN := types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}
+			address := GatewayMustHaveAddressWithContext(ctx, t, c, timeoutConfig, gwNN, family)
+			t.Logf("%s Gateway has %s address %s", gwNN, address.Type, address.Dial)
+		}
+	}
+}
+
+// gatewayDialAddress returns the first address of the Gateway belonging to
+// the family, joined with the port of its first listener, if any.
+func gatewayDialAddress(ctx context.Context, t *testing.T, gw *v1alpha2.Gateway, family AddressFamily) (GatewayAddress, bool) {
+	if family == PreferIPv6AddressFamily {
+		if address, ok := gatewayDialAddress(ctx, t, gw, IPv6AddressFamily); ok {
+			return address, true
+		}
+		family = AnyAddressFamily
+	}
+
+	port := strconv.FormatInt(int64(gw.Spec.Listeners[0].Port), 10)
+	for _, address := range gw.Status.Addresses {
+		// The type of an address defaults to IPAddress.
+		addressType := v1alpha2.IPAddressType
+		if address.Type != nil {
+			addressType = *address.Type
+		}
+
+		var ip net.IP
+		switch addressType {
+		case v1alpha2.IPAddressType:
+			ip = net.ParseIP(address.Value)
+		case v1alpha2.HostnameAddressType:
+			ip = resolveHostname(ctx, t, address.Value, family)
+		default:
+			continue
+		}
+
+		if ip != nil && family.matches(ip) {
+			return GatewayAddress{
+				Type:  addressType,
+				Value: address.Value,
+				Dial:  net.JoinHostPort(ip.String(), port),
+			}, true
+		}
+	}
+	return GatewayAddress{}, false
+}
+
+// resolveHostname returns the first IP of the family the hostname resolves
+// to, or nil if it doesn't resolve to any.
+func resolveHostname(ctx context.Context, t *testing.T, hostname string, family AddressFamily) net.IP {
+	addrs, err := lookupIPAddr(ctx, hostname)
+	if err != nil {
+		t.Logf("Error resolving Gateway hostname %s: %v", hostname, err)
+		return nil
+	}
+	for _, addr := range addrs {
+		if family.matches(addr.IP) {
+			return addr.IP
+		}
+	}
+	return nil
+}
+
+// familyName returns the name of the family for error messages.
+func familyName(family AddressFamily) string {
+	switch family {
+	case AnyAddressFamily, PreferIPv6AddressFamily:
+		return "IP or Hostname"
+	}
+	return string(family)
+}
+
+// formatAddresses formats the addresses of a Gateway for error messages.
+func formatAddresses(addresses []v1alpha2.GatewayAddress) string {
+	if len(addresses) == 0 {
+		return "none"
+	}
+	formatted := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		addressType := v1alpha2.IPAddressType
+		if address.Type != nil {
+			addressType = *address.Type
+		}
+		formatted = append(formatted, fmt.Sprintf("%s (%s)", address.Value, addressType))
+	}
+	return strings.Join(formatted, ", ")
+}
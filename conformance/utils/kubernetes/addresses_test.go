@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+func TestGatewayMustHaveAddress(t *testing.T) {
+	lookup := lookupIPAddr
+	t.Cleanup(func() { lookupIPAddr = lookup })
+	lookupIPAddr = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		if host != "gateway.example.com" {
+			return nil, errors.New("no such host")
+		}
+		return []net.IPAddr{{IP: net.ParseIP("2001:db8::1")}, {IP: net.ParseIP("192.0.2.1")}}, nil
+	}
+
+	hostname, ip, named := v1alpha2.HostnameAddressType, v1alpha2.IPAddressType, v1alpha2.NamedAddressType
+	newGateway := func(name string, addresses ...v1alpha2.GatewayAddress) *v1alpha2.Gateway {
+		return &v1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "gateway-conformance-infra"},
+			Spec: v1alpha2.GatewaySpec{
+				Listeners: []v1alpha2.Listener{{Name: "http", Port: 8080, Protocol: v1alpha2.HTTPProtocolType}},
+			},
+			Status: v1alpha2.GatewayStatus{Addresses: addresses},
+		}
+	}
+	c := newFakeClient(t,
+		newGateway("hostname", v1alpha2.GatewayAddress{Type: &named, Value: "internal"}, v1alpha2.GatewayAddress{Type: &hostname, Value: "gateway.example.com"}),
+		newGateway("dual-stack", v1alpha2.GatewayAddress{Type: &ip, Value: "192.0.2.10"}, v1alpha2.GatewayAddress{Value: "2001:db8::10"}),
+		newGateway("ipv4", v1alpha2.GatewayAddress{Type: &ip, Value: "192.0.2.20"}),
+	)
+
+	timeoutConfig := config.DefaultTimeoutConfig()
+	timeoutConfig.GatewayMustHaveAddress = 5 * time.Second
+	hostnameNN := types.NamespacedName{Name: "hostname", Namespace: "gateway-conformance-infra"}
+	dualStackNN := types.NamespacedName{Name: "dual-stack", Namespace: "gateway-conformance-infra"}
+	ipv4NN := types.NamespacedName{Name: "ipv4", Namespace: "gateway-conformance-infra"}
+
+	testCases := []struct {
+		name     string
+		gwNN     types.NamespacedName
+		family   AddressFamily
+		expected GatewayAddress
+	}{{
+		name:     "hostname resolved to the first address",
+		gwNN:     hostnameNN,
+		expected: GatewayAddress{Type: hostname, Value: "gateway.example.com", Dial: "[2001:db8::1]:8080"},
+	}, {
+		name:     "hostname resolved to an IPv4 address",
+		gwNN:     hostnameNN,
+		family:   IPv4AddressFamily,
+		expected: GatewayAddress{Type: hostname, Value: "gateway.example.com", Dial: "192.0.2.1:8080"},
+	}, {
+		name:     "first IP address",
+		gwNN:     dualStackNN,
+		expected: GatewayAddress{Type: ip, Value: "192.0.2.10", Dial: "192.0.2.10:8080"},
+	}, {
+		name:     "IPv6 address without a type",
+		gwNN:     dualStackNN,
+		family:   IPv6AddressFamily,
+		expected: GatewayAddress{Type: ip, Value: "2001:db8::10", Dial: "[2001:db8::10]:8080"},
+	}, {
+		name:     "preferred IPv6 address",
+		gwNN:     dualStackNN,
+		family:   PreferIPv6AddressFamily,
+		expected: GatewayAddress{Type: ip, Value: "2001:db8::10", Dial: "[2001:db8::10]:8080"},
+	}, {
+		name:     "IPv4 address without a preferred IPv6 address",
+		gwNN:     ipv4NN,
+		family:   PreferIPv6AddressFamily,
+		expected: GatewayAddress{Type: ip, Value: "192.0.2.20", Dial: "192.0.2.20:8080"},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			address := GatewayMustHaveAddressWithContext(context.Background(), t, c, timeoutConfig, tc.gwNN, tc.family)
+			require.Equal(t, tc.expected, address)
+		})
+	}
+
+	GatewaysMustHaveAddressWithContext(context.Background(), t, c, timeoutConfig, []string{"gateway-conformance-infra"}, IPv4AddressFamily)
+}
+
+func TestGatewayDialAddressUnusable(t *testing.T) {
+	named := v1alpha2.NamedAddressType
+	gw := &v1alpha2.Gateway{
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{{Name: "http", Port: 80, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+		Status: v1alpha2.GatewayStatus{
+			Addresses: []v1alpha2.GatewayAddress{{Type: &named, Value: "internal"}, {Value: "192.0.2.10"}},
+		},
+	}
+
+	_, ok := gatewayDialAddress(context.Background(), t, gw, IPv6AddressFamily)
+	require.False(t, ok, "expected no IPv6 address to be usable")
+	require.Equal(t, "internal (NamedAddress), 192.0.2.10 (IPAddress)", formatAddresses(gw.Status.Addresses))
+	require.False(t, AddressFamily("IPv5").Valid())
+}
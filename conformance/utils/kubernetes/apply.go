@@ -25,10 +25,12 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"text/template"
 	"time"
 
 	"github.com/stretchr/testify/require"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -38,6 +40,11 @@ import (
 	"sigs.k8s.io/gateway-api/conformance"
 )
 
+// OwnedLabel is set on every resource created by the Applier. Only resources
+// carrying it are deleted on cleanup, so that resources which already existed
+// in the cluster are left untouched.
+const OwnedLabel = "gateway-api.sigs.k8s.io/conformance-owned"
+
 // Applier prepares manifests depending on the available options and applies
 // them to the Kubernetes cluster.
 type Applier struct {
@@ -46,9 +53,51 @@ type Applier struct {
 	// manifests to a valid, unique port. There must be as many
 	// ValidUniqueListenerPorts as there are listeners in the set of manifests.
 	// For example, given two Gateways, each with 2 listeners, there should be
-	// four ValidUniqueListenerPorts.
+	// four ValidUniqueListenerPorts. Manifests with more listeners than ports,
+	// or duplicate ports, are rejected before anything is applied; manifests
+	// with fewer listeners, such as those of single tests, use the first ports.
 	// If empty or nil, ports are not modified.
 	ValidUniqueListenerPorts []v1alpha2.PortNumber
+	// Namespace, if set, is used for namespaced resources whose manifest
+	// doesn't specify a namespace.
+	Namespace string
+	// RetryBudget is how long applying a resource is retried for when it
+	// fails with a transient error, e.g. because a CRD or an admission
+	// webhook isn't ready yet on a freshly created cluster. Other errors,
+	// such as invalid resources, fail immediately. If zero, applies aren't
+	// retried.
+	RetryBudget time.Duration
+	// Substitutions, if not nil, makes manifests be rendered as text/template
+	// templates before being applied, with the substitutions as values, e.g.
+	// {{ .NamespacePrefix }}. The GatewayClassName value is set to the name of
+	// the GatewayClass under test unless overridden. Referencing an undefined
+	// value fails, before anything is applied.
+	Substitutions map[string]string
+	// RetainOnFailure, if set, keeps the resources that would be deleted on
+	// cleanup when the test failed, so that they can be inspected.
+	RetainOnFailure bool
+	// Tracker, if set, records the applied resources and whether they were
+	// cleaned up, see FindLeakedResources.
+	Tracker *ResourceTracker
+	// DeletionTimeout, if set, is how long cleanup waits for each deleted
+	// resource to be gone, e.g. while its finalizers run, before deleting
+	// the next one. Resources still there afterwards are logged, and the
+	// cleanup moves on. If zero, deletes aren't waited for.
+	DeletionTimeout time.Duration
+	// DiffApplied, if set, makes every applied resource be read back and
+	// logs how the stored object differs from the submitted one, e.g.
+	// because of defaults or mutating admission webhooks, ignoring the
+	// metadata and status managed by the apiserver.
+	DiffApplied bool
+}
+
+// clusterScopedKinds lists the kinds of the cluster-scoped resources found in
+// conformance manifests, which never get a namespace set.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":          true,
+	"GatewayClass":       true,
+	"ClusterRole":        true,
+	"ClusterRoleBinding": true,
 }
 
 // prepareGateway adjusts both listener ports and the gatewayClassName. It
@@ -123,8 +172,8 @@ func (a Applier) prepareResources(t *testing.T, decoder *yaml.YAMLOrJSONDecoder,
 			continue
 		}
 
-		if uObj.GetKind() == "Gateway" {
-			portIndex = prepareGateway(t, &uObj, gcName, a.ValidUniqueListenerPorts, portIndex)
+		if a.Namespace != "" && uObj.GetNamespace() == "" && !clusterScopedKinds[uObj.GetKind()] {
+			uObj.SetNamespace(a.Namespace)
 		}
 
 		if uObj.GetKind() == "Namespace" && uObj.GetObjectKind().GroupVersionKind().Group == "" {
@@ -134,16 +183,132 @@ func (a Applier) prepareResources(t *testing.T, decoder *yaml.YAMLOrJSONDecoder,
 		resources = append(resources, uObj)
 	}
 
+	if err := a.checkListenerPorts(resources); err != nil {
+		return nil, err
+	}
+	for i := range resources {
+		if resources[i].GetKind() == "Gateway" {
+			portIndex = prepareGateway(t, &resources[i], gcName, a.ValidUniqueListenerPorts, portIndex)
+		}
+	}
+
 	return resources, nil
 }
 
-// MustApplyWithCleanup creates or updates Kubernetes resources defined with the
-// provided YAML file and registers a cleanup function for resources it created.
-// Note that this does not remove resources that already existed in the cluster.
+// renderManifest renders the manifest as a template with the Substitutions,
+// if set. The data is returned as is otherwise.
+func (a Applier) renderManifest(location string, data *bytes.Buffer, gcName string) (*bytes.Buffer, error) {
+	if a.Substitutions == nil {
+		return data, nil
+	}
+
+	values := map[string]string{"GatewayClassName": gcName}
+	for name, value := range a.Substitutions {
+		values[name] = value
+	}
+
+	tmpl, err := template.New(location).Option("missingkey=error").Parse(data.String())
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s as a template: %w", location, err)
+	}
+	rendered := &bytes.Buffer{}
+	if err := tmpl.Execute(rendered, values); err != nil {
+		return nil, fmt.Errorf("error rendering %s: %w", location, err)
+	}
+	return rendered, nil
+}
+
+// checkListenerPorts returns an error if the ValidUniqueListenerPorts, when
+// set, contain duplicates or are fewer than the listeners of the Gateways
+// among the resources.
+func (a Applier) checkListenerPorts(resources []unstructured.Unstructured) error {
+	if len(a.ValidUniqueListenerPorts) == 0 {
+		return nil
+	}
+
+	seen := map[v1alpha2.PortNumber]bool{}
+	for _, port := range a.ValidUniqueListenerPorts {
+		if seen[port] {
+			return fmt.Errorf("duplicate port %d in ValidUniqueListenerPorts", port)
+		}
+		seen[port] = true
+	}
+
+	listeners, err := countListeners(resources)
+	if err != nil {
+		return err
+	}
+	if listeners > len(a.ValidUniqueListenerPorts) {
+		return fmt.Errorf("expected %d ValidUniqueListenerPorts, got %d", listeners, len(a.ValidUniqueListenerPorts))
+	}
+	return nil
+}
+
+// countListeners returns the number of listeners of the Gateways among the
+// resources.
+func countListeners(resources []unstructured.Unstructured) (int, error) {
+	listeners := 0
+	for _, uObj := range resources {
+		if uObj.GetKind() != "Gateway" {
+			continue
+		}
+		gwListeners, _, err := unstructured.NestedSlice(uObj.Object, "spec", "listeners")
+		if err != nil {
+			return 0, fmt.Errorf("error getting `spec.listeners` on %s Gateway resource: %w", uObj.GetName(), err)
+		}
+		listeners += len(gwListeners)
+	}
+	return listeners, nil
+}
+
+// CountListeners returns the number of listeners of the Gateways defined in
+// the provided YAML file, i.e. how many ValidUniqueListenerPorts applying it
+// takes.
+func (a Applier) CountListeners(ctx context.Context, location string, gcName string) (int, error) {
+	data, err := getContentsFromPathOrURL(ctx, location)
+	if err != nil {
+		return 0, err
+	}
+	data, err = a.renderManifest(location, data, gcName)
+	if err != nil {
+		return 0, err
+	}
+
+	var resources []unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(data, 4096)
+	for {
+		uObj := unstructured.Unstructured{}
+		if err := decoder.Decode(&uObj); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, fmt.Errorf("error parsing %s: %w", location, err)
+		}
+		resources = append(resources, uObj)
+	}
+	return countListeners(resources)
+}
+
+// MustApplyWithCleanup is MustApplyWithCleanupWithContext with a background
+// context.
+//
+// Deprecated: use MustApplyWithCleanupWithContext.
 func (a Applier) MustApplyWithCleanup(t *testing.T, c client.Client, location string, gcName string, cleanup bool) {
-	data, err := getContentsFromPathOrURL(location)
+	a.MustApplyWithCleanupWithContext(context.Background(), t, c, location, gcName, cleanup)
+}
+
+// MustApplyWithCleanupWithContext creates or updates Kubernetes resources
+// defined with the provided YAML file and registers a cleanup function for
+// resources it created. Note that this does not remove resources that already
+// existed in the cluster, unless they were created by a previous run of the
+// conformance suite. Cancelling ctx aborts the apply, but not the cleanup.
+func (a Applier) MustApplyWithCleanupWithContext(ctx context.Context, t *testing.T, c client.Client, location string, gcName string, cleanup bool) {
+	data, err := getContentsFromPathOrURL(ctx, location)
 	require.NoError(t, err)
 
+	data, err = a.renderManifest(location, data, gcName)
+	require.NoErrorf(t, err, "error rendering manifest")
+
 	decoder := yaml.NewYAMLOrJSONDecoder(data, 4096)
 
 	resources, err := a.prepareResources(t, decoder, gcName)
@@ -152,59 +317,154 @@ func (a Applier) MustApplyWithCleanup(t *testing.T, c client.Client, location st
 		require.NoErrorf(t, err, "error parsing manifest")
 	}
 
+	a.mustApplyResources(ctx, t, c, resources, cleanup)
+}
+
+// mustApplyResources creates or updates the provided resources and registers
+// a cleanup function deleting the ones owned by the conformance suite, routes
+// first and GatewayClasses last.
+func (a Applier) mustApplyResources(ctx context.Context, t *testing.T, c client.Client, resources []unstructured.Unstructured, cleanup bool) {
+	var toDelete []*unstructured.Unstructured
+	if cleanup {
+		t.Cleanup(func() {
+			a.deleteResources(t, c, toDelete)
+		})
+	}
+
 	for i := range resources {
 		uObj := &resources[i]
+		// Applying the resource overwrites it with the stored object.
+		submitted := uObj.DeepCopy()
 
-		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-		defer cancel()
+		var created, owned bool
+		err := a.retryTransient(ctx, t, uObj, func() error {
+			ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+			defer cancel()
 
-		namespacedName := types.NamespacedName{Namespace: uObj.GetNamespace(), Name: uObj.GetName()}
-		fetchedObj := uObj.DeepCopy()
-		err := c.Get(ctx, namespacedName, fetchedObj)
-		if err != nil {
-			if !apierrors.IsNotFound(err) {
-				require.NoErrorf(t, err, "error getting resource")
-			}
-			t.Logf("Creating %s %s", uObj.GetName(), uObj.GetKind())
-			err = c.Create(ctx, uObj)
-			require.NoErrorf(t, err, "error creating resource")
-
-			if cleanup {
-				t.Cleanup(func() {
-					ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-					defer cancel()
-					t.Logf("Deleting %s %s", uObj.GetName(), uObj.GetKind())
-					err = c.Delete(ctx, uObj)
-					require.NoErrorf(t, err, "error deleting resource")
-				})
+			var err error
+			created, owned, err = applyResource(ctx, t, c, uObj)
+			return err
+		})
+
+		if err == nil && a.Tracker != nil {
+			a.Tracker.applied(uObj)
+		}
+		if err == nil && a.DiffApplied {
+			if created || owned {
+				setOwned(submitted)
 			}
-			continue
+			logAppliedDiff(ctx, t, c, submitted)
+		}
+		if cleanup && (created || owned) {
+			toDelete = append(toDelete, uObj)
+		} else if cleanup && !created && err == nil {
+			t.Logf("Not deleting %s %s on cleanup, it was not created by the conformance suite", uObj.GetName(), uObj.GetKind())
 		}
+		require.NoError(t, err)
+	}
+}
 
-		uObj.SetResourceVersion(fetchedObj.GetResourceVersion())
-		t.Logf("Updating %s %s", uObj.GetName(), uObj.GetKind())
-		err = c.Update(ctx, uObj)
+// applyResource creates the resource, or updates it if it already exists.
+// It returns whether it was created, or whether it was owned by the suite
+// if it existed.
+func applyResource(ctx context.Context, t *testing.T, c client.Client, uObj *unstructured.Unstructured) (created, owned bool, err error) {
+	namespacedName := types.NamespacedName{Namespace: uObj.GetNamespace(), Name: uObj.GetName()}
+	fetchedObj := uObj.DeepCopy()
+	err = c.Get(ctx, namespacedName, fetchedObj)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, false, fmt.Errorf("error getting resource: %w", err)
+		}
+		setOwned(uObj)
+		t.Logf("Creating %s %s", uObj.GetName(), uObj.GetKind())
+		if err = c.Create(ctx, uObj); err != nil {
+			return false, false, fmt.Errorf("error creating resource: %w", err)
+		}
+		return true, false, nil
+	}
+
+	// Resources that existed before and weren't created by the suite
+	// belong to the user and must survive the cleanup.
+	owned = isOwned(fetchedObj)
+	if owned {
+		setOwned(uObj)
+	}
+
+	uObj.SetResourceVersion(fetchedObj.GetResourceVersion())
+	t.Logf("Updating %s %s", uObj.GetName(), uObj.GetKind())
+	if err = c.Update(ctx, uObj); err != nil {
+		return false, owned, fmt.Errorf("error updating resource: %w", err)
+	}
+	return false, owned, nil
+}
+
+// retryTransient calls apply until it succeeds, fails with an error that
+// isn't transient, the RetryBudget is exhausted or ctx is cancelled, backing
+// off exponentially between attempts. The last error is returned.
+func (a Applier) retryTransient(ctx context.Context, t *testing.T, uObj *unstructured.Unstructured, apply func() error) error {
+	deadline := time.Now().Add(a.RetryBudget)
+	backoff := 500 * time.Millisecond
+	for {
+		err := apply()
+		if err == nil || !isTransientApplyError(err) || time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+
+		t.Logf("Retrying to apply %s %s in %s: %v", uObj.GetName(), uObj.GetKind(), backoff, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > 5*time.Second {
+			backoff = 5 * time.Second
+		}
+	}
+}
 
-		if cleanup {
-			t.Cleanup(func() {
-				ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
-				defer cancel()
-				t.Logf("Deleting %s %s", uObj.GetName(), uObj.GetKind())
-				err = c.Delete(ctx, uObj)
-				require.NoErrorf(t, err, "error deleting resource")
-			})
+// isTransientApplyError returns true if the error, or any error it wraps, is
+// expected to go away on its own, e.g. while CRDs or admission webhooks of a
+// freshly created cluster settle.
+func isTransientApplyError(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch {
+		case apimeta.IsNoMatchError(err),
+			apierrors.IsConflict(err),
+			apierrors.IsServiceUnavailable(err),
+			apierrors.IsServerTimeout(err),
+			apierrors.IsTimeout(err),
+			apierrors.IsTooManyRequests(err):
+			return true
+		case apierrors.IsInternalError(err) && strings.Contains(err.Error(), "failed calling webhook"):
+			return true
 		}
-		require.NoErrorf(t, err, "error updating resource")
 	}
+	return false
+}
+
+// setOwned labels the resource as created by the conformance suite.
+func setOwned(uObj *unstructured.Unstructured) {
+	labels := uObj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[OwnedLabel] = "true"
+	uObj.SetLabels(labels)
+}
+
+// isOwned returns true if the resource was created by the conformance suite.
+func isOwned(uObj *unstructured.Unstructured) bool {
+	return uObj.GetLabels()[OwnedLabel] == "true"
 }
 
 // getContentsFromPathOrURL takes a string that can either be a local file
 // path or an https:// URL to YAML manifests and provides the contents.
-func getContentsFromPathOrURL(location string) (*bytes.Buffer, error) {
+func getContentsFromPathOrURL(ctx context.Context, location string) (*bytes.Buffer, error) {
 	if strings.HasPrefix(location, "http://") {
 		return nil, fmt.Errorf("data can't be retrieved from %s: http is not supported, use https", location)
 	} else if strings.HasPrefix(location, "https://") {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 		defer cancel()
 
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
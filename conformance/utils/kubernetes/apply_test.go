@@ -17,12 +17,27 @@ limitations under the License.
 package kubernetes
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/gateway-api/apis/v1alpha2"
 	_ "sigs.k8s.io/gateway-api/conformance/utils/flags"
@@ -259,6 +274,53 @@ spec:
 				},
 			},
 		}},
+	}, {
+		name:    "default namespace",
+		applier: Applier{Namespace: "reused"},
+		given: `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: unset
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: set
+  namespace: explicit
+`,
+		expected: []unstructured.Unstructured{{
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]interface{}{
+					"name": "test",
+				},
+			},
+		}, {
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":      "unset",
+					"namespace": "reused",
+				},
+			},
+		}, {
+			Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Service",
+				"metadata": map[string]interface{}{
+					"name":      "set",
+					"namespace": "explicit",
+				},
+			},
+		}},
 	}}
 
 	for _, tc := range tests {
@@ -272,3 +334,226 @@ spec:
 		})
 	}
 }
+
+func TestPrepareResourcesInvalidListenerPorts(t *testing.T) {
+	given := `
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind:       Gateway
+metadata:
+  name: test
+spec:
+  gatewayClassName: {GATEWAY_CLASS_NAME}
+  listeners:
+    - name: http
+      port: 80
+      protocol: HTTP
+    - name: https
+      port: 443
+      protocol: HTTPS
+`
+	tests := []struct {
+		name     string
+		ports    []v1alpha2.PortNumber
+		expected string
+	}{{
+		name:     "fewer ports than listeners",
+		ports:    []v1alpha2.PortNumber{8000},
+		expected: "expected 2 ValidUniqueListenerPorts, got 1",
+	}, {
+		name:     "duplicate ports",
+		ports:    []v1alpha2.PortNumber{8000, 8001, 8000},
+		expected: "duplicate port 8000 in ValidUniqueListenerPorts",
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(given), 4096)
+
+			_, err := Applier{ValidUniqueListenerPorts: tc.ports}.prepareResources(t, decoder, "test-class")
+
+			require.EqualError(t, err, tc.expected)
+		})
+	}
+}
+
+func TestCountListeners(t *testing.T) {
+	a := Applier{}
+	listeners, err := a.CountListeners(context.Background(), "tests/httproute-listener-hostname-matching.yaml", "test-class")
+	require.NoError(t, err)
+	require.Equal(t, 3, listeners)
+
+	listeners, err = a.CountListeners(context.Background(), "tests/httproute-simple-same-namespace.yaml", "test-class")
+	require.NoError(t, err)
+	require.Zero(t, listeners, "expected manifests without Gateways to have no listeners")
+
+	_, err = a.CountListeners(context.Background(), "tests/missing.yaml", "test-class")
+	require.Error(t, err)
+}
+
+func TestApplyCleanupPreservesExistingResources(t *testing.T) {
+	existing := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "user-config", Namespace: "test"},
+		Data:       map[string]string{"owner": "user"},
+	}
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build()
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: user-config
+  namespace: test
+data:
+  owner: suite
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: suite-config
+  namespace: test
+`), 4096)
+	a := Applier{}
+	resources, err := a.prepareResources(t, decoder, "test-class")
+	require.NoError(t, err)
+
+	t.Run("apply", func(t *testing.T) {
+		a.mustApplyResources(context.Background(), t, c, resources, true)
+
+		suiteConfig := &v1.ConfigMap{}
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "suite-config"}, suiteConfig))
+		require.Equal(t, "true", suiteConfig.Labels[OwnedLabel])
+	})
+
+	// Cleanup of the subtest has run at this point.
+	userConfig := &v1.ConfigMap{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "user-config"}, userConfig), "expected pre-existing resource to survive cleanup")
+	require.NotContains(t, userConfig.Labels, OwnedLabel)
+
+	err = c.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "suite-config"}, &v1.ConfigMap{})
+	require.True(t, apierrors.IsNotFound(err), "expected resource created by the suite to be deleted, got %v", err)
+}
+
+// flakyClient fails the first Creates with the given errors.
+type flakyClient struct {
+	client.Client
+	createErrs []error
+	creates    int
+}
+
+func (c *flakyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.creates++
+	if len(c.createErrs) > 0 {
+		err := c.createErrs[0]
+		c.createErrs = c.createErrs[1:]
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestApplyRetriesTransientErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := &flakyClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		createErrs: []error{
+			apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "suite-config", errors.New("conflict")),
+			fmt.Errorf("discovery: %w", &apimeta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "ConfigMap"}}),
+		},
+	}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: suite-config
+  namespace: test
+`), 4096)
+	a := Applier{RetryBudget: 10 * time.Second}
+	resources, err := a.prepareResources(t, decoder, "test-class")
+	require.NoError(t, err)
+
+	a.mustApplyResources(context.Background(), t, c, resources, false)
+	require.Equal(t, 3, c.creates)
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "suite-config"}, &v1.ConfigMap{}))
+}
+
+func TestIsTransientApplyError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{{
+		name:      "conflict",
+		err:       apierrors.NewConflict(schema.GroupResource{Resource: "gateways"}, "gateway", errors.New("conflict")),
+		transient: true,
+	}, {
+		name:      "kind not registered yet",
+		err:       fmt.Errorf("error creating resource: %w", &apimeta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "gateway.networking.k8s.io", Kind: "Gateway"}}),
+		transient: true,
+	}, {
+		name:      "webhook not available",
+		err:       apierrors.NewInternalError(errors.New(`failed calling webhook "validate.gateway.networking.k8s.io": connection refused`)),
+		transient: true,
+	}, {
+		name:      "invalid resource",
+		err:       fmt.Errorf("error creating resource: %w", apierrors.NewInvalid(schema.GroupKind{Kind: "Gateway"}, "gateway", nil)),
+		transient: false,
+	}, {
+		name:      "other internal error",
+		err:       apierrors.NewInternalError(errors.New("etcd is on fire")),
+		transient: false,
+	}}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.transient, isTransientApplyError(tc.err))
+		})
+	}
+}
+
+func TestApplySubstitutions(t *testing.T) {
+	manifest := bytes.NewBufferString(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: suite-config
+  namespace: {{ .NamespacePrefix }}-infra
+data:
+  gatewayClassName: {{ .GatewayClassName }}
+`)
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	a := Applier{Substitutions: map[string]string{"NamespacePrefix": "downstream"}}
+	data, err := a.renderManifest("manifest.yaml", manifest, "test-class")
+	require.NoError(t, err)
+	resources, err := a.prepareResources(t, yaml.NewYAMLOrJSONDecoder(data, 4096), "test-class")
+	require.NoError(t, err)
+	a.mustApplyResources(context.Background(), t, c, resources, false)
+
+	cm := &v1.ConfigMap{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "downstream-infra", Name: "suite-config"}, cm))
+	require.Equal(t, "test-class", cm.Data["gatewayClassName"])
+}
+
+func TestRenderManifest(t *testing.T) {
+	manifest := "name: {{ .Name }}"
+
+	data, err := Applier{}.renderManifest("manifest.yaml", bytes.NewBufferString(manifest), "test-class")
+	require.NoError(t, err)
+	require.Equal(t, manifest, data.String(), "expected manifests not to be rendered without substitutions")
+
+	a := Applier{Substitutions: map[string]string{"GatewayClassName": "overridden"}}
+	data, err = a.renderManifest("manifest.yaml", bytes.NewBufferString("name: {{ .GatewayClassName }}"), "test-class")
+	require.NoError(t, err)
+	require.Equal(t, "name: overridden", data.String())
+
+	_, err = a.renderManifest("manifest.yaml", bytes.NewBufferString(manifest), "test-class")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error rendering manifest.yaml")
+	require.Contains(t, err.Error(), `map has no entry for key "Name"`)
+}
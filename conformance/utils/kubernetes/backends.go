@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// Backend describes the Pods of a backend tests route requests to, i.e. the
+// Pods with the AppLabel app label in Namespace. By convention, AppLabel is
+// also the name of the Deployment of the Pods, which their names start with.
+type Backend struct {
+	Namespace string
+	AppLabel  string
+}
+
+// String returns the backend as namespace/app.
+func (b Backend) String() string {
+	return b.Namespace + "/" + b.AppLabel
+}
+
+// Serves returns true if the Pod with the provided name and namespace, as
+// reported by the echo server, is one of the Pods of the backend.
+func (b Backend) Serves(namespace, pod string) bool {
+	return namespace == b.Namespace && strings.HasPrefix(pod, b.AppLabel+"-")
+}
+
+// BackendFor returns the backend serving the Pod with the provided name and
+// namespace, if any. The backend with the longest AppLabel wins, so that Pods
+// of foo-v2 aren't attributed to a foo backend.
+func BackendFor(backends []Backend, namespace, pod string) (Backend, bool) {
+	var (
+		found Backend
+		ok    bool
+	)
+	for _, backend := range backends {
+		if backend.Serves(namespace, pod) && len(backend.AppLabel) > len(found.AppLabel) {
+			found, ok = backend, true
+		}
+	}
+	return found, ok
+}
+
+// BackendsMustBeReadyWithContext waits until every backend has at least one
+// Pod and all of its Pods are ready. This will cause the test to halt if the
+// DeploymentsMustBeReady timeout is exceeded or ctx is cancelled, describing
+// the Pods that aren't ready.
+func BackendsMustBeReadyWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, backends []Backend) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.DeploymentsMustBeReady, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		for _, backend := range backends {
+			if err := backendReady(ctx, c, backend); err != nil {
+				t.Logf("%s backend not ready yet: %v", backend, err)
+				return false, nil
+			}
+		}
+		t.Logf("Backends %s ready", formatBackends(backends))
+		return true, nil
+	})
+	if waitErr != nil {
+		require.NoErrorf(t, waitErr, "error waiting for %s backends to be ready, %s", formatBackends(backends), describeNotReadyBackends(c, backends))
+	}
+}
+
+// backendReady returns an error telling why the backend isn't ready, if it
+// isn't.
+func backendReady(ctx context.Context, c client.Reader, backend Backend) error {
+	podList := &v1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(backend.Namespace), client.MatchingLabels{"app": backend.AppLabel}); err != nil {
+		return fmt.Errorf("error listing Pods: %w", err)
+	}
+	if len(podList.Items) == 0 {
+		return fmt.Errorf("no Pods with app label %q", backend.AppLabel)
+	}
+	for _, pod := range podList.Items {
+		if !podReady(pod) {
+			return fmt.Errorf("%s Pod not ready", pod.Name)
+		}
+	}
+	return nil
+}
+
+// podReady returns true if the Pod has a Ready condition with status True.
+func podReady(pod v1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == v1.PodReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// formatBackends formats backends for logs.
+func formatBackends(backends []Backend) string {
+	names := make([]string, 0, len(backends))
+	for _, backend := range backends {
+		names = append(names, backend.String())
+	}
+	return strings.Join(names, ", ")
+}
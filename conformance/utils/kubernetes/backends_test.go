@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+func TestBackendFor(t *testing.T) {
+	backends := []Backend{
+		{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend"},
+		{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v2"},
+		{Namespace: "gateway-conformance-web-backend", AppLabel: "web-backend"},
+	}
+
+	backend, ok := BackendFor(backends, "gateway-conformance-infra", "infra-backend-v2-7f9c8d6b5-x2x9z")
+	require.True(t, ok)
+	require.Equal(t, backends[1], backend, "expected the backend with the longest matching label")
+
+	backend, ok = BackendFor(backends, "gateway-conformance-infra", "infra-backend-7f9c8d6b5-x2x9z")
+	require.True(t, ok)
+	require.Equal(t, backends[0], backend)
+
+	_, ok = BackendFor(backends, "gateway-conformance-infra", "web-backend-7f9c8d6b5-x2x9z")
+	require.False(t, ok, "expected backends to be matched in their namespace only")
+	_, ok = BackendFor(backends, "gateway-conformance-web-backend", "web-backendv2-7f9c8d6b5-x2x9z")
+	require.False(t, ok, "expected labels to match whole name segments")
+}
+
+func TestBackendsMustBeReady(t *testing.T) {
+	newPod := func(name, app string, ready v1.ConditionStatus) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "gateway-conformance-infra", Labels: map[string]string{"app": app}},
+			Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: ready}}},
+		}
+	}
+	c := newFakeClient(t,
+		newPod("infra-backend-v1-a", "infra-backend-v1", v1.ConditionTrue),
+		newPod("infra-backend-v2-a", "infra-backend-v2", v1.ConditionTrue),
+		newPod("infra-backend-v2-b", "infra-backend-v2", v1.ConditionFalse),
+	)
+	ctx := context.Background()
+
+	require.NoError(t, backendReady(ctx, c, Backend{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v1"}))
+	require.EqualError(t, backendReady(ctx, c, Backend{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v2"}), "infra-backend-v2-b Pod not ready")
+	require.EqualError(t, backendReady(ctx, c, Backend{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v3"}), `no Pods with app label "infra-backend-v3"`)
+
+	timeoutConfig := config.TimeoutConfig{PollInterval: 10 * time.Millisecond, DeploymentsMustBeReady: time.Second}
+	BackendsMustBeReadyWithContext(ctx, t, c, timeoutConfig, []Backend{{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v1"}})
+}
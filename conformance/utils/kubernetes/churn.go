@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// ChurnLabel is set on every HTTPRoute created by HTTPRouteChurnMustBeStable,
+// with the name of the template route as value.
+const ChurnLabel = "gateway-api.sigs.k8s.io/conformance-churn"
+
+// ChurnConfig configures HTTPRouteChurnMustBeStable.
+type ChurnConfig struct {
+	// Duration is how long routes are churned for.
+	Duration time.Duration
+	// MaxIterations optionally limits the number of routes created and
+	// deleted, regardless of Duration.
+	MaxIterations int
+	// MaxConsecutiveProbeFailures is the number of probes in a row that may
+	// fail before the errors are considered sustained and the test fails.
+	MaxConsecutiveProbeFailures int
+}
+
+// HTTPRouteChurnMustBeStable repeatedly creates and deletes copies of the
+// template HTTPRoute, calling probe after every iteration to verify that
+// traffic still flows. Probes may fail occasionally, but not more than
+// MaxConsecutiveProbeFailures times in a row. Once churning is done, every
+// churned route must eventually be gone and probe must succeed. This will
+// cause the test to halt if the specified timeout is exceeded.
+func HTTPRouteChurnMustBeStable(t *testing.T, c client.Client, template *v1alpha2.HTTPRoute, config ChurnConfig, probe func() error, seconds int) {
+	t.Helper()
+
+	deadline := time.Now().Add(config.Duration)
+	iterations, consecutiveFailures := 0, 0
+	for time.Now().Before(deadline) && (config.MaxIterations == 0 || iterations < config.MaxIterations) {
+		route := template.DeepCopy()
+		route.ResourceVersion = ""
+		route.Name = fmt.Sprintf("%s-churn-%d", template.Name, iterations)
+		if route.Labels == nil {
+			route.Labels = map[string]string{}
+		}
+		route.Labels[ChurnLabel] = template.Name
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := c.Create(ctx, route)
+		require.NoErrorf(t, err, "error creating %s HTTPRoute", route.Name)
+		err = c.Delete(ctx, route)
+		cancel()
+		require.NoErrorf(t, err, "error deleting %s HTTPRoute", route.Name)
+		iterations++
+
+		if err = probe(); err != nil {
+			consecutiveFailures++
+			t.Logf("Probe failed after %d churn iterations: %v", iterations, err)
+			require.LessOrEqualf(t, consecutiveFailures, config.MaxConsecutiveProbeFailures, "probe failed %d times in a row while churning HTTPRoutes", consecutiveFailures)
+			continue
+		}
+		consecutiveFailures = 0
+	}
+	t.Logf("Churned %d HTTPRoutes from %s template", iterations, template.Name)
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		routes := &v1alpha2.HTTPRouteList{}
+		err := c.List(ctx, routes, client.InNamespace(template.Namespace), client.MatchingLabels{ChurnLabel: template.Name})
+		if err != nil {
+			return false, fmt.Errorf("error listing HTTPRoutes: %w", err)
+		}
+		if len(routes.Items) > 0 {
+			t.Logf("%d churned HTTPRoutes still exist", len(routes.Items))
+			return false, nil
+		}
+
+		if err = probe(); err != nil {
+			t.Logf("Probe failed after churning HTTPRoutes: %v", err)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for state to converge after churning HTTPRoutes")
+}
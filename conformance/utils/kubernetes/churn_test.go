@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestHTTPRouteChurnMustBeStable(t *testing.T) {
+	c := newFakeClient(t)
+	template := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "churn", Namespace: "gateway-conformance-infra"},
+	}
+
+	// Every third probe fails, which is below the tolerated number of
+	// consecutive failures.
+	probes := 0
+	probe := func() error {
+		probes++
+		if probes%3 == 0 {
+			return errors.New("connection reset")
+		}
+		return nil
+	}
+
+	HTTPRouteChurnMustBeStable(t, c, template, ChurnConfig{
+		Duration:                    time.Minute,
+		MaxIterations:               4,
+		MaxConsecutiveProbeFailures: 1,
+	}, probe, 5)
+
+	// 4 probes while churning and a final one once routes are gone.
+	require.Equal(t, 5, probes)
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deletionRanks orders the deletion of resources on cleanup: routes go before
+// the Gateways they are attached to, which go before their GatewayClass.
+// Namespaces go last, as deleting them deletes everything in them. Other
+// kinds have rank 1.
+var deletionRanks = map[string]int{
+	"HTTPRoute":    0,
+	"TLSRoute":     0,
+	"TCPRoute":     0,
+	"UDPRoute":     0,
+	"GRPCRoute":    0,
+	"Gateway":      2,
+	"GatewayClass": 3,
+	"Namespace":    4,
+}
+
+// deletionRank returns the rank of the resource in deletionRanks.
+func deletionRank(uObj *unstructured.Unstructured) int {
+	if rank, ok := deletionRanks[uObj.GetKind()]; ok {
+		return rank
+	}
+	return 1
+}
+
+// inDeletionOrder returns the resources, in the order they were applied, in
+// the order they must be deleted: by deletionRank, and then most recently
+// applied first.
+func inDeletionOrder(resources []*unstructured.Unstructured) []*unstructured.Unstructured {
+	ordered := make([]*unstructured.Unstructured, 0, len(resources))
+	for i := len(resources) - 1; i >= 0; i-- {
+		ordered = append(ordered, resources[i])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return deletionRank(ordered[i]) < deletionRank(ordered[j])
+	})
+	return ordered
+}
+
+// deleteResources deletes the resources in deletion order, unless the test
+// failed and RetainOnFailure is set. If DeletionTimeout is set, every
+// resource is waited for to be gone before the next one is deleted.
+func (a Applier) deleteResources(t *testing.T, c client.Client, resources []*unstructured.Unstructured) {
+	if a.RetainOnFailure && t.Failed() {
+		for _, uObj := range resources {
+			t.Logf("Retaining %s %s in namespace %q for inspection since the test failed", uObj.GetName(), uObj.GetKind(), uObj.GetNamespace())
+		}
+		return
+	}
+
+	for _, uObj := range inDeletionOrder(resources) {
+		if a.Tracker != nil {
+			a.Tracker.cleanedUp(uObj)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		t.Logf("Deleting %s %s", uObj.GetName(), uObj.GetKind())
+		err := c.Delete(ctx, uObj)
+		cancel()
+		if apierrors.IsNotFound(err) {
+			// Already deleted, e.g. by the test itself.
+			continue
+		}
+		if err != nil {
+			t.Errorf("error deleting %s %s: %v", uObj.GetName(), uObj.GetKind(), err)
+			continue
+		}
+		if a.DeletionTimeout > 0 {
+			waitForDeletion(t, c, uObj, a.DeletionTimeout)
+		}
+	}
+}
+
+// waitForDeletion waits until the resource is gone. If it's still there once
+// timeout elapses, e.g. because a finalizer never cleared, it is logged along
+// with its finalizers rather than failing the test.
+func waitForDeletion(t *testing.T, c client.Client, uObj *unstructured.Unstructured, timeout time.Duration) {
+	nn := types.NamespacedName{Namespace: uObj.GetNamespace(), Name: uObj.GetName()}
+	fetched := &unstructured.Unstructured{}
+	fetched.SetGroupVersionKind(uObj.GroupVersionKind())
+
+	waitErr := wait.PollImmediate(deletionPollInterval, timeout, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		err := c.Get(ctx, nn, fetched)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	})
+	if waitErr != nil {
+		t.Logf("%s %s in namespace %q still exists %s after being deleted, finalizers: %v", uObj.GetName(), uObj.GetKind(), uObj.GetNamespace(), timeout, fetched.GetFinalizers())
+	}
+}
+
+// deletionPollInterval is how long waitForDeletion waits between two checks.
+var deletionPollInterval = time.Second
@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// deleteRecordingClient records the kinds of the resources it deletes, in
+// order.
+type deleteRecordingClient struct {
+	client.Client
+	deleted []string
+}
+
+func (c *deleteRecordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deleted = append(c.deleted, obj.GetObjectKind().GroupVersionKind().Kind)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestInDeletionOrder(t *testing.T) {
+	var resources []*unstructured.Unstructured
+	for _, kind := range []string{"Namespace", "GatewayClass", "Gateway", "Service", "HTTPRoute", "Deployment", "TCPRoute"} {
+		uObj := &unstructured.Unstructured{}
+		uObj.SetKind(kind)
+		resources = append(resources, uObj)
+	}
+
+	var kinds []string
+	for _, uObj := range inDeletionOrder(resources) {
+		kinds = append(kinds, uObj.GetKind())
+	}
+	require.Equal(t, []string{"TCPRoute", "HTTPRoute", "Deployment", "Service", "Gateway", "GatewayClass", "Namespace"}, kinds)
+}
+
+func TestDeleteResourcesWaitsForDeletion(t *testing.T) {
+	defer func(interval time.Duration) { deletionPollInterval = interval }(deletionPollInterval)
+	deletionPollInterval = 10 * time.Millisecond
+
+	c := &deleteRecordingClient{Client: newFakeClient(t)}
+	applier := Applier{DeletionTimeout: 200 * time.Millisecond}
+	resources, err := applier.prepareResources(t, yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: GatewayClass
+metadata:
+  name: test-class
+spec:
+  controllerName: example.com/gateway-controller
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: Gateway
+metadata:
+  name: finalized
+  namespace: gateway-conformance-infra
+  finalizers:
+  - example.com/gateway-controller
+spec:
+  gatewayClassName: test-class
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: route
+  namespace: gateway-conformance-infra
+spec:
+  parentRefs:
+  - name: finalized
+`), 4096), "test-class")
+	require.NoError(t, err)
+
+	passed := t.Run("apply", func(t *testing.T) {
+		applier.mustApplyResources(context.Background(), t, c, resources, true)
+	})
+	require.True(t, passed, "expected a stuck finalizer not to fail the test")
+	require.Equal(t, []string{"HTTPRoute", "Gateway", "GatewayClass"}, c.deleted)
+
+	gw := &v1alpha2.Gateway{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "finalized", Namespace: "gateway-conformance-infra"}, gw))
+	require.NotNil(t, gw.DeletionTimestamp, "expected the Gateway to be stuck being deleted")
+
+	err = c.Get(context.Background(), types.NamespacedName{Name: "route", Namespace: "gateway-conformance-infra"}, &v1alpha2.HTTPRoute{})
+	require.Error(t, err, "expected the HTTPRoute to be gone")
+}
+
+func TestDeleteResourcesIgnoresDeletedResources(t *testing.T) {
+	c := newFakeClient(t)
+	applier := Applier{}
+	resources, err := applier.prepareResources(t, yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: route
+  namespace: gateway-conformance-infra
+spec:
+  parentRefs:
+  - name: same-namespace
+`), 4096), "test-class")
+	require.NoError(t, err)
+
+	passed := t.Run("apply", func(t *testing.T) {
+		applier.mustApplyResources(context.Background(), t, c, resources, true)
+		route := &v1alpha2.HTTPRoute{}
+		require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "route", Namespace: "gateway-conformance-infra"}, route))
+		require.NoError(t, c.Delete(context.Background(), route))
+	})
+	require.True(t, passed, "expected deleting a resource that is already gone not to fail the test")
+}
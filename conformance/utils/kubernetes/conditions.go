@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// RouteMustHaveCondition waits for the route routeNN to have a condition
+// matching the expected type, status and, if set, reason in the status of the
+// parent referenced by parentRef. The route is fetched into the provided
+// object, which must be an HTTPRoute, TLSRoute, TCPRoute or UDPRoute. Since
+// a route may be attached to several sections of the same Gateway, the
+// parentRef must match the one in the status exactly, with its group, kind
+// and namespace defaulted. This will cause the test to halt if the specified
+// timeout is exceeded, logging the conditions last reported for the parent.
+func RouteMustHaveCondition(t *testing.T, c client.Reader, route client.Object, routeNN types.NamespacedName, parentRef v1alpha2.ParentReference, expected metav1.Condition, seconds int) {
+	t.Helper()
+
+	var conditions []metav1.Condition
+	waitErr := wait.PollImmediate(1*time.Second, time.Duration(seconds)*time.Second, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := c.Get(ctx, routeNN, route); err != nil {
+			return false, fmt.Errorf("error fetching route: %w", err)
+		}
+		parents, ok := routeParents(route)
+		if !ok {
+			return false, fmt.Errorf("unsupported route type %T", route)
+		}
+
+		for _, parent := range parents {
+			if parentRefEqual(parent.ParentRef, parentRef, routeNN.Namespace) {
+				conditions = parent.Conditions
+				return findConditionWithReason(t, conditions, expected), nil
+			}
+		}
+		conditions = nil
+		t.Logf("Route %s has no status for parent %s yet", routeNN, formatParentRef(parentRef, routeNN.Namespace))
+		return false, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for route %s to have %s condition set to %s for parent %s, current conditions: %s",
+		routeNN, expected.Type, expected.Status, formatParentRef(parentRef, routeNN.Namespace), formatConditions(conditions))
+}
+
+// GatewayMustHaveCondition waits for the Gateway gwNN to have a condition
+// matching the expected type, status and, if set, reason. This will cause the
+// test to halt if the specified timeout is exceeded, logging the conditions
+// last reported for the Gateway.
+func GatewayMustHaveCondition(t *testing.T, c client.Reader, gwNN types.NamespacedName, expected metav1.Condition, seconds int) {
+	t.Helper()
+
+	var conditions []metav1.Condition
+	waitErr := wait.PollImmediate(1*time.Second, time.Duration(seconds)*time.Second, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		conditions = gw.Status.Conditions
+		return findConditionWithReason(t, conditions, expected), nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for Gateway %s to have %s condition set to %s, current conditions: %s",
+		gwNN, expected.Type, expected.Status, formatConditions(conditions))
+}
+
+// GatewayClassMustHaveCondition waits for the GatewayClass gwcName to have a
+// condition matching the expected type, status and, if set, reason. This will
+// cause the test to halt if the specified timeout is exceeded, logging the
+// conditions last reported for the GatewayClass.
+func GatewayClassMustHaveCondition(t *testing.T, c client.Reader, gwcName string, expected metav1.Condition, seconds int) {
+	t.Helper()
+
+	var conditions []metav1.Condition
+	waitErr := wait.PollImmediate(1*time.Second, time.Duration(seconds)*time.Second, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gwc := &v1alpha2.GatewayClass{}
+		if err := c.Get(ctx, types.NamespacedName{Name: gwcName}, gwc); err != nil {
+			return false, fmt.Errorf("error fetching GatewayClass: %w", err)
+		}
+		conditions = gwc.Status.Conditions
+		return findConditionWithReason(t, conditions, expected), nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for GatewayClass %s to have %s condition set to %s, current conditions: %s",
+		gwcName, expected.Type, expected.Status, formatConditions(conditions))
+}
+
+// parentRefEqual returns true if both ParentReferences refer to the same
+// parent and section, defaulting them as the API does.
+func parentRefEqual(actual, expected v1alpha2.ParentReference, routeNamespace string) bool {
+	return formatParentRef(actual, routeNamespace) == formatParentRef(expected, routeNamespace)
+}
+
+// formatParentRef formats the defaulted ParentReference as
+// group/kind/namespace/name, followed by the section name if set.
+func formatParentRef(ref v1alpha2.ParentReference, routeNamespace string) string {
+	group, kind, namespace := v1alpha2.GroupName, "Gateway", routeNamespace
+	if ref.Group != nil {
+		group = string(*ref.Group)
+	}
+	if ref.Kind != nil {
+		kind = string(*ref.Kind)
+	}
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+
+	formatted := fmt.Sprintf("%s/%s/%s/%s", group, kind, namespace, ref.Name)
+	if ref.SectionName != nil {
+		formatted += "#" + string(*ref.SectionName)
+	}
+	return formatted
+}
+
+// formatConditions formats the conditions for error messages.
+func formatConditions(conditions []metav1.Condition) string {
+	if len(conditions) == 0 {
+		return "none"
+	}
+	formatted := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		formatted = append(formatted, fmt.Sprintf("%s=%s (reason: %s, message: %q)", cond.Type, cond.Status, cond.Reason, cond.Message))
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestRouteMustHaveCondition(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	http, https := v1alpha2.SectionName("http"), v1alpha2.SectionName("https")
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing-backend", Namespace: ns},
+		Status: v1alpha2.HTTPRouteStatus{
+			RouteStatus: v1alpha2.RouteStatus{
+				Parents: []v1alpha2.RouteParentStatus{{
+					ParentRef:      v1alpha2.ParentReference{Name: "same-namespace", SectionName: &http},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionResolvedRefs),
+						Status: metav1.ConditionFalse,
+						Reason: "BackendNotFound",
+					}},
+				}, {
+					ParentRef:      v1alpha2.ParentReference{Name: "same-namespace", SectionName: &https},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionResolvedRefs),
+						Status: metav1.ConditionTrue,
+						Reason: "ResolvedRefs",
+					}},
+				}},
+			},
+		},
+	}
+	c := newFakeClient(t, route)
+	routeNN := types.NamespacedName{Name: "missing-backend", Namespace: ns}
+
+	group, kind, namespace := v1alpha2.Group(v1alpha2.GroupName), v1alpha2.Kind("Gateway"), v1alpha2.Namespace(ns)
+	RouteMustHaveCondition(t, c, &v1alpha2.HTTPRoute{}, routeNN, v1alpha2.ParentReference{
+		Group:       &group,
+		Kind:        &kind,
+		Namespace:   &namespace,
+		Name:        "same-namespace",
+		SectionName: &http,
+	}, metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionResolvedRefs),
+		Status: metav1.ConditionFalse,
+		Reason: "BackendNotFound",
+	}, 5)
+	RouteMustHaveCondition(t, c, &v1alpha2.HTTPRoute{}, routeNN, v1alpha2.ParentReference{Name: "same-namespace", SectionName: &https}, metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionResolvedRefs),
+		Status: metav1.ConditionTrue,
+	}, 5)
+}
+
+func TestGatewayAndGatewayClassMustHaveCondition(t *testing.T) {
+	gwc := &v1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance"},
+		Status: v1alpha2.GatewayClassStatus{
+			Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue, Reason: "Accepted"}},
+		},
+	}
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "same-namespace", Namespace: "gateway-conformance-infra"},
+		Status: v1alpha2.GatewayStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionFalse, Reason: "AddressNotAssigned"}},
+		},
+	}
+	c := newFakeClient(t, gwc, gw)
+
+	GatewayClassMustHaveCondition(t, c, "gateway-conformance", metav1.Condition{Type: "Accepted", Status: metav1.ConditionTrue}, 5)
+	GatewayMustHaveCondition(t, c, types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}, metav1.Condition{
+		Type:   "Ready",
+		Status: metav1.ConditionFalse,
+		Reason: "AddressNotAssigned",
+	}, 5)
+}
+
+func TestFormatConditions(t *testing.T) {
+	require.Equal(t, "none", formatConditions(nil))
+	require.Equal(t, `Accepted=False (reason: NotAllowedByListeners, message: "no matching listener"), ResolvedRefs=True (reason: ResolvedRefs, message: "")`, formatConditions([]metav1.Condition{
+		{Type: "Accepted", Status: metav1.ConditionFalse, Reason: "NotAllowedByListeners", Message: "no matching listener"},
+		{Type: "ResolvedRefs", Status: metav1.ConditionTrue, Reason: "ResolvedRefs"},
+	}))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// HTTPRouteConflictingUpdatesMustConverge concurrently applies each of the
+// provided mutations to the same revision of the specified HTTPRoute. Since
+// every update carries the same resourceVersion, exactly one of them must be
+// written while the others must be rejected with a conflict. The route must
+// then keep the spec of that last successful writer, and probe, called with
+// the index of the winning mutation, must eventually succeed to verify that
+// routing converged to it. The index of the winning mutation is returned. This
+// will cause the test to halt if the specified timeout is exceeded.
+func HTTPRouteConflictingUpdatesMustConverge(t *testing.T, c client.Client, routeNN types.NamespacedName, mutations []func(*v1alpha2.HTTPRoute), probe func(winner int) error, seconds int) int {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	base := &v1alpha2.HTTPRoute{}
+	require.NoErrorf(t, c.Get(ctx, routeNN, base), "error fetching HTTPRoute %s", routeNN)
+
+	updated := make([]*v1alpha2.HTTPRoute, len(mutations))
+	errs := make([]error, len(mutations))
+	var wg sync.WaitGroup
+	for i := range mutations {
+		i := i
+		updated[i] = base.DeepCopy()
+		mutations[i](updated[i])
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.Update(ctx, updated[i])
+		}()
+	}
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			require.Equalf(t, -1, winner, "expected a single update of HTTPRoute %s to succeed, updates %d and %d both did", routeNN, winner, i)
+			winner = i
+		case apierrors.IsConflict(err):
+			t.Logf("Update %d of HTTPRoute %s was rejected with a conflict", i, routeNN)
+		default:
+			require.NoErrorf(t, err, "error applying update %d to HTTPRoute %s", i, routeNN)
+		}
+	}
+	require.NotEqualf(t, -1, winner, "expected one update of HTTPRoute %s to succeed", routeNN)
+	t.Logf("Update %d of HTTPRoute %s won", winner, routeNN)
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		route := &v1alpha2.HTTPRoute{}
+		if err := c.Get(ctx, routeNN, route); err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+		if !apiequality.Semantic.DeepEqual(updated[winner].Spec, route.Spec) {
+			return false, fmt.Errorf("expected HTTPRoute %s to keep the spec of update %d", routeNN, winner)
+		}
+
+		if err := probe(winner); err != nil {
+			t.Logf("Routing has not converged to update %d yet: %v", winner, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for routing to converge after conflicting updates of HTTPRoute %s", routeNN)
+	return winner
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestHTTPRouteConflictingUpdatesMustConverge(t *testing.T) {
+	routeNN := types.NamespacedName{Name: "conflicting", Namespace: "gateway-conformance-infra"}
+	withBackend := func(name string) func(*v1alpha2.HTTPRoute) {
+		return func(route *v1alpha2.HTTPRoute) {
+			route.Spec.Rules = []v1alpha2.HTTPRouteRule{{
+				BackendRefs: []v1alpha2.HTTPBackendRef{{
+					BackendRef: v1alpha2.BackendRef{
+						BackendObjectReference: v1alpha2.BackendObjectReference{Name: v1alpha2.ObjectName(name)},
+					},
+				}},
+			}}
+		}
+	}
+	route := &v1alpha2.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: routeNN.Name, Namespace: routeNN.Namespace}}
+	withBackend("infra-backend-v1")(route)
+	c := newFakeClient(t, route)
+
+	backends := []string{"infra-backend-v2", "infra-backend-v3", "app-backend-v1"}
+	mutations := make([]func(*v1alpha2.HTTPRoute), len(backends))
+	for i, backend := range backends {
+		mutations[i] = withBackend(backend)
+	}
+
+	// The fake Gateway routes to the backend of the stored HTTPRoute.
+	probe := func(winner int) error {
+		route := &v1alpha2.HTTPRoute{}
+		if err := c.Get(context.Background(), routeNN, route); err != nil {
+			return err
+		}
+		if got := string(route.Spec.Rules[0].BackendRefs[0].Name); got != backends[winner] {
+			return fmt.Errorf("expected traffic to be routed to %s, got %s", backends[winner], got)
+		}
+		return nil
+	}
+
+	winner := HTTPRouteConflictingUpdatesMustConverge(t, c, routeNN, mutations, probe, 5)
+	require.NoError(t, probe(winner))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// HTTPRouteDeletionMustDetach deletes the specified HTTPRoute, which must be
+// attached to the listener of the Gateway gwNN, and waits for the
+// AttachedRoutes of the listener to drop by one and for probe to succeed: it
+// is expected to verify requests previously matching the route aren't routed
+// anymore, e.g. get a 404. The route may have been applied with cleanup,
+// which ignores it once deleted. This will cause the test to halt if the
+// ResourcesMustBeDeleted timeout is exceeded.
+func HTTPRouteDeletionMustDetach(t *testing.T, c client.Client, timeoutConfig config.TimeoutConfig, routeNN, gwNN types.NamespacedName, listenerName string, probe func() error) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	before, err := listenerAttachedRoutes(ctx, c, gwNN, listenerName)
+	require.NoErrorf(t, err, "error fetching AttachedRoutes of %s listener of %s Gateway", listenerName, gwNN)
+	require.Positivef(t, before, "expected HTTPRoute %s to be attached to %s listener of %s Gateway", routeNN, listenerName, gwNN)
+
+	route := &v1alpha2.HTTPRoute{}
+	require.NoErrorf(t, c.Get(ctx, routeNN, route), "error fetching HTTPRoute %s", routeNN)
+	require.NoErrorf(t, c.Delete(ctx, route), "error deleting HTTPRoute %s", routeNN)
+
+	waitErr := wait.PollImmediate(timeoutConfig.PollInterval, timeoutConfig.ResourcesMustBeDeleted, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := c.Get(ctx, routeNN, &v1alpha2.HTTPRoute{}); !apierrors.IsNotFound(err) {
+			t.Logf("HTTPRoute %s not deleted yet: %v", routeNN, err)
+			return false, nil
+		}
+
+		attached, err := listenerAttachedRoutes(ctx, c, gwNN, listenerName)
+		if err != nil {
+			return false, err
+		}
+		if attached != before-1 {
+			t.Logf("Expected %s listener of %s Gateway to have %d attached routes, got %d", listenerName, gwNN, before-1, attached)
+			return false, nil
+		}
+
+		if err := probe(); err != nil {
+			t.Logf("Traffic to deleted HTTPRoute %s hasn't stopped yet: %v", routeNN, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for deleted HTTPRoute %s to be detached from %s Gateway", routeNN, gwNN)
+}
+
+// listenerAttachedRoutes returns the AttachedRoutes of the listener of the
+// Gateway gwNN.
+func listenerAttachedRoutes(ctx context.Context, c client.Reader, gwNN types.NamespacedName, listenerName string) (int32, error) {
+	gw := &v1alpha2.Gateway{}
+	if err := c.Get(ctx, gwNN, gw); err != nil {
+		return 0, fmt.Errorf("error fetching Gateway: %w", err)
+	}
+	for _, listener := range gw.Status.Listeners {
+		if string(listener.Name) == listenerName {
+			return listener.AttachedRoutes, nil
+		}
+	}
+	return 0, fmt.Errorf("%s Gateway has no status for %s listener", gwNN, listenerName)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// detachingClient stands in for a Gateway controller decrementing the
+// AttachedRoutes of the http listener of the gwNN Gateway when an HTTPRoute
+// is deleted.
+type detachingClient struct {
+	client.Client
+	gwNN types.NamespacedName
+}
+
+func (c *detachingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if _, ok := obj.(*v1alpha2.HTTPRoute); !ok {
+		return nil
+	}
+
+	gw := &v1alpha2.Gateway{}
+	if err := c.Client.Get(ctx, c.gwNN, gw); err != nil {
+		return err
+	}
+	gw.Status.Listeners[0].AttachedRoutes--
+	return c.Client.Update(ctx, gw)
+}
+
+func TestHTTPRouteDeletionMustDetach(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
+	routeNN := types.NamespacedName{Name: "deleted", Namespace: ns}
+	c := &detachingClient{
+		Client: newFakeClient(t,
+			&v1alpha2.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+				Status: v1alpha2.GatewayStatus{
+					Listeners: []v1alpha2.ListenerStatus{{Name: "http", AttachedRoutes: 2}},
+				},
+			},
+			&v1alpha2.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: routeNN.Name, Namespace: ns}},
+		),
+		gwNN: gwNN,
+	}
+
+	// probe stands in for a request matching the deleted route, which the
+	// Gateway answers with a 404 once its configuration is updated.
+	var probes int
+	probe := func() error {
+		probes++
+		if probes < 2 {
+			return errors.New("expected status 404, got 200")
+		}
+		return nil
+	}
+
+	timeoutConfig := config.TimeoutConfig{PollInterval: 10 * time.Millisecond, ResourcesMustBeDeleted: 5 * time.Second}
+	HTTPRouteDeletionMustDetach(t, c, timeoutConfig, routeNN, gwNN, "http", probe)
+	require.Equal(t, 2, probes, "expected probes to be retried until traffic stops")
+
+	gw := &v1alpha2.Gateway{}
+	require.NoError(t, c.Get(context.Background(), gwNN, gw))
+	require.EqualValues(t, 1, gw.Status.Listeners[0].AttachedRoutes)
+}
+
+func TestHTTPRouteDeletionMustDetachAppliedWithCleanup(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
+	c := &detachingClient{
+		Client: newFakeClient(t, &v1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+			Status: v1alpha2.GatewayStatus{
+				Listeners: []v1alpha2.ListenerStatus{{Name: "http", AttachedRoutes: 1}},
+			},
+		}),
+		gwNN: gwNN,
+	}
+
+	applier := Applier{}
+	resources, err := applier.prepareResources(t, yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: deleted
+  namespace: gateway-conformance-infra
+spec:
+  parentRefs:
+  - name: same-namespace
+`), 4096), "test-class")
+	require.NoError(t, err)
+
+	timeoutConfig := config.TimeoutConfig{PollInterval: 10 * time.Millisecond, ResourcesMustBeDeleted: 5 * time.Second}
+	passed := t.Run("delete", func(t *testing.T) {
+		applier.mustApplyResources(context.Background(), t, c, resources, true)
+		HTTPRouteDeletionMustDetach(t, c, timeoutConfig, types.NamespacedName{Name: "deleted", Namespace: ns}, gwNN, "http", func() error { return nil })
+	})
+	require.True(t, passed, "expected the cleanup of the deleted HTTPRoute not to fail the test")
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// serverManagedFields lists the fields set by the apiserver on every object,
+// which are left out of the diff between submitted and stored objects.
+var serverManagedFields = [][]string{
+	{"metadata", "resourceVersion"},
+	{"metadata", "uid"},
+	{"metadata", "generation"},
+	{"metadata", "creationTimestamp"},
+	{"metadata", "deletionTimestamp"},
+	{"metadata", "managedFields"},
+	{"metadata", "selfLink"},
+	{"status"},
+}
+
+// logAppliedDiff re-reads the applied object and logs how the object stored
+// by the apiserver differs from the submitted one, e.g. because of defaults
+// or mutating admission webhooks. Failing to read the object is only logged.
+func logAppliedDiff(ctx context.Context, t *testing.T, c client.Client, submitted *unstructured.Unstructured) {
+	t.Helper()
+
+	stored := &unstructured.Unstructured{}
+	stored.SetGroupVersionKind(submitted.GroupVersionKind())
+	namespacedName := types.NamespacedName{Namespace: submitted.GetNamespace(), Name: submitted.GetName()}
+	if err := c.Get(ctx, namespacedName, stored); err != nil {
+		t.Logf("Unable to read back %s %s to diff it: %v", submitted.GetName(), submitted.GetKind(), err)
+		return
+	}
+
+	diff := diffApplied(submitted, stored)
+	if len(diff) == 0 {
+		t.Logf("%s %s was stored as submitted", submitted.GetName(), submitted.GetKind())
+		return
+	}
+	t.Logf("%s %s was stored with differences from the submitted object:\n  %s", submitted.GetName(), submitted.GetKind(), strings.Join(diff, "\n  "))
+}
+
+// diffApplied returns a line for each field that differs between the
+// submitted and the stored object, sorted by path, ignoring the
+// serverManagedFields.
+func diffApplied(submitted, stored *unstructured.Unstructured) []string {
+	submittedContent := withoutServerManagedFields(submitted)
+	storedContent := withoutServerManagedFields(stored)
+
+	var diff []string
+	diffValues("", submittedContent, storedContent, &diff)
+	sort.Strings(diff)
+	return diff
+}
+
+// withoutServerManagedFields returns a copy of the content of the object
+// without the serverManagedFields.
+func withoutServerManagedFields(uObj *unstructured.Unstructured) map[string]interface{} {
+	content := uObj.DeepCopy().UnstructuredContent()
+	for _, fields := range serverManagedFields {
+		unstructured.RemoveNestedField(content, fields...)
+	}
+	return content
+}
+
+// diffValues appends the differences between the submitted and the stored
+// value at path to diff, descending into the maps found in both.
+func diffValues(path string, submitted, stored interface{}, diff *[]string) {
+	submittedMap, submittedIsMap := submitted.(map[string]interface{})
+	storedMap, storedIsMap := stored.(map[string]interface{})
+	if !submittedIsMap || !storedIsMap {
+		if !reflect.DeepEqual(submitted, stored) {
+			*diff = append(*diff, fmt.Sprintf("%s: submitted %v, stored %v", path, submitted, stored))
+		}
+		return
+	}
+
+	for key, submittedValue := range submittedMap {
+		storedValue, ok := storedMap[key]
+		if !ok {
+			*diff = append(*diff, fmt.Sprintf("%s: submitted %v, not stored", joinPath(path, key), submittedValue))
+			continue
+		}
+		diffValues(joinPath(path, key), submittedValue, storedValue, diff)
+	}
+	for key, storedValue := range storedMap {
+		if _, ok := submittedMap[key]; !ok {
+			*diff = append(*diff, fmt.Sprintf("%s: not submitted, stored %v", joinPath(path, key), storedValue))
+		}
+	}
+}
+
+// joinPath returns the path of the key of the map at path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
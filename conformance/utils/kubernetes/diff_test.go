@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mutatingClient mutates the ConfigMaps it creates, as a mutating admission
+// webhook would.
+type mutatingClient struct {
+	client.Client
+}
+
+func (c *mutatingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if u, ok := obj.(*unstructured.Unstructured); ok && u.GetKind() == "ConfigMap" {
+		if err := unstructured.SetNestedField(u.Object, "injected", "data", "sidecar"); err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(u.Object, "rewritten", "data", "mode"); err != nil {
+			return err
+		}
+		unstructured.RemoveNestedField(u.Object, "data", "dropped")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestDiffApplied(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := &mutatingClient{Client: fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: suite-config
+  namespace: test
+data:
+  mode: original
+  dropped: value
+  kept: value
+`), 4096)
+	a := Applier{DiffApplied: true}
+	resources, err := a.prepareResources(t, decoder, "test-class")
+	require.NoError(t, err)
+	submitted := resources[0].DeepCopy()
+	setOwned(submitted)
+
+	a.mustApplyResources(context.Background(), t, c, resources, false)
+
+	stored := &unstructured.Unstructured{}
+	stored.SetGroupVersionKind(submitted.GroupVersionKind())
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "test", Name: "suite-config"}, stored))
+	require.NotEmpty(t, stored.GetResourceVersion())
+
+	// The server-managed fields, set on the stored object only, and the
+	// OwnedLabel set by the Applier don't show up in the diff.
+
+	require.Equal(t, []string{
+		"data.dropped: submitted value, not stored",
+		"data.mode: submitted original, stored rewritten",
+		"data.sidecar: not submitted, stored injected",
+	}, diffApplied(submitted, stored))
+
+}
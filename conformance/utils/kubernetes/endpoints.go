@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// HTTPRouteToServiceWithoutEndpointsMustBeUnavailable creates the provided
+// HTTPRoute, whose backendRefs must all refer to Services without any ready
+// endpoint, and deletes it once the test is done. The route must be attached
+// to the gwNN Gateway with a ResolvedRefs condition set to True, as the
+// Services exist, and probe must succeed: it is expected to verify requests
+// get a 503 rather than a 404, since the route matches them. This will cause
+// the test to halt if the specified timeout is exceeded.
+func HTTPRouteToServiceWithoutEndpointsMustBeUnavailable(t *testing.T, c client.Client, route *v1alpha2.HTTPRoute, gwNN types.NamespacedName, probe func() error, seconds int) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, rule := range route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			serviceNN := types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}
+			if ref.Namespace != nil {
+				serviceNN.Namespace = string(*ref.Namespace)
+			}
+			require.NoErrorf(t, c.Get(ctx, serviceNN, &v1.Service{}), "error fetching %s Service", serviceNN)
+			ready, err := readyEndpoints(ctx, c, serviceNN)
+			require.NoErrorf(t, err, "error fetching %s Endpoints", serviceNN)
+			require.Zerof(t, ready, "expected %s Service to have no ready endpoint", serviceNN)
+		}
+	}
+
+	route = route.DeepCopy()
+	require.NoErrorf(t, c.Create(ctx, route), "error creating %s HTTPRoute", route.Name)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoErrorf(t, client.IgnoreNotFound(c.Delete(ctx, route)), "error deleting %s HTTPRoute", route.Name)
+	})
+
+	routeNN := types.NamespacedName{Name: route.Name, Namespace: route.Namespace}
+	expected := metav1.Condition{Type: string(v1alpha2.RouteConditionResolvedRefs), Status: metav1.ConditionTrue}
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		fetched := &v1alpha2.HTTPRoute{}
+		if err := c.Get(ctx, routeNN, fetched); err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+		if !parentHasCondition(t, fetched.Status.Parents, gwNN, fetched.Namespace, expected) {
+			t.Logf("HTTPRoute %s doesn't have ResolvedRefs set to True for %s Gateway yet", routeNN, gwNN)
+			return false, nil
+		}
+
+		if err := probe(); err != nil {
+			t.Logf("HTTPRoute %s to Services without endpoints isn't unavailable yet: %v", routeNN, err)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute %s to Services without endpoints to be unavailable", routeNN)
+}
+
+// readyEndpoints returns the number of ready addresses of the Endpoints of
+// the Service, which don't exist for Services without endpoints.
+func readyEndpoints(ctx context.Context, c client.Reader, serviceNN types.NamespacedName) (int, error) {
+	endpoints := &v1.Endpoints{}
+	if err := c.Get(ctx, serviceNN, endpoints); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	ready := 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+	}
+	return ready, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// refResolvingClient stands in for a Gateway controller resolving the
+// backendRefs of created HTTPRoutes, setting ResolvedRefs to True for every
+// parent when all referenced Services exist.
+type refResolvingClient struct {
+	client.Client
+}
+
+func (c *refResolvingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	route, ok := obj.(*v1alpha2.HTTPRoute)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	status := metav1.ConditionTrue
+	for _, rule := range route.Spec.Rules {
+		for _, ref := range rule.BackendRefs {
+			err := c.Client.Get(ctx, types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}, &v1.Service{})
+			if apierrors.IsNotFound(err) {
+				status = metav1.ConditionFalse
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+	for _, ref := range route.Spec.ParentRefs {
+		route.Status.Parents = append(route.Status.Parents, v1alpha2.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: "example.com/gateway-controller",
+			Conditions: []metav1.Condition{{
+				Type:   string(v1alpha2.RouteConditionResolvedRefs),
+				Status: status,
+				Reason: string(v1alpha2.RouteReasonResolvedRefs),
+			}},
+		})
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestHTTPRouteToServiceWithoutEndpointsMustBeUnavailable(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
+	c := &refResolvingClient{Client: newFakeClient(t,
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "no-endpoints", Namespace: ns}},
+		&v1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "no-endpoints", Namespace: ns}},
+	)}
+	port := v1alpha2.PortNumber(8080)
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-endpoints", Namespace: ns},
+		Spec: v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{{Name: v1alpha2.ObjectName(gwNN.Name)}},
+			},
+			Rules: []v1alpha2.HTTPRouteRule{{
+				BackendRefs: []v1alpha2.HTTPBackendRef{{
+					BackendRef: v1alpha2.BackendRef{
+						BackendObjectReference: v1alpha2.BackendObjectReference{Name: "no-endpoints", Port: &port},
+					},
+				}},
+			}},
+		},
+	}
+
+	// probe stands in for a request matching the route, which the Gateway
+	// answers with a 503 once the route is programmed.
+	var probes int
+	probe := func() error {
+		probes++
+		if probes < 2 {
+			return errors.New("expected status 503, got 404")
+		}
+		return nil
+	}
+
+	t.Run("run", func(t *testing.T) {
+		HTTPRouteToServiceWithoutEndpointsMustBeUnavailable(t, c, route, gwNN, probe, 5)
+	})
+	require.Equal(t, 2, probes, "expected probes to be retried until requests get a 503")
+
+	err := c.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: ns}, &v1alpha2.HTTPRoute{})
+	require.True(t, apierrors.IsNotFound(err), "expected the HTTPRoute to be deleted once the test is done")
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// ListenerCertificateRefMustRequireGrant points the TLS certificateRefs of the
+// specified listener of the Gateway gwNN at the Secret secretNN, which must be
+// in another namespace than the Gateway. Without a ReferencePolicy allowing
+// the reference, the listener must have a ResolvedRefs condition set to False
+// with the RefNotPermitted reason and serving, e.g. a TLS handshake with the
+// listener, must fail. Once the helper creates a ReferencePolicy allowing the
+// reference, the listener must have ResolvedRefs set to True and serving must
+// succeed. The ReferencePolicy is deleted once the test is done. This will
+// cause the test to halt if the specified timeout is exceeded by either step.
+func ListenerCertificateRefMustRequireGrant(t *testing.T, c client.Client, gwNN types.NamespacedName, listenerName string, secretNN types.NamespacedName, serving func() error, seconds int) {
+	t.Helper()
+
+	require.NotEqualf(t, gwNN.Namespace, secretNN.Namespace, "expected Secret %s to be in another namespace than %s Gateway", secretNN, gwNN)
+	require.NoErrorf(t, setListenerCertificateRef(c, gwNN, listenerName, secretNN), "error referencing Secret %s from %s listener", secretNN, listenerName)
+
+	waitForListenerCertificateRef(t, c, gwNN, listenerName, metav1.ConditionFalse, string(v1alpha2.ListenerReasonRefNotPermitted), false, serving, seconds)
+
+	name := v1alpha2.ObjectName(secretNN.Name)
+	grant := &v1alpha2.ReferencePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s-cert", gwNN.Name, listenerName), Namespace: secretNN.Namespace},
+		Spec: v1alpha2.ReferencePolicySpec{
+			From: []v1alpha2.ReferencePolicyFrom{{
+				Group:     v1alpha2.GroupName,
+				Kind:      "Gateway",
+				Namespace: v1alpha2.Namespace(gwNN.Namespace),
+			}},
+			To: []v1alpha2.ReferencePolicyTo{{
+				Group: "",
+				Kind:  "Secret",
+				Name:  &name,
+			}},
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err := c.Create(ctx, grant)
+	cancel()
+	require.NoErrorf(t, err, "error creating %s ReferencePolicy", grant.Name)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoErrorf(t, c.Delete(ctx, grant), "error deleting %s ReferencePolicy", grant.Name)
+	})
+
+	waitForListenerCertificateRef(t, c, gwNN, listenerName, metav1.ConditionTrue, "", true, serving, seconds)
+}
+
+// setListenerCertificateRef makes the Secret secretNN the only certificate of
+// the specified listener of the Gateway gwNN, retrying on conflicts.
+func setListenerCertificateRef(c client.Client, gwNN types.NamespacedName, listenerName string, secretNN types.NamespacedName) error {
+	kind := v1alpha2.Kind("Secret")
+	group := v1alpha2.Group("")
+	namespace := v1alpha2.Namespace(secretNN.Namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			return fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		for i := range gw.Spec.Listeners {
+			listener := &gw.Spec.Listeners[i]
+			if string(listener.Name) != listenerName {
+				continue
+			}
+			if listener.TLS == nil {
+				listener.TLS = &v1alpha2.GatewayTLSConfig{}
+			}
+			listener.TLS.CertificateRefs = []*v1alpha2.SecretObjectReference{{
+				Group:     &group,
+				Kind:      &kind,
+				Name:      v1alpha2.ObjectName(secretNN.Name),
+				Namespace: &namespace,
+			}}
+			return c.Update(ctx, gw)
+		}
+		return fmt.Errorf("listener %s not found in %s Gateway", listenerName, gwNN)
+	})
+}
+
+// waitForListenerCertificateRef waits for the specified listener to have a
+// ResolvedRefs condition with the expected status and, if not empty, reason,
+// and for serving to succeed or fail as expected.
+func waitForListenerCertificateRef(t *testing.T, c client.Reader, gwNN types.NamespacedName, listenerName string, status metav1.ConditionStatus, reason string, served bool, serving func() error, seconds int) {
+	t.Helper()
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		var cond *metav1.Condition
+		for _, listener := range gw.Status.Listeners {
+			if string(listener.Name) == listenerName {
+				cond = apimeta.FindStatusCondition(listener.Conditions, string(v1alpha2.ListenerConditionResolvedRefs))
+			}
+		}
+		if cond == nil || cond.Status != status || (reason != "" && cond.Reason != reason) {
+			t.Logf("Expected %s listener of %s Gateway to have ResolvedRefs condition set to %s %s, got %v", listenerName, gwNN, status, reason, cond)
+			return false, nil
+		}
+
+		err := serving()
+		if served && err != nil {
+			t.Logf("%s listener of %s Gateway is not serving yet: %v", listenerName, gwNN, err)
+			return false, nil
+		}
+		if !served && err == nil {
+			t.Logf("%s listener of %s Gateway is still serving", listenerName, gwNN)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s listener of %s Gateway to have ResolvedRefs set to %s", listenerName, gwNN, status)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// grantCheckingClient stands in for a Gateway controller resolving the
+// certificateRefs of listeners when Gateways are fetched, only allowing
+// cross-namespace references permitted by a ReferencePolicy.
+type grantCheckingClient struct {
+	client.Client
+}
+
+func (c *grantCheckingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	gw, ok := obj.(*v1alpha2.Gateway)
+	if !ok {
+		return nil
+	}
+
+	gw.Status.Listeners = nil
+	for _, listener := range gw.Spec.Listeners {
+		cond := metav1.Condition{Type: string(v1alpha2.ListenerConditionResolvedRefs), Status: metav1.ConditionTrue, Reason: "ResolvedRefs"}
+		if listener.TLS != nil {
+			for _, ref := range listener.TLS.CertificateRefs {
+				if ref.Namespace == nil || string(*ref.Namespace) == gw.Namespace {
+					continue
+				}
+				permitted, err := c.permitted(ctx, gw.Namespace, string(*ref.Namespace))
+				if err != nil {
+					return err
+				}
+				if !permitted {
+					cond = metav1.Condition{Type: string(v1alpha2.ListenerConditionResolvedRefs), Status: metav1.ConditionFalse, Reason: string(v1alpha2.ListenerReasonRefNotPermitted)}
+				}
+			}
+		}
+		gw.Status.Listeners = append(gw.Status.Listeners, v1alpha2.ListenerStatus{
+			Name:       listener.Name,
+			Conditions: []metav1.Condition{cond},
+		})
+	}
+	return nil
+}
+
+// permitted returns whether a ReferencePolicy in the Secret namespace allows
+// Gateways of the Gateway namespace to reference Secrets.
+func (c *grantCheckingClient) permitted(ctx context.Context, gwNamespace, secretNamespace string) (bool, error) {
+	policies := &v1alpha2.ReferencePolicyList{}
+	if err := c.List(ctx, policies, client.InNamespace(secretNamespace)); err != nil {
+		return false, err
+	}
+	for _, policy := range policies.Items {
+		for _, from := range policy.Spec.From {
+			if from.Kind == "Gateway" && string(from.Namespace) == gwNamespace {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func TestListenerCertificateRefMustRequireGrant(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
+	secretNN := types.NamespacedName{Name: "certificate", Namespace: "gateway-conformance-web-backend"}
+	c := &grantCheckingClient{Client: newFakeClient(t, &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{{Name: "https", Port: 443, Protocol: v1alpha2.HTTPSProtocolType}},
+		},
+	})}
+
+	// serving stands in for a TLS handshake with the listener, which only
+	// succeeds once the listener resolved its certificate.
+	var states []bool
+	serving := func() error {
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(context.Background(), gwNN, gw); err != nil {
+			return err
+		}
+		permitted := gw.Status.Listeners[0].Conditions[0].Status == metav1.ConditionTrue
+		states = append(states, permitted)
+		if !permitted {
+			return errors.New("remote error: tls: unrecognized name")
+		}
+		return nil
+	}
+
+	t.Run("grant", func(t *testing.T) {
+		ListenerCertificateRefMustRequireGrant(t, c, gwNN, "https", secretNN, serving, 5)
+	})
+	require.Equal(t, []bool{false, true}, states, "expected listener to only serve once the reference is permitted")
+
+	gw := &v1alpha2.Gateway{}
+	require.NoError(t, c.Get(context.Background(), gwNN, gw))
+	ref := gw.Spec.Listeners[0].TLS.CertificateRefs[0]
+	require.Equal(t, v1alpha2.ObjectName(secretNN.Name), ref.Name)
+	require.Equal(t, v1alpha2.Namespace(secretNN.Namespace), *ref.Namespace)
+
+	t.Run("denied", func(t *testing.T) {
+		cond := gw.Status.Listeners[0].Conditions[0]
+		require.Equal(t, metav1.ConditionFalse, cond.Status, "expected reference to be denied once the ReferencePolicy is deleted")
+		require.Equal(t, string(v1alpha2.ListenerReasonRefNotPermitted), cond.Reason)
+	})
+
+	policies := &v1alpha2.ReferencePolicyList{}
+	require.NoError(t, c.List(context.Background(), policies))
+	require.Empty(t, policies.Items, "expected created ReferencePolicy to be deleted")
+}
@@ -27,26 +27,40 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
 )
 
-// GWCMustBeAccepted waits until the specified GatewayClass has an Accepted
-// condition set to true. It also returns the ControllerName for the
-// GatewayClass. This will cause the test to halt if the specified timeout is
-// exceeded.
-func GWCMustBeAccepted(t *testing.T, c client.Client, gwcName string, seconds int) string {
+// GWCMustBeAccepted is GWCMustBeAcceptedWithContext with a background
+// context.
+//
+// Deprecated: use GWCMustBeAcceptedWithContext.
+func GWCMustBeAccepted(t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, gwcName string) string {
+	t.Helper()
+	return GWCMustBeAcceptedWithContext(context.Background(), t, c, timeoutConfig, gwcName)
+}
+
+// GWCMustBeAcceptedWithContext waits until the specified GatewayClass has an
+// Accepted condition set to true. It also returns the ControllerName for the
+// GatewayClass. This will cause the test to halt if the
+// GatewayClassMustBeAccepted timeout is exceeded or ctx is cancelled.
+func GWCMustBeAcceptedWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, gwcName string) string {
 	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
 
 	var controllerName string
-	waitFor := time.Duration(seconds) * time.Second
-	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.GatewayClassMustBeAccepted, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
 		gwc := &v1alpha2.GatewayClass{}
@@ -63,15 +77,26 @@ func GWCMustBeAccepted(t *testing.T, c client.Client, gwcName string, seconds in
 	return controllerName
 }
 
-// NamespacesMustBeReady waits until all Pods and Gateways in the provided
-// namespaces are marked as ready. This will cause the test to halt if the
-// specified timeout is exceeded.
-func NamespacesMustBeReady(t *testing.T, c client.Client, namespaces []string, seconds int) {
+// NamespacesMustBeReady is NamespacesMustBeReadyWithContext with a
+// background context.
+//
+// Deprecated: use NamespacesMustBeReadyWithContext.
+func NamespacesMustBeReady(t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespaces []string) {
 	t.Helper()
+	NamespacesMustBeReadyWithContext(context.Background(), t, c, timeoutConfig, namespaces)
+}
 
-	waitFor := time.Duration(seconds) * time.Second
-	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// NamespacesMustBeReadyWithContext waits until all Pods and Gateways in the
+// provided namespaces are marked as ready. This will cause the test to halt
+// if the NamespacesMustBeReady timeout is exceeded or ctx is cancelled, with
+// the conditions of the Gateways and Pods that aren't ready and the state of
+// the containers of those Pods, e.g. CrashLoopBackOff.
+func NamespacesMustBeReadyWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespaces []string) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.NamespacesMustBeReady, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
 		for _, ns := range namespaces {
@@ -103,18 +128,151 @@ func NamespacesMustBeReady(t *testing.T, c client.Client, namespaces []string, s
 		t.Logf("Gateways and Pods in %s namespaces ready", strings.Join(namespaces, ", "))
 		return true, nil
 	})
-	require.NoErrorf(t, waitErr, "error waiting for %s namespaces to be ready", strings.Join(namespaces, ", "))
+	if waitErr != nil {
+		require.NoErrorf(t, waitErr, "error waiting for %s namespaces to be ready, %s", strings.Join(namespaces, ", "), describeNotReadyInNamespaces(c, namespaces))
+	}
+}
+
+// GatewayMustBeReadyWithContext waits until the Gateway is marked as ready and
+// has an address of the provided family in its status, e.g. for a Gateway
+// provisioned outside of the conformance suite, and returns it. This will
+// cause the test to halt if the NamespacesMustBeReady timeout is exceeded
+// before the Gateway is ready, if the GatewayMustHaveAddress timeout is
+// exceeded before it has an address, or if ctx is cancelled.
+func GatewayMustBeReadyWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, gwNN types.NamespacedName, family AddressFamily) GatewayAddress {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.NamespacesMustBeReady, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			t.Logf("Error fetching %s Gateway: %v", gwNN, err)
+			return false, nil
+		}
+		if !findConditionInList(t, gw.Status.Conditions, "Ready", "True") {
+			t.Logf("%s Gateway not ready yet", gwNN)
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s Gateway to be ready", gwNN)
+	return GatewayMustHaveAddressWithContext(ctx, t, c, timeoutConfig, gwNN, family)
+}
+
+// NamespacesMustBeDeletedWithContext waits until the provided namespaces that
+// were created by the conformance suite are gone, typically once the cleanup
+// of the resources applied by a run deleted them. Namespaces that weren't
+// created by the suite are never deleted by it and aren't waited for. This
+// will cause the test to halt if the NamespacesMustBeDeleted timeout is
+// exceeded or ctx is cancelled.
+func NamespacesMustBeDeletedWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespaces []string) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.NamespacesMustBeDeleted, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		for _, ns := range namespaces {
+			namespace := &v1.Namespace{}
+			err := c.Get(ctx, types.NamespacedName{Name: ns}, namespace)
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, fmt.Errorf("error fetching %s namespace: %w", ns, err)
+			}
+			if namespace.Labels[OwnedLabel] == "true" {
+				t.Logf("%s namespace not deleted yet", ns)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s namespaces to be deleted", strings.Join(namespaces, ", "))
+}
+
+// DeploymentsMustBeReady is DeploymentsMustBeReadyWithContext with a
+// background context.
+//
+// Deprecated: use DeploymentsMustBeReadyWithContext.
+func DeploymentsMustBeReady(t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespace string, names []string) {
+	t.Helper()
+	DeploymentsMustBeReadyWithContext(context.Background(), t, c, timeoutConfig, namespace, names)
+}
+
+// DeploymentsMustBeReadyWithContext waits until all the named Deployments in
+// the provided namespace have observed their latest spec and have all of
+// their replicas available. This will cause the test to halt if the
+// DeploymentsMustBeReady timeout is exceeded or ctx is cancelled, describing
+// the Deployments that aren't ready along with their Pods that aren't.
+func DeploymentsMustBeReadyWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, namespace string, names []string) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.DeploymentsMustBeReady, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		for _, name := range names {
+			deploy := &appsv1.Deployment{}
+			err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deploy)
+			if err != nil {
+				t.Logf("Error fetching %s/%s Deployment: %v", namespace, name, err)
+				return false, nil
+			}
+
+			replicas := int32(1)
+			if deploy.Spec.Replicas != nil {
+				replicas = *deploy.Spec.Replicas
+			}
+			if deploy.Status.ObservedGeneration < deploy.Generation || deploy.Status.AvailableReplicas < replicas {
+				t.Logf("%s/%s Deployment not ready yet", namespace, name)
+				return false, nil
+			}
+		}
+		t.Logf("Deployments %s in %s namespace ready", strings.Join(names, ", "), namespace)
+		return true, nil
+	})
+	if waitErr != nil {
+		require.NoErrorf(t, waitErr, "error waiting for %s Deployments in %s namespace to be ready, %s", strings.Join(names, ", "), namespace, describeNotReadyDeployments(c, namespace, names))
+	}
+}
+
+// GatewayAndHTTPRoutesMustBeReady is
+// GatewayAndHTTPRoutesMustBeReadyWithContext with a background context, the
+// default TimeoutConfig and any address family.
+//
+// Deprecated: use GatewayAndHTTPRoutesMustBeReadyWithContext.
+func GatewayAndHTTPRoutesMustBeReady(t *testing.T, c client.Reader, controllerName string, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
+	t.Helper()
+	return GatewayAndHTTPRoutesMustBeReadyWithContext(context.Background(), t, c, config.DefaultTimeoutConfig(), controllerName, AnyAddressFamily, gwNN, routeNNs...)
+}
+
+// GatewayAndHTTPRoutesMustBeReadyWithFamily is
+// GatewayAndHTTPRoutesMustBeReadyWithContext with a background context and
+// the default TimeoutConfig.
+//
+// Deprecated: use GatewayAndHTTPRoutesMustBeReadyWithContext.
+func GatewayAndHTTPRoutesMustBeReadyWithFamily(t *testing.T, c client.Reader, controllerName string, family AddressFamily, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
+	t.Helper()
+	return GatewayAndHTTPRoutesMustBeReadyWithContext(context.Background(), t, c, config.DefaultTimeoutConfig(), controllerName, family, gwNN, routeNNs...)
 }
 
-// GatewayAndHTTPRoutesMustBeReady waits until the specified Gateway has an IP
-// address assigned to it and the Route has a ParentRef referring to the
-// Gateway. The test will fail if these conditions are not met before the
-// timeouts.
-func GatewayAndHTTPRoutesMustBeReady(t *testing.T, c client.Client, controllerName string, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
+// GatewayAndHTTPRoutesMustBeReadyWithContext waits until the specified
+// Gateway has an address of the provided family assigned to it and the
+// Routes have a ParentRef referring to the Gateway, and returns the address
+// requests are sent to. This will cause the test to halt if the
+// GatewayMustHaveAddress or RouteMustHaveParents timeout is exceeded or ctx
+// is cancelled.
+func GatewayAndHTTPRoutesMustBeReadyWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, controllerName string, family AddressFamily, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
 	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
 
-	gwAddr, err := WaitForGatewayAddress(t, c, gwNN, 180)
-	require.NoErrorf(t, err, "timed out waiting for Gateway address to be assigned")
+	gwAddr := GatewayMustHaveAddressWithContext(ctx, t, c, timeoutConfig, gwNN, family).Dial
 
 	ns := v1alpha2.Namespace(gwNN.Namespace)
 	kind := v1alpha2.Kind("Gateway")
@@ -137,18 +295,22 @@ func GatewayAndHTTPRoutesMustBeReady(t *testing.T, c client.Client, controllerNa
 				Status: metav1.ConditionTrue,
 			}},
 		}}
-		HTTPRouteMustHaveParents(t, c, routeNN, parents, namespaceRequired, 60)
+		HTTPRouteMustHaveParentsWithContext(ctx, t, c, timeoutConfig, routeNN, parents, namespaceRequired)
 	}
 
 	return gwAddr
 }
 
-// WaitForGatewayAddress waits until at least one IP Address has been set in the
-// status of the specified Gateway.
-func WaitForGatewayAddress(t *testing.T, client client.Client, gwName types.NamespacedName, seconds int) (string, error) {
+// WaitForGatewayAddress waits until at least one usable address has been set
+// in the status of the specified Gateway and returns the ip:port requests to
+// its first listener are sent to. Hostname addresses are resolved through
+// DNS.
+//
+// Deprecated: use GatewayMustHaveAddressWithContext.
+func WaitForGatewayAddress(t *testing.T, client client.Reader, gwName types.NamespacedName, seconds int) (string, error) {
 	t.Helper()
 
-	var ipAddr, port string
+	var gwAddr string
 	waitFor := time.Duration(seconds) * time.Second
 	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -161,32 +323,102 @@ func WaitForGatewayAddress(t *testing.T, client client.Client, gwName types.Name
 			return false, fmt.Errorf("error fetching Gateway: %w", err)
 		}
 
-		port = strconv.FormatInt(int64(gw.Spec.Listeners[0].Port), 10)
+		address, ok := gatewayDialAddress(ctx, t, gw, AnyAddressFamily)
+		gwAddr = address.Dial
+		return ok, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for Gateway to have at least one usable %s address in status", familyName(AnyAddressFamily))
+	return gwAddr, waitErr
+}
+
+// WaitForGatewayAddresses waits until at least one IP Address has been set in
+// the status of the specified Gateway and returns every IP address in status,
+// e.g. both an IPv4 and an IPv6 address for dual-stack Gateways, joined with
+// the port of the first listener.
+func WaitForGatewayAddresses(t *testing.T, client client.Reader, gwName types.NamespacedName, seconds int) []string {
+	t.Helper()
+
+	var addrs []string
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		err := client.Get(ctx, gwName, gw)
+		if err != nil {
+			t.Logf("error fetching Gateway: %v", err)
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
 
-		// TODO: Support more than IPAddress
+		port := strconv.FormatInt(int64(gw.Spec.Listeners[0].Port), 10)
+		addrs = nil
 		for _, address := range gw.Status.Addresses {
 			if address.Type != nil && *address.Type == v1alpha2.IPAddressType {
-				ipAddr = address.Value
-				return true, nil
+				addrs = append(addrs, net.JoinHostPort(address.Value, port))
 			}
 		}
 
-		return false, nil
+		return len(addrs) > 0, nil
 	})
 	require.NoErrorf(t, waitErr, "error waiting for Gateway to have at least one IP address in status")
-	return net.JoinHostPort(ipAddr, port), waitErr
+	return addrs
 }
 
-// HTTPRouteMustHaveParents waits for the specified HTTPRoute to have parents
-// in status that match the expected parents. This will cause the test to halt
-// if the specified timeout is exceeded.
-func HTTPRouteMustHaveParents(t *testing.T, client client.Client, routeName types.NamespacedName, parents []v1alpha2.RouteParentStatus, namespaceRequired bool, seconds int) {
+// GatewaysMustShareAddress is GatewaysMustShareAddressWithContext with a
+// background context and the default TimeoutConfig.
+//
+// Deprecated: use GatewaysMustShareAddressWithContext.
+func GatewaysMustShareAddress(t *testing.T, c client.Reader, gwNNs ...types.NamespacedName) string {
+	t.Helper()
+	return GatewaysMustShareAddressWithContext(context.Background(), t, c, config.DefaultTimeoutConfig(), gwNNs...)
+}
+
+// GatewaysMustShareAddressWithContext waits until every specified Gateway has
+// an address assigned to it and verifies they all share the same address, as
+// is the case for implementations merging the listeners of multiple
+// Gateways. The shared address is returned. This will cause the test to halt
+// if the GatewayMustHaveAddress timeout is exceeded or ctx is cancelled.
+func GatewaysMustShareAddressWithContext(ctx context.Context, t *testing.T, c client.Reader, timeoutConfig config.TimeoutConfig, gwNNs ...types.NamespacedName) string {
 	t.Helper()
 
+	require.NotEmpty(t, gwNNs, "at least one Gateway is required")
+
+	var sharedAddr string
+	for i, gwNN := range gwNNs {
+		gwAddr := GatewayMustHaveAddressWithContext(ctx, t, c, timeoutConfig, gwNN, AnyAddressFamily).Dial
+		if i == 0 {
+			sharedAddr = gwAddr
+			continue
+		}
+		require.Equalf(t, sharedAddr, gwAddr, "expected %s Gateway to share address with %s", gwNN, gwNNs[0])
+	}
+
+	return sharedAddr
+}
+
+// HTTPRouteMustHaveParents is HTTPRouteMustHaveParentsWithContext with a
+// background context, waiting for the provided number of seconds.
+//
+// Deprecated: use HTTPRouteMustHaveParentsWithContext.
+func HTTPRouteMustHaveParents(t *testing.T, client client.Reader, routeName types.NamespacedName, parents []v1alpha2.RouteParentStatus, namespaceRequired bool, seconds int) {
+	t.Helper()
+	timeoutConfig := config.DefaultTimeoutConfig()
+	timeoutConfig.RouteMustHaveParents = time.Duration(seconds) * time.Second
+	HTTPRouteMustHaveParentsWithContext(context.Background(), t, client, timeoutConfig, routeName, parents, namespaceRequired)
+}
+
+// HTTPRouteMustHaveParentsWithContext waits for the specified HTTPRoute to
+// have parents in status that match the expected parents. This will cause the
+// test to halt if the RouteMustHaveParents timeout is exceeded or ctx is
+// cancelled.
+func HTTPRouteMustHaveParentsWithContext(ctx context.Context, t *testing.T, client client.Reader, timeoutConfig config.TimeoutConfig, routeName types.NamespacedName, parents []v1alpha2.RouteParentStatus, namespaceRequired bool) {
+	t.Helper()
+	config.SetupTimeoutConfig(&timeoutConfig)
+
 	var actual []v1alpha2.RouteParentStatus
-	waitFor := time.Duration(seconds) * time.Second
-	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	waitErr := wait.PollImmediateWithContext(ctx, timeoutConfig.PollInterval, timeoutConfig.RouteMustHaveParents, func(ctx context.Context) (bool, error) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
 		route := &v1alpha2.HTTPRoute{}
@@ -202,6 +434,132 @@ func HTTPRouteMustHaveParents(t *testing.T, client client.Client, routeName type
 	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute to have parents matching expectations")
 }
 
+// HTTPRouteMustHaveParentCondition waits for the specified HTTPRoute to have
+// a condition matching the expected type, status and, if set, reason in the
+// status of the parent referring to parentNN. This will cause the test to
+// halt if the specified timeout is exceeded.
+func HTTPRouteMustHaveParentCondition(t *testing.T, client client.Reader, routeNN, parentNN types.NamespacedName, expected metav1.Condition, seconds int) {
+	t.Helper()
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		route := &v1alpha2.HTTPRoute{}
+		err := client.Get(ctx, routeNN, route)
+		if err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+
+		for _, parent := range route.Status.Parents {
+			if !parentRefMatches(parent.ParentRef, parentNN, routeNN.Namespace) {
+				continue
+			}
+			return findConditionWithReason(t, parent.Conditions, expected), nil
+		}
+
+		t.Logf("HTTPRoute %s has no status for parent %s yet", routeNN, parentNN)
+		return false, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute %s to have %s condition set to %s for parent %s", routeNN, expected.Type, expected.Status, parentNN)
+}
+
+// HTTPRouteMustHaveNonexistentParent waits for the specified HTTPRoute, which
+// references a parent Gateway that doesn't exist, to report an Accepted
+// condition set to False with the expected reason (typically
+// "NoMatchingParent") for that parent. This will cause the test to halt if
+// the specified timeout is exceeded.
+func HTTPRouteMustHaveNonexistentParent(t *testing.T, client client.Reader, routeNN, parentNN types.NamespacedName, reason string, seconds int) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := client.Get(ctx, parentNN, &v1alpha2.Gateway{})
+	require.Truef(t, apierrors.IsNotFound(err), "expected parent Gateway %s not to exist, got %v", parentNN, err)
+
+	HTTPRouteMustHaveParentCondition(t, client, routeNN, parentNN, metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionAccepted),
+		Status: metav1.ConditionFalse,
+		Reason: reason,
+	}, seconds)
+}
+
+// HTTPRouteMustBeRejected waits for the specified HTTPRoute, which is valid
+// according to the CRD schema but semantically invalid, e.g. because a filter
+// references something that doesn't exist, to have an Accepted condition set
+// to False with the expected reason for the parent Gateway gwNN. If reason is
+// empty, any reason is accepted. This will cause the test to halt if the
+// specified timeout is exceeded.
+func HTTPRouteMustBeRejected(t *testing.T, client client.Reader, routeNN, gwNN types.NamespacedName, reason string, seconds int) {
+	t.Helper()
+
+	HTTPRouteMustHaveParentCondition(t, client, routeNN, gwNN, metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionAccepted),
+		Status: metav1.ConditionFalse,
+		Reason: reason,
+	}, seconds)
+}
+
+// HTTPRouteMustHandleUnsupportedFields waits for the specified HTTPRoute,
+// which sets fields from the experimental release channel, to be handled
+// gracefully by an implementation only supporting the standard channel. The
+// route must get an Accepted condition for the parent Gateway gwNN: set to
+// True if the unsupported fields were ignored, or to False with a reason if
+// the route was rejected because of them. The returned value reports whether
+// the route was accepted. This will cause the test to halt if the specified
+// timeout is exceeded, e.g. because the controller crashed on the route.
+func HTTPRouteMustHandleUnsupportedFields(t *testing.T, client client.Reader, routeNN, gwNN types.NamespacedName, seconds int) bool {
+	t.Helper()
+
+	var accepted bool
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		route := &v1alpha2.HTTPRoute{}
+		err := client.Get(ctx, routeNN, route)
+		if err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+
+		for _, parent := range route.Status.Parents {
+			if !parentRefMatches(parent.ParentRef, gwNN, routeNN.Namespace) {
+				continue
+			}
+			cond := apimeta.FindStatusCondition(parent.Conditions, string(v1alpha2.RouteConditionAccepted))
+			switch {
+			case cond == nil:
+				t.Logf("HTTPRoute %s has no Accepted condition for %s Gateway yet", routeNN, gwNN)
+			case cond.Status == metav1.ConditionTrue:
+				accepted = true
+				return true, nil
+			case cond.Status == metav1.ConditionFalse && cond.Reason != "":
+				t.Logf("HTTPRoute %s with unsupported fields was rejected: %s", routeNN, cond.Reason)
+				accepted = false
+				return true, nil
+			}
+			return false, nil
+		}
+
+		t.Logf("HTTPRoute %s has no status for parent %s yet", routeNN, gwNN)
+		return false, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute %s with unsupported fields to be handled by %s Gateway", routeNN, gwNN)
+	return accepted
+}
+
+// parentRefMatches returns true if the ParentReference refers to the Gateway
+// parentNN, defaulting its namespace to the one of the route.
+func parentRefMatches(ref v1alpha2.ParentReference, parentNN types.NamespacedName, routeNamespace string) bool {
+	namespace := routeNamespace
+	if ref.Namespace != nil {
+		namespace = string(*ref.Namespace)
+	}
+	return string(ref.Name) == parentNN.Name && namespace == parentNN.Namespace
+}
+
 func parentsMatch(t *testing.T, expected, actual []v1alpha2.RouteParentStatus, namespaceRequired bool) bool {
 	t.Helper()
 
@@ -247,7 +605,7 @@ func parentsMatch(t *testing.T, expected, actual []v1alpha2.RouteParentStatus, n
 // GatewayStatusMustHaveListeners waits for the specified Gateway to have listeners
 // in status that match the expected listeners. This will cause the test to halt
 // if the specified timeout is exceeded.
-func GatewayStatusMustHaveListeners(t *testing.T, client client.Client, gwNN types.NamespacedName, listeners []v1alpha2.ListenerStatus, seconds int) {
+func GatewayStatusMustHaveListeners(t *testing.T, client client.Reader, gwNN types.NamespacedName, listeners []v1alpha2.ListenerStatus, seconds int) {
 	t.Helper()
 
 	var actual []v1alpha2.ListenerStatus
@@ -269,6 +627,160 @@ func GatewayStatusMustHaveListeners(t *testing.T, client client.Client, gwNN typ
 	require.NoErrorf(t, waitErr, "error waiting for Gateway status to have listeners matching expectations")
 }
 
+// UpdateListenerHostname sets the hostname of the specified listener of the
+// Gateway gwNN, retrying on conflicts. An empty hostname removes it.
+func UpdateListenerHostname(c client.Client, gwNN types.NamespacedName, listenerName string, hostname v1alpha2.Hostname) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			return fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		for i := range gw.Spec.Listeners {
+			listener := &gw.Spec.Listeners[i]
+			if string(listener.Name) != listenerName {
+				continue
+			}
+			listener.Hostname = nil
+			if hostname != "" {
+				listener.Hostname = &hostname
+			}
+			return c.Update(ctx, gw)
+		}
+		return fmt.Errorf("listener %s not found in %s Gateway", listenerName, gwNN)
+	})
+}
+
+// ListenerMustOnlyAttachAllowedKinds waits for the named listener of the
+// specified Gateway, which restricts allowedRoutes.kinds, to have only the
+// allowed route attached. The allowed route must be Accepted by the Gateway
+// while the disallowed route, of a kind the listener does not allow, must have
+// an Accepted condition set to False with the expected reason. Both routes
+// must be HTTPRoutes, TLSRoutes, TCPRoutes or UDPRoutes. This will cause the
+// test to halt if the specified timeout is exceeded.
+func ListenerMustOnlyAttachAllowedKinds(t *testing.T, c client.Reader, gwNN types.NamespacedName, listenerName string, allowed, disallowed client.Object, reason string, seconds int) {
+	t.Helper()
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		err := c.Get(ctx, gwNN, gw)
+		if err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		var attached int32 = -1
+		for _, listener := range gw.Status.Listeners {
+			if string(listener.Name) == listenerName {
+				attached = listener.AttachedRoutes
+			}
+		}
+		if attached != 1 {
+			t.Logf("Expected %s listener of %s Gateway to have 1 attached route, got %d", listenerName, gwNN, attached)
+			return false, nil
+		}
+
+		expected := map[client.Object]metav1.Condition{
+			allowed: {
+				Type:   string(v1alpha2.RouteConditionAccepted),
+				Status: metav1.ConditionTrue,
+			},
+			disallowed: {
+				Type:   string(v1alpha2.RouteConditionAccepted),
+				Status: metav1.ConditionFalse,
+				Reason: reason,
+			},
+		}
+		for route, condition := range expected {
+			routeNN := client.ObjectKeyFromObject(route)
+			if err = c.Get(ctx, routeNN, route); err != nil {
+				return false, fmt.Errorf("error fetching route %s: %w", routeNN, err)
+			}
+			parents, ok := routeParents(route)
+			if !ok {
+				return false, fmt.Errorf("unsupported route type %T", route)
+			}
+			if !parentHasCondition(t, parents, gwNN, routeNN.Namespace, condition) {
+				t.Logf("%T %s does not have the expected status for %s Gateway yet", route, routeNN, gwNN)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s listener of %s Gateway to only attach routes of allowed kinds", listenerName, gwNN)
+}
+
+// HTTPRouteMustNotAttachToListenerHostnames waits for the specified HTTPRoute,
+// whose hostnames aren't covered by any listener hostname of the Gateway gwNN,
+// to have an Accepted condition set to False with the NoMatchingListenerHostname
+// reason for that Gateway, while no listener of the Gateway has any route
+// attached. This will cause the test to halt if the specified timeout is
+// exceeded.
+func HTTPRouteMustNotAttachToListenerHostnames(t *testing.T, c client.Reader, gwNN, routeNN types.NamespacedName, seconds int) {
+	t.Helper()
+
+	expected := metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionAccepted),
+		Status: metav1.ConditionFalse,
+		Reason: "NoMatchingListenerHostname",
+	}
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		err := c.Get(ctx, gwNN, gw)
+		if err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		for _, listener := range gw.Status.Listeners {
+			if listener.AttachedRoutes != 0 {
+				t.Logf("Expected %s listener of %s Gateway to have no attached routes, got %d", listener.Name, gwNN, listener.AttachedRoutes)
+				return false, nil
+			}
+		}
+
+		route := &v1alpha2.HTTPRoute{}
+		if err = c.Get(ctx, routeNN, route); err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+		return parentHasCondition(t, route.Status.Parents, gwNN, routeNN.Namespace, expected), nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute %s not to attach to %s Gateway because of its hostnames", routeNN, gwNN)
+}
+
+// routeParents returns the parents in the status of the provided route.
+func routeParents(route client.Object) ([]v1alpha2.RouteParentStatus, bool) {
+	switch r := route.(type) {
+	case *v1alpha2.HTTPRoute:
+		return r.Status.Parents, true
+	case *v1alpha2.TLSRoute:
+		return r.Status.Parents, true
+	case *v1alpha2.TCPRoute:
+		return r.Status.Parents, true
+	case *v1alpha2.UDPRoute:
+		return r.Status.Parents, true
+	}
+	return nil, false
+}
+
+// parentHasCondition returns true if the status of the parent referring to
+// parentNN has a condition matching the expected one.
+func parentHasCondition(t *testing.T, parents []v1alpha2.RouteParentStatus, parentNN types.NamespacedName, routeNamespace string, expected metav1.Condition) bool {
+	for _, parent := range parents {
+		if parentRefMatches(parent.ParentRef, parentNN, routeNamespace) {
+			return findConditionWithReason(t, parent.Conditions, expected)
+		}
+	}
+	return false
+}
+
 // TODO(mikemorris): this and parentsMatch could possibly be rewritten as a generic function?
 func listenersMatch(t *testing.T, expected, actual []v1alpha2.ListenerStatus) bool {
 	t.Helper()
@@ -331,6 +843,28 @@ func findConditionInList(t *testing.T, conditions []metav1.Condition, condName,
 	return false
 }
 
+// findConditionWithReason returns true if the conditions contain one with the
+// expected type and status, and the expected reason if one is set.
+func findConditionWithReason(t *testing.T, conditions []metav1.Condition, expected metav1.Condition) bool {
+	for _, cond := range conditions {
+		if cond.Type != expected.Type {
+			continue
+		}
+		if cond.Status != expected.Status {
+			t.Logf("%s condition set to %s, expected %s", expected.Type, cond.Status, expected.Status)
+			return false
+		}
+		if expected.Reason != "" && cond.Reason != expected.Reason {
+			t.Logf("%s condition reason set to %s, expected %s", expected.Type, cond.Reason, expected.Reason)
+			return false
+		}
+		return true
+	}
+
+	t.Logf("%s was not in conditions list", expected.Type)
+	return false
+}
+
 func findPodConditionInList(t *testing.T, conditions []v1.PodCondition, condName, condValue string) bool {
 	for _, cond := range conditions {
 		if cond.Type == v1.PodConditionType(condName) {
@@ -0,0 +1,328 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// newFakeClient returns a fake client aware of core and Gateway API types,
+// populated with the provided objects.
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestHTTPRouteMustHaveNonexistentParent(t *testing.T) {
+	ns := v1alpha2.Namespace("gateway-conformance-infra")
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "missing-parent", Namespace: string(ns)},
+		Spec: v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{{Name: "does-not-exist"}},
+			},
+		},
+		Status: v1alpha2.HTTPRouteStatus{
+			RouteStatus: v1alpha2.RouteStatus{
+				Parents: []v1alpha2.RouteParentStatus{{
+					ParentRef:      v1alpha2.ParentReference{Name: "does-not-exist", Namespace: &ns},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionAccepted),
+						Status: metav1.ConditionFalse,
+						Reason: "NoMatchingParent",
+					}},
+				}},
+			},
+		},
+	}
+	c := newFakeClient(t, route)
+
+	routeNN := types.NamespacedName{Name: "missing-parent", Namespace: string(ns)}
+	parentNN := types.NamespacedName{Name: "does-not-exist", Namespace: string(ns)}
+	HTTPRouteMustHaveNonexistentParent(t, c, routeNN, parentNN, "NoMatchingParent", 5)
+}
+
+func TestListenerMustOnlyAttachAllowedKinds(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "httproutes-only", Namespace: ns}
+	parentStatus := func(status metav1.ConditionStatus, reason string) v1alpha2.RouteStatus {
+		return v1alpha2.RouteStatus{
+			Parents: []v1alpha2.RouteParentStatus{{
+				ParentRef:      v1alpha2.ParentReference{Name: v1alpha2.ObjectName(gwNN.Name)},
+				ControllerName: "example.com/gateway-controller",
+				Conditions: []metav1.Condition{{
+					Type:   string(v1alpha2.RouteConditionAccepted),
+					Status: status,
+					Reason: reason,
+				}},
+			}},
+		}
+	}
+
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+		Status: v1alpha2.GatewayStatus{
+			Listeners: []v1alpha2.ListenerStatus{{Name: "http", AttachedRoutes: 1}},
+		},
+	}
+	allowed := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowed-kind", Namespace: ns},
+		Status:     v1alpha2.HTTPRouteStatus{RouteStatus: parentStatus(metav1.ConditionTrue, "Accepted")},
+	}
+	disallowed := &v1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "disallowed-kind", Namespace: ns},
+		Status:     v1alpha2.TLSRouteStatus{RouteStatus: parentStatus(metav1.ConditionFalse, "NotAllowedByListeners")},
+	}
+	c := newFakeClient(t, gw, allowed, disallowed)
+
+	ListenerMustOnlyAttachAllowedKinds(t, c, gwNN, "http",
+		&v1alpha2.HTTPRoute{ObjectMeta: allowed.ObjectMeta},
+		&v1alpha2.TLSRoute{ObjectMeta: disallowed.ObjectMeta},
+		"NotAllowedByListeners", 5)
+}
+
+func TestHTTPRouteMustBeRejected(t *testing.T) {
+	ns := v1alpha2.Namespace("gateway-conformance-infra")
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-filter", Namespace: string(ns)},
+		Status: v1alpha2.HTTPRouteStatus{
+			RouteStatus: v1alpha2.RouteStatus{
+				Parents: []v1alpha2.RouteParentStatus{{
+					ParentRef:      v1alpha2.ParentReference{Name: "same-namespace"},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionAccepted),
+						Status: metav1.ConditionFalse,
+						Reason: "InvalidFilter",
+					}},
+				}},
+			},
+		},
+	}
+	c := newFakeClient(t, route)
+
+	routeNN := types.NamespacedName{Name: "invalid-filter", Namespace: string(ns)}
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: string(ns)}
+	HTTPRouteMustBeRejected(t, c, routeNN, gwNN, "InvalidFilter", 5)
+}
+
+func TestHTTPRouteMustNotAttachToListenerHostnames(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "hostname-listeners", Namespace: ns}
+	listenerHostname := v1alpha2.Hostname("*.example.com")
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{{Name: "http", Hostname: &listenerHostname, Port: 80, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+		Status: v1alpha2.GatewayStatus{
+			Listeners: []v1alpha2.ListenerStatus{{Name: "http", AttachedRoutes: 0}},
+		},
+	}
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "unmatched-hostname", Namespace: ns},
+		Spec: v1alpha2.HTTPRouteSpec{
+			Hostnames: []v1alpha2.Hostname{"other.org"},
+		},
+		Status: v1alpha2.HTTPRouteStatus{
+			RouteStatus: v1alpha2.RouteStatus{
+				Parents: []v1alpha2.RouteParentStatus{{
+					ParentRef:      v1alpha2.ParentReference{Name: v1alpha2.ObjectName(gwNN.Name)},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionAccepted),
+						Status: metav1.ConditionFalse,
+						Reason: "NoMatchingListenerHostname",
+					}},
+				}},
+			},
+		},
+	}
+	c := newFakeClient(t, gw, route)
+
+	HTTPRouteMustNotAttachToListenerHostnames(t, c, gwNN, types.NamespacedName{Name: route.Name, Namespace: ns}, 5)
+}
+
+// acceptingReader reports the GatewayClass it reads as accepted from the
+// second read on.
+type acceptingReader struct {
+	client.Reader
+	reads int
+}
+
+func (r *acceptingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := r.Reader.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	r.reads++
+	if gwc, ok := obj.(*v1alpha2.GatewayClass); ok && r.reads > 1 {
+		gwc.Status.Conditions = []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}}
+	}
+	return nil
+}
+
+func TestHelpersWithZeroTimeoutConfig(t *testing.T) {
+	gwc := &v1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance"},
+		Spec:       v1alpha2.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+	}
+	c := &acceptingReader{Reader: newFakeClient(t, gwc)}
+
+	// Polling more than once with a zero PollInterval would panic if it
+	// wasn't defaulted.
+	controllerName := GWCMustBeAccepted(t, c, config.TimeoutConfig{}, gwc.Name)
+	require.Equal(t, "example.com/gateway-controller", controllerName)
+	require.Equal(t, 2, c.reads, "expected the GatewayClass to be polled until accepted")
+
+	NamespacesMustBeReady(t, c, config.TimeoutConfig{}, []string{"gateway-conformance-infra"})
+	NamespacesMustBeDeletedWithContext(context.Background(), t, c, config.TimeoutConfig{}, []string{"gateway-conformance-infra"})
+	DeploymentsMustBeReady(t, c, config.TimeoutConfig{}, "gateway-conformance-infra", nil)
+}
+
+func TestGatewayAndHTTPRoutesMustBeReadyWithContext(t *testing.T) {
+	ns := v1alpha2.Namespace("gateway-conformance-infra")
+	kind := v1alpha2.Kind("Gateway")
+	ipAddress := v1alpha2.IPAddressType
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "same-namespace", Namespace: string(ns)},
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{{Name: "http", Port: 80, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+		Status: v1alpha2.GatewayStatus{
+			Addresses: []v1alpha2.GatewayAddress{{Type: &ipAddress, Value: "10.0.0.1"}},
+		},
+	}
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance-infra-test", Namespace: string(ns)},
+		Status: v1alpha2.HTTPRouteStatus{
+			RouteStatus: v1alpha2.RouteStatus{
+				Parents: []v1alpha2.RouteParentStatus{{
+					ParentRef: v1alpha2.ParentReference{
+						Group: (*v1alpha2.Group)(&v1alpha2.GroupVersion.Group),
+						Kind:  &kind,
+						Name:  v1alpha2.ObjectName(gw.Name),
+					},
+					ControllerName: "example.com/gateway-controller",
+					Conditions: []metav1.Condition{{
+						Type:   string(v1alpha2.RouteConditionAccepted),
+						Status: metav1.ConditionTrue,
+					}},
+				}},
+			},
+		},
+	}
+	c := newFakeClient(t, gw, route)
+
+	gwAddr := GatewayAndHTTPRoutesMustBeReadyWithContext(context.Background(), t, c, config.TimeoutConfig{}, "example.com/gateway-controller", AnyAddressFamily,
+		types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}, types.NamespacedName{Name: route.Name, Namespace: route.Namespace})
+	require.Equal(t, "10.0.0.1:80", gwAddr)
+}
+
+func TestWaitForGatewayAddresses(t *testing.T) {
+	ipAddress := v1alpha2.IPAddressType
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "dual-stack", Namespace: "gateway-conformance-infra"},
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{{Name: "http", Port: 80, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+		Status: v1alpha2.GatewayStatus{
+			Addresses: []v1alpha2.GatewayAddress{
+				{Type: &ipAddress, Value: "10.0.0.1"},
+				{Type: &ipAddress, Value: "fd00::1"},
+			},
+		},
+	}
+	c := newFakeClient(t, gw)
+
+	addrs := WaitForGatewayAddresses(t, c, types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}, 5)
+	require.Equal(t, []string{"10.0.0.1:80", "[fd00::1]:80"}, addrs)
+}
+
+func TestHTTPRouteMustHandleUnsupportedFields(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: ns}
+	newRoute := func(name string, status metav1.ConditionStatus, reason string) *v1alpha2.HTTPRoute {
+		return &v1alpha2.HTTPRoute{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns},
+			Status: v1alpha2.HTTPRouteStatus{
+				RouteStatus: v1alpha2.RouteStatus{
+					Parents: []v1alpha2.RouteParentStatus{{
+						ParentRef:      v1alpha2.ParentReference{Name: v1alpha2.ObjectName(gwNN.Name)},
+						ControllerName: "example.com/gateway-controller",
+						Conditions: []metav1.Condition{{
+							Type:   string(v1alpha2.RouteConditionAccepted),
+							Status: status,
+							Reason: reason,
+						}},
+					}},
+				},
+			},
+		}
+	}
+	c := newFakeClient(t,
+		newRoute("fields-ignored", metav1.ConditionTrue, "Accepted"),
+		newRoute("fields-rejected", metav1.ConditionFalse, "UnsupportedValue"),
+	)
+
+	t.Run("fields ignored", func(t *testing.T) {
+		accepted := HTTPRouteMustHandleUnsupportedFields(t, c, types.NamespacedName{Name: "fields-ignored", Namespace: ns}, gwNN, 5)
+		require.True(t, accepted)
+	})
+
+	t.Run("condition set", func(t *testing.T) {
+		accepted := HTTPRouteMustHandleUnsupportedFields(t, c, types.NamespacedName{Name: "fields-rejected", Namespace: ns}, gwNN, 5)
+		require.False(t, accepted)
+	})
+}
+
+func TestUpdateListenerHostname(t *testing.T) {
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
+	old := v1alpha2.Hostname("old.example.com")
+	c := newFakeClient(t, &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: gwNN.Namespace},
+		Spec: v1alpha2.GatewaySpec{
+			Listeners: []v1alpha2.Listener{
+				{Name: "http", Hostname: &old},
+				{Name: "other", Hostname: &old},
+			},
+		},
+	})
+
+	require.NoError(t, UpdateListenerHostname(c, gwNN, "http", "new.example.com"))
+
+	gw := &v1alpha2.Gateway{}
+	require.NoError(t, c.Get(context.Background(), gwNN, gw))
+	require.Equal(t, v1alpha2.Hostname("new.example.com"), *gw.Spec.Listeners[0].Hostname)
+	require.Equal(t, old, *gw.Spec.Listeners[1].Hostname, "expected other listeners to be left untouched")
+
+	require.Error(t, UpdateListenerHostname(c, gwNN, "missing", "new.example.com"))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// ResourceRef identifies a resource by kind, namespace and name.
+type ResourceRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String formats the reference as Kind namespace/name.
+func (r ResourceRef) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// resourceRef returns the reference to the resource.
+func resourceRef(uObj *unstructured.Unstructured) ResourceRef {
+	return ResourceRef{Kind: uObj.GetKind(), Namespace: uObj.GetNamespace(), Name: uObj.GetName()}
+}
+
+// ResourceTracker records the resources applied by an Applier and whether
+// they were cleaned up, so that resources left behind can be found once
+// tests are done. It's safe for concurrent use.
+type ResourceTracker struct {
+	mu        sync.Mutex
+	resources map[ResourceRef]*trackedResource
+}
+
+// trackedResource is a resource applied by an Applier.
+type trackedResource struct {
+	obj       *unstructured.Unstructured
+	cleanedUp bool
+}
+
+// applied records that the resource was applied.
+func (r *ResourceTracker) applied(uObj *unstructured.Unstructured) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.resources == nil {
+		r.resources = map[ResourceRef]*trackedResource{}
+	}
+	// Applying a resource again, e.g. for another test, makes it live.
+	r.resources[resourceRef(uObj)] = &trackedResource{obj: uObj.DeepCopy()}
+}
+
+// cleanedUp records that the resource was deleted on cleanup.
+func (r *ResourceTracker) cleanedUp(uObj *unstructured.Unstructured) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tracked, ok := r.resources[resourceRef(uObj)]; ok {
+		tracked.cleanedUp = true
+	}
+}
+
+// snapshot returns a copy of the tracked resources.
+func (r *ResourceTracker) snapshot() map[ResourceRef]trackedResource {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resources := make(map[ResourceRef]trackedResource, len(r.resources))
+	for ref, tracked := range r.resources {
+		resources[ref] = *tracked
+	}
+	return resources
+}
+
+// LeakReason tells why a resource is considered leaked.
+type LeakReason string
+
+const (
+	// LeakNotDeleted is used for resources still present although they
+	// were deleted on cleanup, e.g. because of a finalizer never removed.
+	LeakNotDeleted LeakReason = "still present after being deleted on cleanup"
+	// LeakNotApplied is used for resources in the audited namespaces that
+	// weren't applied by the conformance suite.
+	LeakNotApplied LeakReason = "not applied by the conformance suite"
+)
+
+// LeakedResource is a resource left behind in the cluster.
+type LeakedResource struct {
+	ResourceRef
+	Reason LeakReason
+}
+
+// auditedLists are the lists of Gateway API resources audited for leaks.
+var auditedLists = map[string]func() client.ObjectList{
+	"Gateway":         func() client.ObjectList { return &v1alpha2.GatewayList{} },
+	"HTTPRoute":       func() client.ObjectList { return &v1alpha2.HTTPRouteList{} },
+	"TLSRoute":        func() client.ObjectList { return &v1alpha2.TLSRouteList{} },
+	"TCPRoute":        func() client.ObjectList { return &v1alpha2.TCPRouteList{} },
+	"UDPRoute":        func() client.ObjectList { return &v1alpha2.UDPRouteList{} },
+	"ReferencePolicy": func() client.ObjectList { return &v1alpha2.ReferencePolicyList{} },
+}
+
+// FindLeakedResources returns the resources tracked by the tracker that are
+// still present although they were deleted on cleanup, along with the
+// Gateway API resources in the provided namespaces that weren't applied
+// through the tracker at all. Resources the tracker knows are meant to stay,
+// such as base resources kept after the run, aren't reported. Kinds whose
+// CRD isn't installed are ignored.
+func FindLeakedResources(ctx context.Context, c client.Reader, tracker *ResourceTracker, namespaces []string) ([]LeakedResource, error) {
+	tracked := tracker.snapshot()
+
+	var leaks []LeakedResource
+	for ref, resource := range tracked {
+		if !resource.cleanedUp {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(resource.obj.GroupVersionKind())
+		err := c.Get(ctx, client.ObjectKeyFromObject(resource.obj), obj)
+		switch {
+		case err == nil:
+			leaks = append(leaks, LeakedResource{ResourceRef: ref, Reason: LeakNotDeleted})
+		case !isNotFoundOrNoMatch(err):
+			return nil, fmt.Errorf("error fetching %s: %w", ref, err)
+		}
+	}
+
+	for _, ns := range namespaces {
+		for kind, newList := range auditedLists {
+			list := newList()
+			if err := c.List(ctx, list, client.InNamespace(ns)); err != nil {
+				if isNotFoundOrNoMatch(err) {
+					continue
+				}
+				return nil, fmt.Errorf("error listing %s resources in %s namespace: %w", kind, ns, err)
+			}
+			items, err := apimeta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				obj, ok := item.(client.Object)
+				if !ok {
+					continue
+				}
+				ref := ResourceRef{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName()}
+				if _, ok := tracked[ref]; !ok {
+					leaks = append(leaks, LeakedResource{ResourceRef: ref, Reason: LeakNotApplied})
+				}
+			}
+		}
+	}
+
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].String() < leaks[j].String() })
+	return leaks, nil
+}
+
+// isNotFoundOrNoMatch returns true if the error, or any error it wraps, tells
+// that the resource or its kind doesn't exist.
+func isNotFoundOrNoMatch(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// stuckDeleteClient ignores the deletion of the named resources, as if a
+// finalizer was never removed.
+type stuckDeleteClient struct {
+	client.Client
+	stuck map[string]bool
+}
+
+func (c *stuckDeleteClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if c.stuck[obj.GetName()] {
+		return nil
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func TestFindLeakedResources(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	unknown := &v1alpha2.HTTPRoute{ObjectMeta: metav1.ObjectMeta{Name: "unknown", Namespace: ns}}
+	c := &stuckDeleteClient{Client: newFakeClient(t, unknown), stuck: map[string]bool{"stuck": true}}
+
+	applier := Applier{Tracker: &ResourceTracker{}}
+	apply := func(t *testing.T, manifest string, cleanup bool) {
+		resources, err := applier.prepareResources(t, yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096), "test-class")
+		require.NoError(t, err)
+		applier.mustApplyResources(context.Background(), t, c, resources, cleanup)
+	}
+
+	apply(t, `
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: Gateway
+metadata:
+  name: base
+  namespace: gateway-conformance-infra
+spec:
+  gatewayClassName: test-class
+  listeners:
+  - name: http
+    port: 80
+    protocol: HTTP
+`, false)
+	t.Run("test", func(t *testing.T) {
+		apply(t, `
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: cleaned-up
+  namespace: gateway-conformance-infra
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: stuck
+  namespace: gateway-conformance-infra
+`, true)
+	})
+
+	leaks, err := FindLeakedResources(context.Background(), c, applier.Tracker, []string{ns})
+	require.NoError(t, err)
+	require.Equal(t, []LeakedResource{{
+		ResourceRef: ResourceRef{Kind: "HTTPRoute", Namespace: ns, Name: "stuck"},
+		Reason:      LeakNotDeleted,
+	}, {
+		ResourceRef: ResourceRef{Kind: "HTTPRoute", Namespace: ns, Name: "unknown"},
+		Reason:      LeakNotApplied,
+	}}, leaks)
+	require.Equal(t, "HTTPRoute gateway-conformance-infra/stuck", leaks[0].String())
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// RouteLimitConfig configures HTTPRoutesMustRespectListenerRouteLimit.
+type RouteLimitConfig struct {
+	// Limit is the maximum number of routes the implementation attaches to
+	// a single listener.
+	Limit int
+	// Excess is the number of routes created beyond Limit. Defaults to 1.
+	Excess int
+	// Reason is the reason of the Accepted condition set to False on the
+	// routes exceeding Limit.
+	Reason string
+}
+
+// HTTPRoutesMustRespectListenerRouteLimit creates Limit+Excess copies of the
+// template HTTPRoute, which must attach to the specified listener of the
+// Gateway gwNN, and waits for the listener to have exactly Limit attached
+// routes. Limit of the routes must be Accepted while the others must have an
+// Accepted condition set to False with the configured reason. The copies are
+// deleted once the test is done. The names of the rejected routes are
+// returned. This will cause the test to halt if the specified timeout is
+// exceeded.
+func HTTPRoutesMustRespectListenerRouteLimit(t *testing.T, c client.Client, gwNN types.NamespacedName, listenerName string, template *v1alpha2.HTTPRoute, config RouteLimitConfig, seconds int) []types.NamespacedName {
+	t.Helper()
+
+	if config.Excess == 0 {
+		config.Excess = 1
+	}
+
+	routeNNs := make([]types.NamespacedName, 0, config.Limit+config.Excess)
+	for i := 0; i < config.Limit+config.Excess; i++ {
+		route := template.DeepCopy()
+		route.ResourceVersion = ""
+		route.Name = fmt.Sprintf("%s-limit-%d", template.Name, i)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := c.Create(ctx, route)
+		cancel()
+		require.NoErrorf(t, err, "error creating %s HTTPRoute", route.Name)
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			require.NoErrorf(t, c.Delete(ctx, route), "error deleting %s HTTPRoute", route.Name)
+		})
+		routeNNs = append(routeNNs, client.ObjectKeyFromObject(route))
+	}
+
+	var rejectedNNs []types.NamespacedName
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		err := c.Get(ctx, gwNN, gw)
+		if err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		var attached int32 = -1
+		for _, listener := range gw.Status.Listeners {
+			if string(listener.Name) == listenerName {
+				attached = listener.AttachedRoutes
+			}
+		}
+		if attached != int32(config.Limit) {
+			t.Logf("Expected %s listener of %s Gateway to have %d attached routes, got %d", listenerName, gwNN, config.Limit, attached)
+			return false, nil
+		}
+
+		rejectedNNs = nil
+		acceptedCount := 0
+		for _, routeNN := range routeNNs {
+			route := &v1alpha2.HTTPRoute{}
+			if err = c.Get(ctx, routeNN, route); err != nil {
+				return false, fmt.Errorf("error fetching HTTPRoute %s: %w", routeNN, err)
+			}
+			switch cond := routeAcceptedCondition(route, gwNN); {
+			case cond != nil && cond.Status == metav1.ConditionTrue:
+				acceptedCount++
+			case cond != nil && cond.Status == metav1.ConditionFalse && cond.Reason == config.Reason:
+				rejectedNNs = append(rejectedNNs, routeNN)
+			default:
+				t.Logf("HTTPRoute %s is neither accepted nor rejected with %s reason by %s Gateway yet", routeNN, config.Reason, gwNN)
+				return false, nil
+			}
+		}
+
+		if acceptedCount != config.Limit || len(rejectedNNs) != config.Excess {
+			t.Logf("Expected %d accepted and %d rejected HTTPRoutes, got %d and %d", config.Limit, config.Excess, acceptedCount, len(rejectedNNs))
+			return false, nil
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s listener of %s Gateway to attach at most %d routes", listenerName, gwNN, config.Limit)
+	return rejectedNNs
+}
+
+// routeAcceptedCondition returns the Accepted condition of the HTTPRoute for
+// the parent Gateway gwNN, or nil if it isn't set.
+func routeAcceptedCondition(route *v1alpha2.HTTPRoute, gwNN types.NamespacedName) *metav1.Condition {
+	for _, parent := range route.Status.Parents {
+		if parentRefMatches(parent.ParentRef, gwNN, route.Namespace) {
+			return apimeta.FindStatusCondition(parent.Conditions, string(v1alpha2.RouteConditionAccepted))
+		}
+	}
+	return nil
+}
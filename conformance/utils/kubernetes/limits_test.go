@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// routeCapClient stands in for a Gateway controller attaching at most limit
+// HTTPRoutes to the http listener of the gwNN Gateway, in creation order.
+type routeCapClient struct {
+	client.Client
+	gwNN  types.NamespacedName
+	limit int
+}
+
+func (c *routeCapClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	route, ok := obj.(*v1alpha2.HTTPRoute)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	gw := &v1alpha2.Gateway{}
+	if err := c.Client.Get(ctx, c.gwNN, gw); err != nil {
+		return err
+	}
+	cond := metav1.Condition{Type: string(v1alpha2.RouteConditionAccepted), Status: metav1.ConditionTrue, Reason: "Accepted"}
+	if int(gw.Status.Listeners[0].AttachedRoutes) < c.limit {
+		gw.Status.Listeners[0].AttachedRoutes++
+		if err := c.Client.Update(ctx, gw); err != nil {
+			return err
+		}
+	} else {
+		cond = metav1.Condition{Type: string(v1alpha2.RouteConditionAccepted), Status: metav1.ConditionFalse, Reason: "TooManyRoutes"}
+	}
+	route.Status.Parents = []v1alpha2.RouteParentStatus{{
+		ParentRef:      v1alpha2.ParentReference{Name: v1alpha2.ObjectName(c.gwNN.Name)},
+		ControllerName: "example.com/gateway-controller",
+		Conditions:     []metav1.Condition{cond},
+	}}
+	return c.Client.Create(ctx, route, opts...)
+}
+
+func TestHTTPRoutesMustRespectListenerRouteLimit(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	gwNN := types.NamespacedName{Name: "capped", Namespace: ns}
+	c := &routeCapClient{
+		Client: newFakeClient(t, &v1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: ns},
+			Status: v1alpha2.GatewayStatus{
+				Listeners: []v1alpha2.ListenerStatus{{Name: "http"}},
+			},
+		}),
+		gwNN:  gwNN,
+		limit: 3,
+	}
+	template := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "capped-route", Namespace: ns},
+		Spec: v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{{Name: v1alpha2.ObjectName(gwNN.Name)}},
+			},
+		},
+	}
+
+	t.Run("attach", func(t *testing.T) {
+		rejected := HTTPRoutesMustRespectListenerRouteLimit(t, c, gwNN, "http", template, RouteLimitConfig{
+			Limit:  3,
+			Excess: 2,
+			Reason: "TooManyRoutes",
+		}, 5)
+		require.Equal(t, []types.NamespacedName{
+			{Name: "capped-route-limit-3", Namespace: ns},
+			{Name: "capped-route-limit-4", Namespace: ns},
+		}, rejected)
+	})
+
+	routes := &v1alpha2.HTTPRouteList{}
+	require.NoError(t, c.List(context.Background(), routes))
+	require.Empty(t, routes.Items, "expected created HTTPRoutes to be deleted")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// HTTPRouteBeforeGatewayMustAttach creates the provided HTTPRoute while its
+// parent Gateway gw doesn't exist yet, then creates the Gateway, and deletes
+// both once the test is done. The route must eventually be attached to the
+// Gateway with an Accepted condition set to True, as it would have been had
+// the Gateway been created first. This will cause the test to halt if the
+// specified timeout is exceeded.
+func HTTPRouteBeforeGatewayMustAttach(t *testing.T, c client.Client, route *v1alpha2.HTTPRoute, gw *v1alpha2.Gateway, seconds int) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gwNN := types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}
+	err := c.Get(ctx, gwNN, &v1alpha2.Gateway{})
+	require.Truef(t, apierrors.IsNotFound(err), "expected Gateway %s not to exist before its HTTPRoute, got %v", gwNN, err)
+
+	route = route.DeepCopy()
+	require.NoErrorf(t, c.Create(ctx, route), "error creating %s HTTPRoute", route.Name)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoErrorf(t, client.IgnoreNotFound(c.Delete(ctx, route)), "error deleting %s HTTPRoute", route.Name)
+	})
+
+	gw = gw.DeepCopy()
+	require.NoErrorf(t, c.Create(ctx, gw), "error creating %s Gateway", gwNN)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoErrorf(t, client.IgnoreNotFound(c.Delete(ctx, gw)), "error deleting %s Gateway", gwNN)
+	})
+
+	routeNN := types.NamespacedName{Name: route.Name, Namespace: route.Namespace}
+	HTTPRouteMustHaveParentCondition(t, c, routeNN, gwNN, metav1.Condition{
+		Type:   string(v1alpha2.RouteConditionAccepted),
+		Status: metav1.ConditionTrue,
+	}, seconds)
+}
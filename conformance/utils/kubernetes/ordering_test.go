@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// attachingClient stands in for a Gateway controller attaching HTTPRoutes to
+// their parent Gateways when their status is read, reporting routes as not
+// accepted while the Gateway doesn't exist.
+type attachingClient struct {
+	client.Client
+	// routesBeforeGateways counts the HTTPRoutes created while their
+	// parent Gateway didn't exist.
+	routesBeforeGateways int
+}
+
+func (c *attachingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if route, ok := obj.(*v1alpha2.HTTPRoute); ok {
+		for _, ref := range route.Spec.ParentRefs {
+			err := c.Client.Get(ctx, types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}, &v1alpha2.Gateway{})
+			if apierrors.IsNotFound(err) {
+				c.routesBeforeGateways++
+			}
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *attachingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	route, ok := obj.(*v1alpha2.HTTPRoute)
+	if !ok {
+		return nil
+	}
+
+	route.Status.Parents = nil
+	for _, ref := range route.Spec.ParentRefs {
+		accepted := metav1.Condition{
+			Type:   string(v1alpha2.RouteConditionAccepted),
+			Status: metav1.ConditionTrue,
+			Reason: string(v1alpha2.RouteReasonAccepted),
+		}
+		err := c.Client.Get(ctx, types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}, &v1alpha2.Gateway{})
+		if apierrors.IsNotFound(err) {
+			accepted.Status = metav1.ConditionFalse
+			accepted.Reason = "NoMatchingParent"
+		} else if err != nil {
+			return err
+		}
+		route.Status.Parents = append(route.Status.Parents, v1alpha2.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: "example.com/gateway-controller",
+			Conditions:     []metav1.Condition{accepted},
+		})
+	}
+	return nil
+}
+
+func TestHTTPRouteBeforeGatewayMustAttach(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	c := &attachingClient{Client: newFakeClient(t)}
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "late-gateway", Namespace: ns},
+		Spec: v1alpha2.GatewaySpec{
+			GatewayClassName: "gateway-conformance",
+			Listeners:        []v1alpha2.Listener{{Name: "http", Port: 80, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+	}
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "early-route", Namespace: ns},
+		Spec: v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{{Name: v1alpha2.ObjectName(gw.Name)}},
+			},
+		},
+	}
+
+	t.Run("run", func(t *testing.T) {
+		HTTPRouteBeforeGatewayMustAttach(t, c, route, gw, 5)
+	})
+	require.Equal(t, 1, c.routesBeforeGateways, "expected the HTTPRoute to be created before its Gateway")
+
+	err := c.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: ns}, &v1alpha2.HTTPRoute{})
+	require.True(t, apierrors.IsNotFound(err), "expected the HTTPRoute to be deleted once the test is done")
+	err = c.Get(context.Background(), types.NamespacedName{Name: gw.Name, Namespace: ns}, &v1alpha2.Gateway{})
+	require.True(t, apierrors.IsNotFound(err), "expected the Gateway to be deleted once the test is done")
+}
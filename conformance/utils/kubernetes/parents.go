@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// HTTPRouteMustAttachToEachParent creates the HTTPRoute, which must reference
+// multiple parent Gateways, and waits for every parent to accept it with a
+// status entry of its own. probe is then called for each parent, e.g. to make
+// a request through its address, and must succeed for all of them. The route
+// is deleted once the test is done. This will cause the test to halt if the
+// specified timeout is exceeded.
+func HTTPRouteMustAttachToEachParent(t *testing.T, c client.Client, route *v1alpha2.HTTPRoute, probe func(parentNN types.NamespacedName) error, seconds int) {
+	t.Helper()
+
+	require.GreaterOrEqualf(t, len(route.Spec.ParentRefs), 2, "expected HTTPRoute %s to reference multiple parents", route.Name)
+	parentNNs := make([]types.NamespacedName, 0, len(route.Spec.ParentRefs))
+	for _, ref := range route.Spec.ParentRefs {
+		parentNN := types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}
+		if ref.Namespace != nil {
+			parentNN.Namespace = string(*ref.Namespace)
+		}
+		parentNNs = append(parentNNs, parentNN)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err := c.Create(ctx, route)
+	cancel()
+	require.NoErrorf(t, err, "error creating %s HTTPRoute", route.Name)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		require.NoErrorf(t, c.Delete(ctx, route), "error deleting %s HTTPRoute", route.Name)
+	})
+	routeNN := client.ObjectKeyFromObject(route)
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		updated := &v1alpha2.HTTPRoute{}
+		if err := c.Get(ctx, routeNN, updated); err != nil {
+			return false, fmt.Errorf("error fetching HTTPRoute: %w", err)
+		}
+		for _, parentNN := range parentNNs {
+			cond := routeAcceptedCondition(updated, parentNN)
+			if cond == nil || cond.Status != metav1.ConditionTrue {
+				t.Logf("HTTPRoute %s is not accepted by %s Gateway yet", routeNN, parentNN)
+				return false, nil
+			}
+		}
+
+		for _, parentNN := range parentNNs {
+			if err := probe(parentNN); err != nil {
+				t.Logf("HTTPRoute %s is not served through %s Gateway yet: %v", routeNN, parentNN, err)
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for HTTPRoute %s to attach to each of its parents", routeNN)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// parentStatusClient stands in for Gateway controllers setting a status entry
+// on created HTTPRoutes for each referenced parent Gateway that exists.
+type parentStatusClient struct {
+	client.Client
+}
+
+func (c *parentStatusClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	route, ok := obj.(*v1alpha2.HTTPRoute)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	for _, ref := range route.Spec.ParentRefs {
+		gwNN := types.NamespacedName{Name: string(ref.Name), Namespace: route.Namespace}
+		if ref.Namespace != nil {
+			gwNN.Namespace = string(*ref.Namespace)
+		}
+		if err := c.Client.Get(ctx, gwNN, &v1alpha2.Gateway{}); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		route.Status.Parents = append(route.Status.Parents, v1alpha2.RouteParentStatus{
+			ParentRef:      ref,
+			ControllerName: "example.com/gateway-controller",
+			Conditions: []metav1.Condition{{
+				Type:   string(v1alpha2.RouteConditionAccepted),
+				Status: metav1.ConditionTrue,
+				Reason: "Accepted",
+			}},
+		})
+	}
+	return c.Client.Create(ctx, route, opts...)
+}
+
+func TestHTTPRouteMustAttachToEachParent(t *testing.T) {
+	ns := "gateway-conformance-infra"
+	otherNS := v1alpha2.Namespace("gateway-conformance-web-backend")
+	c := &parentStatusClient{Client: newFakeClient(t,
+		&v1alpha2.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "same-namespace", Namespace: ns}},
+		&v1alpha2.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "backend-namespace", Namespace: string(otherNS)}},
+	)}
+	route := &v1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "multiple-parents", Namespace: ns},
+		Spec: v1alpha2.HTTPRouteSpec{
+			CommonRouteSpec: v1alpha2.CommonRouteSpec{
+				ParentRefs: []v1alpha2.ParentReference{
+					{Name: "same-namespace"},
+					{Name: "backend-namespace", Namespace: &otherNS},
+				},
+			},
+		},
+	}
+
+	probes := map[types.NamespacedName]int{}
+	t.Run("attach", func(t *testing.T) {
+		HTTPRouteMustAttachToEachParent(t, c, route, func(parentNN types.NamespacedName) error {
+			probes[parentNN]++
+			// The second parent takes longer to program the route, which
+			// must not be mistaken for the first one serving it.
+			if parentNN.Name == "backend-namespace" && probes[parentNN] == 1 {
+				return errors.New("not programmed yet")
+			}
+			return nil
+		}, 5)
+	})
+	require.Equal(t, map[types.NamespacedName]int{
+		{Name: "same-namespace", Namespace: ns}:                 2,
+		{Name: "backend-namespace", Namespace: string(otherNS)}: 2,
+	}, probes)
+
+	routes := &v1alpha2.HTTPRouteList{}
+	require.NoError(t, c.List(context.Background(), routes))
+	require.Empty(t, routes.Items, "expected created HTTPRoute to be deleted")
+}
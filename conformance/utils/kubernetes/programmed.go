@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// listenerConditionProgrammed is the type of the listener condition telling
+// whether the dataplane is ready to serve the listener.
+const listenerConditionProgrammed = "Programmed"
+
+// ListenerProgrammedMustServe waits until the specified listener has a
+// Programmed condition set to True and then probes it right away. As
+// Programmed must only be set once the dataplane is ready to serve, the
+// first probe has to succeed: it isn't retried, so that implementations
+// setting Programmed prematurely are caught.
+func ListenerProgrammedMustServe(t *testing.T, c client.Reader, gwNN types.NamespacedName, listenerName string, probe func() error, seconds int) {
+	t.Helper()
+
+	waitFor := time.Duration(seconds) * time.Second
+	waitErr := wait.PollImmediate(1*time.Second, waitFor, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		gw := &v1alpha2.Gateway{}
+		if err := c.Get(ctx, gwNN, gw); err != nil {
+			return false, fmt.Errorf("error fetching Gateway: %w", err)
+		}
+		for _, listener := range gw.Status.Listeners {
+			if string(listener.Name) == listenerName && apimeta.IsStatusConditionTrue(listener.Conditions, listenerConditionProgrammed) {
+				return true, nil
+			}
+		}
+		t.Logf("%s listener of %s Gateway is not Programmed yet", listenerName, gwNN)
+		return false, nil
+	})
+	require.NoErrorf(t, waitErr, "error waiting for %s listener of %s Gateway to have Programmed set to %s", listenerName, gwNN, metav1.ConditionTrue)
+
+	err := probe()
+	require.NoErrorf(t, err, "expected %s listener of %s Gateway to serve as soon as it is Programmed", listenerName, gwNN)
+}
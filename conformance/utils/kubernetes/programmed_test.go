@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// programmingClient stands in for a Gateway controller programming the
+// dataplane of a listener, reporting it Programmed once the dataplane is
+// ready, from the second Gateway fetch on.
+type programmingClient struct {
+	client.Client
+	fetches    int
+	programmed bool
+}
+
+func (c *programmingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	gw, ok := obj.(*v1alpha2.Gateway)
+	if !ok {
+		return nil
+	}
+
+	c.fetches++
+	c.programmed = c.fetches >= 2
+	status := metav1.ConditionFalse
+	if c.programmed {
+		status = metav1.ConditionTrue
+	}
+	gw.Status.Listeners = []v1alpha2.ListenerStatus{{
+		Name:       "http",
+		Conditions: []metav1.Condition{{Type: listenerConditionProgrammed, Status: status, Reason: "Programmed"}},
+	}}
+	return nil
+}
+
+func TestListenerProgrammedMustServe(t *testing.T) {
+	gwNN := types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}
+	c := &programmingClient{Client: newFakeClient(t, &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: gwNN.Name, Namespace: gwNN.Namespace},
+	})}
+
+	// probe stands in for a request to the listener, which only succeeds
+	// once the dataplane is programmed.
+	var probes int
+	probe := func() error {
+		probes++
+		if !c.programmed {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	ListenerProgrammedMustServe(t, c, gwNN, "http", probe, 5)
+	require.Equal(t, 1, probes, "expected the listener to be probed once it is Programmed")
+}
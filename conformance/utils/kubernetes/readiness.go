@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// diagnosticsTimeout bounds how long describing the objects that aren't
+// ready may take. The readiness helpers only describe them once they timed
+// out, with a fresh context as the one they waited with may be cancelled.
+const diagnosticsTimeout = 10 * time.Second
+
+// describeNotReadyInNamespaces returns a description of every Gateway and
+// Pod in the namespaces that NamespacesMustBeReady is waiting for.
+func describeNotReadyInNamespaces(c client.Reader, namespaces []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+
+	var descriptions []string
+	for _, ns := range namespaces {
+		gwList := &v1alpha2.GatewayList{}
+		if err := c.List(ctx, gwList, client.InNamespace(ns)); err != nil {
+			descriptions = append(descriptions, fmt.Sprintf("error listing Gateways in %s namespace: %v", ns, err))
+		}
+		for _, gw := range gwList.Items {
+			if !apimeta.IsStatusConditionTrue(gw.Status.Conditions, "Ready") {
+				descriptions = append(descriptions, describeGateway(gw))
+			}
+		}
+
+		podList := &v1.PodList{}
+		if err := c.List(ctx, podList, client.InNamespace(ns)); err != nil {
+			descriptions = append(descriptions, fmt.Sprintf("error listing Pods in %s namespace: %v", ns, err))
+		}
+		for _, pod := range podList.Items {
+			if !podReady(pod) && pod.Status.Phase != v1.PodSucceeded {
+				descriptions = append(descriptions, describePod(pod))
+			}
+		}
+	}
+	return formatDescriptions(descriptions)
+}
+
+// describeNotReadyDeployments returns a description of every named
+// Deployment that DeploymentsMustBeReady is waiting for, along with its Pods
+// that aren't ready.
+func describeNotReadyDeployments(c client.Reader, namespace string, names []string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+
+	var descriptions []string
+	for _, name := range names {
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deploy); err != nil {
+			descriptions = append(descriptions, fmt.Sprintf("error fetching %s/%s Deployment: %v", namespace, name, err))
+			continue
+		}
+		replicas := int32(1)
+		if deploy.Spec.Replicas != nil {
+			replicas = *deploy.Spec.Replicas
+		}
+		if deploy.Status.ObservedGeneration >= deploy.Generation && deploy.Status.AvailableReplicas >= replicas {
+			continue
+		}
+		descriptions = append(descriptions, describeDeployment(*deploy, replicas))
+
+		if deploy.Spec.Selector == nil {
+			continue
+		}
+		podList := &v1.PodList{}
+		if err := c.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabels(deploy.Spec.Selector.MatchLabels)); err != nil {
+			descriptions = append(descriptions, fmt.Sprintf("error listing Pods of %s/%s Deployment: %v", namespace, name, err))
+		}
+		for _, pod := range podList.Items {
+			if !podReady(pod) {
+				descriptions = append(descriptions, describePod(pod))
+			}
+		}
+	}
+	return formatDescriptions(descriptions)
+}
+
+// describeNotReadyBackends returns a description of the Pods that aren't
+// ready of every backend BackendsMustBeReady is waiting for.
+func describeNotReadyBackends(c client.Reader, backends []Backend) string {
+	ctx, cancel := context.WithTimeout(context.Background(), diagnosticsTimeout)
+	defer cancel()
+
+	var descriptions []string
+	for _, backend := range backends {
+		podList := &v1.PodList{}
+		if err := c.List(ctx, podList, client.InNamespace(backend.Namespace), client.MatchingLabels{"app": backend.AppLabel}); err != nil {
+			descriptions = append(descriptions, fmt.Sprintf("error listing Pods of %s backend: %v", backend, err))
+			continue
+		}
+		if len(podList.Items) == 0 {
+			descriptions = append(descriptions, fmt.Sprintf("%s backend has no Pods with app label %q", backend, backend.AppLabel))
+		}
+		for _, pod := range podList.Items {
+			if !podReady(pod) {
+				descriptions = append(descriptions, describePod(pod))
+			}
+		}
+	}
+	return formatDescriptions(descriptions)
+}
+
+// formatDescriptions formats the descriptions of objects for failure
+// messages, one per line.
+func formatDescriptions(descriptions []string) string {
+	if len(descriptions) == 0 {
+		return "no objects found not ready"
+	}
+	return "not ready:\n  " + strings.Join(descriptions, "\n  ")
+}
+
+// describeGateway describes the Gateway with its status conditions.
+func describeGateway(gw v1alpha2.Gateway) string {
+	return fmt.Sprintf("%s/%s Gateway, conditions: %s", gw.Namespace, gw.Name, formatConditions(gw.Status.Conditions))
+}
+
+// describeDeployment describes the Deployment with its available replicas
+// and status conditions.
+func describeDeployment(deploy appsv1.Deployment, replicas int32) string {
+	conditions := make([]string, 0, len(deploy.Status.Conditions))
+	for _, cond := range deploy.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s (reason: %s, message: %q)", cond.Type, cond.Status, cond.Reason, cond.Message))
+	}
+	return fmt.Sprintf("%s/%s Deployment, %d/%d replicas available, conditions: %s", deploy.Namespace, deploy.Name, deploy.Status.AvailableReplicas, replicas, joinOrNone(conditions))
+}
+
+// describePod describes the Pod with its phase, status conditions and the
+// state of its containers that aren't ready, e.g. CrashLoopBackOff.
+func describePod(pod v1.Pod) string {
+	conditions := make([]string, 0, len(pod.Status.Conditions))
+	for _, cond := range pod.Status.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s=%s (reason: %s, message: %q)", cond.Type, cond.Status, cond.Reason, cond.Message))
+	}
+
+	var containers []string
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if !status.Ready {
+			containers = append(containers, describeContainer(status))
+		}
+	}
+
+	description := fmt.Sprintf("%s/%s Pod, phase: %s, conditions: %s", pod.Namespace, pod.Name, pod.Status.Phase, joinOrNone(conditions))
+	if len(containers) > 0 {
+		description += ", containers: " + strings.Join(containers, ", ")
+	}
+	return description
+}
+
+// joinOrNone joins the formatted conditions, or returns "none" if there are
+// none.
+func joinOrNone(conditions []string) string {
+	if len(conditions) == 0 {
+		return "none"
+	}
+	return strings.Join(conditions, ", ")
+}
+
+// describeContainer describes the current state of the container and, if it
+// was restarted, why it last terminated.
+func describeContainer(status v1.ContainerStatus) string {
+	description := fmt.Sprintf("%s %s", status.Name, describeContainerState(status.State))
+	if status.LastTerminationState.Terminated != nil {
+		description += fmt.Sprintf(" after %d restarts, last %s", status.RestartCount, describeContainerState(status.LastTerminationState))
+	}
+	return description
+}
+
+// describeContainerState describes the state of a container, with its reason
+// and message if any.
+func describeContainerState(state v1.ContainerState) string {
+	switch {
+	case state.Waiting != nil:
+		return fmt.Sprintf("waiting (reason: %s, message: %q)", state.Waiting.Reason, state.Waiting.Message)
+	case state.Terminated != nil:
+		return fmt.Sprintf("terminated with exit code %d (reason: %s, message: %q)", state.Terminated.ExitCode, state.Terminated.Reason, state.Terminated.Message)
+	case state.Running != nil:
+		return "running"
+	}
+	return "in an unknown state"
+}
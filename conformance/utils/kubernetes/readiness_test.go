@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestDescribeNotReady(t *testing.T) {
+	replicas := int32(1)
+	c := newFakeClient(t,
+		&v1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "same-namespace", Namespace: "gateway-conformance-infra"},
+			Status: v1alpha2.GatewayStatus{Conditions: []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionFalse, Reason: "ListenersNotReady", Message: "listener http not programmed"},
+			}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "infra-backend-v1-a", Namespace: "gateway-conformance-infra", Labels: map[string]string{"app": "infra-backend-v1"}},
+			Status: v1.PodStatus{
+				Phase:      v1.PodRunning,
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionFalse, Reason: "ContainersNotReady"}},
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:                 "infra-backend-v1",
+					State:                v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 5m0s restarting failed container"}},
+					LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"}},
+					RestartCount:         5,
+				}},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "infra-backend-v2-a", Namespace: "gateway-conformance-infra", Labels: map[string]string{"app": "infra-backend-v2"}},
+			Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "infra-backend-v1", Namespace: "gateway-conformance-infra"},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "infra-backend-v1"}},
+			},
+			Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: v1.ConditionFalse, Reason: "MinimumReplicasUnavailable"},
+			}},
+		},
+	)
+
+	const (
+		gateway = `gateway-conformance-infra/same-namespace Gateway, conditions: Ready=False (reason: ListenersNotReady, message: "listener http not programmed")`
+		pod     = `gateway-conformance-infra/infra-backend-v1-a Pod, phase: Running, conditions: Ready=False (reason: ContainersNotReady, message: ""), ` +
+			`containers: infra-backend-v1 waiting (reason: CrashLoopBackOff, message: "back-off 5m0s restarting failed container") after 5 restarts, last terminated with exit code 1 (reason: Error, message: "")`
+	)
+
+	require.Equal(t, "not ready:\n  "+gateway+"\n  "+pod, describeNotReadyInNamespaces(c, []string{"gateway-conformance-infra"}))
+	require.Equal(t, "no objects found not ready", describeNotReadyInNamespaces(c, []string{"gateway-conformance-app-backend"}))
+
+	require.Equal(t, "not ready:\n  "+
+		`gateway-conformance-infra/infra-backend-v1 Deployment, 0/1 replicas available, conditions: Available=False (reason: MinimumReplicasUnavailable, message: "")`+
+		"\n  "+pod, describeNotReadyDeployments(c, "gateway-conformance-infra", []string{"infra-backend-v1"}))
+
+	require.Equal(t, "not ready:\n  "+pod+"\n  "+`gateway-conformance-infra/infra-backend-v3 backend has no Pods with app label "infra-backend-v3"`, describeNotReadyBackends(c, []Backend{
+		{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v1"},
+		{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v2"},
+		{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v3"},
+	}))
+}
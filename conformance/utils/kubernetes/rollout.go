@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RolloutConfig configures DeploymentRolloutMustNotDropTraffic.
+type RolloutConfig struct {
+	// ProbeInterval is how long to wait between two probes. Defaults to
+	// 100ms.
+	ProbeInterval time.Duration
+	// MaxErrorRate is the fraction of probes, between 0 and 1, that may fail
+	// while the Deployment is rolled out.
+	MaxErrorRate float64
+}
+
+// DeploymentRolloutMustNotDropTraffic applies update to the specified
+// Deployment to trigger a rollout, e.g. by changing its image or its number
+// of replicas, and calls probe continuously until every replica is updated
+// and available while no old replica remains. The fraction of failed probes
+// must not exceed MaxErrorRate; it is returned. This will cause the test to
+// halt if the specified timeout is exceeded.
+func DeploymentRolloutMustNotDropTraffic(t *testing.T, c client.Client, deploymentNN types.NamespacedName, update func(*appsv1.Deployment), probe func() error, config RolloutConfig, seconds int) float64 {
+	t.Helper()
+
+	if config.ProbeInterval == 0 {
+		config.ProbeInterval = 100 * time.Millisecond
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		deploy := &appsv1.Deployment{}
+		if err := c.Get(ctx, deploymentNN, deploy); err != nil {
+			return fmt.Errorf("error fetching Deployment: %w", err)
+		}
+		update(deploy)
+		return c.Update(ctx, deploy)
+	})
+	require.NoErrorf(t, err, "error updating %s Deployment", deploymentNN)
+	t.Logf("Rolling out %s Deployment", deploymentNN)
+
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+	probes, failures := 0, 0
+	for {
+		probes++
+		if err := probe(); err != nil {
+			failures++
+			t.Logf("Probe %d failed while rolling out %s Deployment: %v", probes, deploymentNN, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		deploy := &appsv1.Deployment{}
+		err := c.Get(ctx, deploymentNN, deploy)
+		cancel()
+		require.NoErrorf(t, err, "error fetching %s Deployment", deploymentNN)
+		if deploymentRolledOut(deploy) {
+			break
+		}
+
+		require.Truef(t, time.Now().Before(deadline), "error waiting for %s Deployment to be rolled out", deploymentNN)
+		time.Sleep(config.ProbeInterval)
+	}
+
+	errorRate := float64(failures) / float64(probes)
+	t.Logf("%d of %d probes failed while rolling out %s Deployment", failures, probes, deploymentNN)
+	require.LessOrEqualf(t, errorRate, config.MaxErrorRate, "expected at most %.2f%% of probes to fail while rolling out %s Deployment, %d of %d did", config.MaxErrorRate*100, deploymentNN, failures, probes)
+	return errorRate
+}
+
+// deploymentRolledOut returns true if the Deployment observed its latest spec
+// and only has updated, available replicas.
+func deploymentRolledOut(deploy *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return deploy.Status.ObservedGeneration >= deploy.Generation &&
+		deploy.Status.Replicas == replicas &&
+		deploy.Status.UpdatedReplicas == replicas &&
+		deploy.Status.AvailableReplicas == replicas
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rolloutClient stands in for the Deployment controller: updates start a
+// rollout surging one new replica, and every fetch of the Deployment then
+// replaces one more old replica until all of them are updated.
+type rolloutClient struct {
+	client.Client
+}
+
+func (c *rolloutClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if deploy, ok := obj.(*appsv1.Deployment); ok {
+		deploy.Generation++
+		deploy.Status.Replicas = *deploy.Spec.Replicas + 1
+		deploy.Status.UpdatedReplicas = 0
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *rolloutClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	deploy, ok := obj.(*appsv1.Deployment)
+	if !ok || deploy.Status.ObservedGeneration == deploy.Generation {
+		return nil
+	}
+
+	deploy.Status.UpdatedReplicas++
+	if deploy.Status.UpdatedReplicas == *deploy.Spec.Replicas {
+		deploy.Status.Replicas = *deploy.Spec.Replicas
+		deploy.Status.ObservedGeneration = deploy.Generation
+	}
+	return c.Client.Update(ctx, deploy)
+}
+
+func TestDeploymentRolloutMustNotDropTraffic(t *testing.T) {
+	replicas := int32(3)
+	deploymentNN := types.NamespacedName{Name: "infra-backend-v1", Namespace: "gateway-conformance-infra"}
+	c := &rolloutClient{Client: newFakeClient(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: deploymentNN.Name, Namespace: deploymentNN.Namespace, Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           replicas,
+			UpdatedReplicas:    replicas,
+			AvailableReplicas:  replicas,
+		},
+	})}
+
+	t.Run("continuous success", func(t *testing.T) {
+		probes := 0
+		errorRate := DeploymentRolloutMustNotDropTraffic(t, c, deploymentNN, func(deploy *appsv1.Deployment) {
+			deploy.Spec.Template.Annotations = map[string]string{"example.com/restartedAt": "now"}
+		}, func() error {
+			probes++
+			return nil
+		}, RolloutConfig{ProbeInterval: time.Millisecond}, 5)
+		require.Zero(t, errorRate)
+		require.Equal(t, 3, probes, "expected probes until every replica is updated")
+	})
+
+	t.Run("tolerated errors", func(t *testing.T) {
+		probes := 0
+		errorRate := DeploymentRolloutMustNotDropTraffic(t, c, deploymentNN, func(deploy *appsv1.Deployment) {
+			deploy.Spec.Template.Annotations = map[string]string{"example.com/restartedAt": "later"}
+		}, func() error {
+			probes++
+			if probes == 1 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}, RolloutConfig{ProbeInterval: time.Millisecond, MaxErrorRate: 0.5}, 5)
+		require.InDelta(t, 1.0/3, errorRate, 0.001)
+	})
+}
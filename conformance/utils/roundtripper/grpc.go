@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"time"
+)
+
+// GRPCUnaryRoundTripper is implemented by RoundTrippers that are able to make
+// unary gRPC calls.
+type GRPCUnaryRoundTripper interface {
+	CaptureGRPCUnaryCall(GRPCRequest) (*CapturedRequest, *CapturedGRPCResponse, error)
+}
+
+// GRPCRequest is the input for a unary gRPC call, made over cleartext HTTP/2.
+type GRPCRequest struct {
+	// Address is the host:port to connect to.
+	Address string
+	// Host is sent as the :authority pseudo-header, defaults to Address.
+	Host    string
+	Service string
+	Method  string
+	// Metadata is sent as request headers.
+	Metadata map[string][]string
+	// Message is the serialized request message.
+	Message []byte
+}
+
+// CapturedGRPCResponse contains the metadata of a gRPC response along with
+// the response message.
+type CapturedGRPCResponse struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	Headers    map[string][]string
+	Trailers   map[string][]string
+	// GRPCStatus is the grpc-status of the call, -1 if none was received.
+	GRPCStatus  int
+	GRPCMessage string
+	// Message is the serialized response message, if any.
+	Message []byte
+}
+
+// CaptureGRPCUnaryCall makes a unary gRPC call with the provided parameters
+// and returns the response along with the request metadata reported by the
+// backend, which is expected to respond with a JSON serialized CapturedRequest
+// as message the way echoserver does. An error will be returned if the
+// response is malformed, but not if a non-OK gRPC status is received.
+func (d *DefaultRoundTripper) CaptureGRPCUnaryCall(request GRPCRequest) (*CapturedRequest, *CapturedGRPCResponse, error) {
+	frame := make([]byte, 5, 5+len(request.Message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(request.Message)))
+	frame = append(frame, request.Message...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("http://%s/%s/%s", request.Address, request.Service, request.Method)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(frame))
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.Host != "" {
+		req.Host = request.Host
+	}
+	for name, values := range request.Metadata {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+
+	if d.Debug {
+		var dump []byte
+		dump, err = httputil.DumpRequestOut(req, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fmt.Printf("Sending gRPC Request:\n%s\n\n", formatDump(dump, "< "))
+	}
+
+	transport := h2cTransport()
+	defer transport.CloseIdleConnections()
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading gRPC response: %w", err)
+	}
+
+	if d.Debug {
+		var dump []byte
+		dump, err = httputil.DumpResponse(resp, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fmt.Printf("Received gRPC Response:\n%s\nTrailers: %v\n\n", formatDump(dump, "< "), resp.Trailer)
+	}
+
+	cRes := &CapturedGRPCResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Trailers:   resp.Trailer,
+		GRPCStatus: -1,
+	}
+
+	// Trailers-only responses carry the status in their headers.
+	status := resp.Trailer.Get("Grpc-Status")
+	cRes.GRPCMessage = resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		cRes.GRPCMessage = resp.Header.Get("Grpc-Message")
+	}
+	if status != "" {
+		cRes.GRPCStatus, err = strconv.Atoi(status)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid grpc-status %q: %w", status, err)
+		}
+	}
+
+	if len(body) > 0 {
+		if len(body) < 5 {
+			return nil, nil, errors.New("truncated gRPC message")
+		}
+		length := binary.BigEndian.Uint32(body[1:5])
+		if uint32(len(body)-5) < length {
+			return nil, nil, errors.New("truncated gRPC message")
+		}
+		cRes.Message = body[5 : 5+length]
+	}
+
+	cReq := &CapturedRequest{}
+	if len(cRes.Message) > 0 && json.Valid(cRes.Message) {
+		if err = json.Unmarshal(cRes.Message, cReq); err != nil {
+			return nil, nil, fmt.Errorf("unexpected error reading gRPC response message: %w", err)
+		}
+	}
+
+	return cReq, cRes, nil
+}
+
+// GRPCRoundTripper is a RoundTripper making every request as a unary gRPC
+// call to the configured Service and Method, which allows running HTTP
+// helpers against gRPC backends by setting it as Options.RoundTripper. It
+// also implements GRPCUnaryRoundTripper for helpers making gRPC calls
+// directly.
+type GRPCRoundTripper struct {
+	Debug   bool
+	Service string
+	Method  string
+	// Message is the serialized request message of every call.
+	Message []byte
+}
+
+// CaptureRoundTrip makes a unary gRPC call to the host of the request URL,
+// sending the Host of the request as :authority and its headers as metadata.
+// The path and method of the request are ignored. The captured headers
+// include the trailers of the response, such as Grpc-Status, so that the
+// outcome of the call can be checked. An error will be returned if the
+// response is malformed, but not if a non-OK gRPC status is received.
+func (g *GRPCRoundTripper) CaptureRoundTrip(request Request) (*CapturedRequest, *CapturedResponse, error) {
+	cReq, cGRPCRes, err := g.CaptureGRPCUnaryCall(GRPCRequest{
+		Address:  request.URL.Host,
+		Host:     request.Host,
+		Service:  g.Service,
+		Method:   g.Method,
+		Metadata: request.Headers,
+		Message:  g.Message,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make(map[string][]string, len(cGRPCRes.Headers)+len(cGRPCRes.Trailers))
+	for name, values := range cGRPCRes.Headers {
+		headers[name] = append([]string(nil), values...)
+	}
+	for name, values := range cGRPCRes.Trailers {
+		headers[name] = append(headers[name], values...)
+	}
+
+	cRes := &CapturedResponse{
+		StatusCode:    cGRPCRes.StatusCode,
+		ContentLength: int64(len(cGRPCRes.Message)),
+		Protocol:      "HTTP/2.0",
+		Headers:       headers,
+	}
+	return cReq, cRes, nil
+}
+
+// CaptureGRPCUnaryCall makes a unary gRPC call the same way DefaultRoundTripper
+// does.
+func (g *GRPCRoundTripper) CaptureGRPCUnaryCall(request GRPCRequest) (*CapturedRequest, *CapturedGRPCResponse, error) {
+	d := &DefaultRoundTripper{Debug: g.Debug}
+	return d.CaptureGRPCUnaryCall(request)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestCaptureGRPCUnaryCallTrailersOnly(t *testing.T) {
+	// A trailers-only response carries the status in its headers.
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "12")
+		w.Header().Set("Grpc-Message", "unimplemented")
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	d := &DefaultRoundTripper{}
+	_, cRes, err := d.CaptureGRPCUnaryCall(GRPCRequest{
+		Address: server.Listener.Addr().String(),
+		Service: "example.Service",
+		Method:  "Missing",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, cRes.StatusCode)
+	require.Equal(t, 12, cRes.GRPCStatus)
+	require.Equal(t, "unimplemented", cRes.GRPCMessage)
+	require.Empty(t, cRes.Message)
+}
+
+func TestGRPCRoundTripper(t *testing.T) {
+	// The fake backend reports the request metadata it received as message,
+	// with an OK grpc-status trailer.
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		message, _ := json.Marshal(CapturedRequest{
+			Path:      r.URL.Path,
+			Host:      r.Host,
+			Method:    r.Method,
+			Protocol:  r.Proto,
+			Headers:   r.Header,
+			Namespace: "gateway-conformance-infra",
+			Pod:       "grpc-infra-backend-v1-7f9c8d6b5-x2x9z",
+		})
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		frame := make([]byte, 5, 5+len(message))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+		_, _ = w.Write(append(frame, message...))
+		w.Header().Set("Grpc-Status", "0")
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	var r RoundTripper = &GRPCRoundTripper{
+		Service: "gateway_api_conformance.echo_basic.grpcecho.GrpcEcho",
+		Method:  "Echo",
+	}
+	_, ok := r.(GRPCUnaryRoundTripper)
+	require.True(t, ok, "expected GRPCRoundTripper to make unary gRPC calls")
+
+	cReq, cRes, err := r.CaptureRoundTrip(Request{
+		URL:     url.URL{Scheme: "http", Host: server.Listener.Addr().String(), Path: "/ignored"},
+		Host:    "grpc.example.com",
+		Headers: map[string][]string{"X-Echo-Set-Header": {"foo"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, cRes.StatusCode)
+	require.Equal(t, "HTTP/2.0", cRes.Protocol)
+	require.Equal(t, []string{"0"}, cRes.Headers["Grpc-Status"], "expected trailers to be captured as headers")
+
+	require.Equal(t, "/gateway_api_conformance.echo_basic.grpcecho.GrpcEcho/Echo", cReq.Path)
+	require.Equal(t, "grpc.example.com", cReq.Host)
+	require.Equal(t, "POST", cReq.Method)
+	require.Equal(t, []string{"foo"}, cReq.Headers["X-Echo-Set-Header"])
+	require.Equal(t, "grpc-infra-backend-v1-7f9c8d6b5-x2x9z", cReq.Pod)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// maxDatagramSize is the largest UDP datagram that can be received.
+const maxDatagramSize = 64 * 1024
+
+// L4RoundTripper is implemented by RoundTrippers that are able to send a
+// payload over a TCP connection or in a UDP datagram and capture the echo
+// response to it. This is used to verify TCPRoute and UDPRoute routing.
+type L4RoundTripper interface {
+	CaptureL4RoundTrip(L4Request) (*CapturedL4Response, error)
+}
+
+// L4Protocol is the transport protocol of an L4Request.
+type L4Protocol string
+
+const (
+	// L4ProtocolTCP sends the payload over a TCP connection, closing the
+	// write side of the connection once the payload is written.
+	L4ProtocolTCP L4Protocol = "TCP"
+	// L4ProtocolUDP sends the payload in a single UDP datagram.
+	L4ProtocolUDP L4Protocol = "UDP"
+)
+
+// L4Request is the input for an L4 round trip.
+type L4Request struct {
+	Protocol L4Protocol
+	// Address is the host:port to send the payload to.
+	Address string
+	Payload []byte
+}
+
+// CapturedL4Response contains the metadata reported by an L4 echoserver,
+// which responds with the backend that received the payload along with the
+// payload itself.
+type CapturedL4Response struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Payload   string `json:"payload"`
+}
+
+// CaptureL4RoundTrip sends the payload of the request and decodes the echo
+// response. Responses to TCP payloads are read until the backend closes the
+// connection, while a UDP payload must be answered with a single datagram. An
+// error will be returned if no valid echo response is received in time.
+func (d *DefaultRoundTripper) CaptureL4RoundTrip(request L4Request) (*CapturedL4Response, error) {
+	var network string
+	switch request.Protocol {
+	case L4ProtocolTCP:
+		network = "tcp"
+	case L4ProtocolUDP:
+		network = "udp"
+	default:
+		return nil, fmt.Errorf("unsupported L4 protocol %q", request.Protocol)
+	}
+
+	conn, err := net.DialTimeout(network, request.Address, d.requestTimeout())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(d.requestTimeout())); err != nil {
+		return nil, err
+	}
+
+	if d.Debug {
+		fmt.Printf("Sending %s Payload to %s:\n%s\n\n", request.Protocol, request.Address, formatDump(request.Payload, "< "))
+	}
+
+	if _, err = conn.Write(request.Payload); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if err = tcpConn.CloseWrite(); err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadAll(conn)
+	} else {
+		buf := make([]byte, maxDatagramSize)
+		var n int
+		n, err = conn.Read(buf)
+		data = buf[:n]
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s response: %w", request.Protocol, err)
+	}
+
+	if d.Debug {
+		fmt.Printf("Received %s Response:\n%s\n\n", request.Protocol, formatDump(data, "< "))
+	}
+
+	cRes := &CapturedL4Response{}
+	if err = json.Unmarshal(data, cRes); err != nil {
+		return nil, fmt.Errorf("unexpected error reading %s response: %w", request.Protocol, err)
+	}
+	return cRes, nil
+}
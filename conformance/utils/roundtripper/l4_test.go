@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureL4RoundTrip(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		payload, _ := ioutil.ReadAll(conn)
+		_, _ = conn.Write([]byte(`{"namespace": "gateway-conformance-infra", "pod": "tcp-backend-v1-7f9c8d6b5-x2x9z", "payload": "` + string(payload) + `"}`))
+	}()
+
+	d := &DefaultRoundTripper{}
+	cRes, err := d.CaptureL4RoundTrip(L4Request{Protocol: L4ProtocolTCP, Address: listener.Addr().String(), Payload: []byte("ping")})
+	require.NoError(t, err)
+	require.Equal(t, CapturedL4Response{Namespace: "gateway-conformance-infra", Pod: "tcp-backend-v1-7f9c8d6b5-x2x9z", Payload: "ping"}, *cRes)
+
+	_, err = d.CaptureL4RoundTrip(L4Request{Protocol: "SCTP", Address: listener.Addr().String()})
+	require.EqualError(t, err, `unsupported L4 protocol "SCTP"`)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RequestMetrics summarizes the round trips recorded by a recording of
+// Metrics.
+type RequestMetrics struct {
+	// Count is the number of round trips.
+	Count int
+	// P50, P90 and Max are the 50th and 90th percentiles and the maximum of
+	// the latencies of the round trips.
+	P50 time.Duration
+	P90 time.Duration
+	Max time.Duration
+	// StatusCodes counts the round trips by the status code of their
+	// response. Round trips that failed without a response are counted
+	// with status code 0.
+	StatusCodes map[int]int
+}
+
+// Metrics records the latency and status code of round trips for the
+// recording their request belongs to, i.e. the recording whose context the
+// Context of the request derives from, so that parallel tests only record
+// their own round trips. Round trips of requests with another context aren't
+// recorded. The zero value is ready to use, and a nil Metrics records
+// nothing.
+type Metrics struct {
+	mu sync.Mutex
+}
+
+// recordingKey is the context key of the recording of a context.
+type recordingKey struct{}
+
+// recording holds the round trips observed since it started.
+type recording struct {
+	metrics     *Metrics
+	stopped     bool
+	latencies   []time.Duration
+	statusCodes map[int]int
+}
+
+// Observe records a round trip for the recording of ctx, if it's one of m
+// and is still in progress.
+func (m *Metrics) Observe(ctx context.Context, latency time.Duration, statusCode int) {
+	if m == nil {
+		return
+	}
+	r, ok := ctx.Value(recordingKey{}).(*recording)
+	if !ok || r.metrics != m {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r.stopped {
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+	r.statusCodes[statusCode]++
+}
+
+// Start starts a recording, and returns a context derived from ctx round
+// trips are recorded for, along with a function stopping the recording and
+// returning the summary of the round trips observed in the meantime.
+// Recordings started from the returned context take over the round trips of
+// their own context.
+func (m *Metrics) Start(ctx context.Context) (context.Context, func() RequestMetrics) {
+	r := &recording{metrics: m, statusCodes: map[int]int{}}
+	return context.WithValue(ctx, recordingKey{}, r), func() RequestMetrics {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		r.stopped = true
+		return summarize(r.latencies, r.statusCodes)
+	}
+}
+
+// summarize returns the metrics of the provided latencies and status codes.
+func summarize(latencies []time.Duration, statusCodes map[int]int) RequestMetrics {
+	metrics := RequestMetrics{Count: len(latencies), StatusCodes: statusCodes}
+	if len(latencies) == 0 {
+		return metrics
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	metrics.P50 = percentile(sorted, 50)
+	metrics.P90 = percentile(sorted, 90)
+	metrics.Max = sorted[len(sorted)-1]
+	return metrics
+}
+
+// percentile returns the pth percentile of the sorted latencies, using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
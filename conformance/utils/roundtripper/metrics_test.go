@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	var nilMetrics *Metrics
+	nilMetrics.Observe(context.Background(), time.Second, 200)
+
+	m := &Metrics{}
+	m.Observe(context.Background(), time.Second, 200)
+
+	ctx, stop := m.Start(context.Background())
+	for i := 1; i <= 10; i++ {
+		m.Observe(ctx, time.Duration(i)*time.Millisecond, 200)
+	}
+	m.Observe(ctx, 20*time.Millisecond, 503)
+	m.Observe(ctx, 30*time.Millisecond, 0)
+	(&Metrics{}).Observe(ctx, time.Second, 200)
+	m.Observe(context.Background(), time.Second, 200)
+
+	require.Equal(t, RequestMetrics{
+		Count:       12,
+		P50:         6 * time.Millisecond,
+		P90:         20 * time.Millisecond,
+		Max:         30 * time.Millisecond,
+		StatusCodes: map[int]int{200: 10, 503: 1, 0: 1},
+	}, stop(), "expected only the round trips of the recording to be recorded")
+
+	m.Observe(ctx, time.Second, 200)
+	require.Equal(t, 12, stop().Count, "expected round trips after the recording stopped to be ignored")
+
+	_, stop = m.Start(context.Background())
+	require.Equal(t, RequestMetrics{StatusCodes: map[int]int{}}, stop())
+}
+
+func TestMetricsConcurrentRecordings(t *testing.T) {
+	m := &Metrics{}
+	firstCtx, stopFirst := m.Start(context.Background())
+	secondCtx, stopSecond := m.Start(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m.Observe(firstCtx, time.Millisecond, 200)
+		}()
+		go func() {
+			defer wg.Done()
+			m.Observe(secondCtx, time.Second, 503)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, RequestMetrics{
+		Count:       10,
+		P50:         time.Millisecond,
+		P90:         time.Millisecond,
+		Max:         time.Millisecond,
+		StatusCodes: map[int]int{200: 10},
+	}, stopFirst(), "expected the first recording not to record the round trips of the second one")
+	require.Equal(t, RequestMetrics{
+		Count:       10,
+		P50:         time.Second,
+		P90:         time.Second,
+		Max:         time.Second,
+		StatusCodes: map[int]int{503: 10},
+	}, stopSecond(), "expected the second recording not to record the round trips of the first one")
+}
+
+func TestCaptureRoundTripMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	d := &DefaultRoundTripper{Metrics: &Metrics{}}
+	ctx, stop := d.Metrics.Start(context.Background())
+	for _, path := range []string{"/", "/", "/unavailable"} {
+		u, err := url.Parse(server.URL + path)
+		require.NoError(t, err)
+		_, _, err = d.CaptureRoundTrip(Request{URL: *u, Context: ctx})
+		require.NoError(t, err)
+	}
+	_, _, err := d.CaptureRoundTrip(Request{URL: url.URL{Scheme: "http", Host: "127.0.0.1:1"}, Context: ctx})
+	require.Error(t, err)
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, d.CaptureRoundTripTrace(Request{URL: *u, Context: ctx}).Err)
+	_, _, err = d.CaptureRoundTrip(Request{URL: *u})
+	require.NoError(t, err)
+
+	metrics := stop()
+	require.Equal(t, 5, metrics.Count, "expected the traced round trip to be recorded, and the one without the context of the recording not to")
+	require.Equal(t, map[int]int{200: 3, 503: 1, 0: 1}, metrics.StatusCodes)
+	require.Greater(t, int64(metrics.Max), int64(0))
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// PipelinedRoundTripper is implemented by RoundTrippers that are able to
+// pipeline HTTP/1.1 requests, writing all of them on a single connection
+// before reading any response.
+type PipelinedRoundTripper interface {
+	CapturePipelinedRoundTrip(PipelinedRequest) ([]*CapturedRequest, []*CapturedResponse, error)
+}
+
+// PipelinedRequest is the input for a pipelined round trip.
+type PipelinedRequest struct {
+	// Address is the host:port to connect to.
+	Address string
+	// Requests are written verbatim, back-to-back, to the connection.
+	Requests [][]byte
+}
+
+// CapturePipelinedRoundTrip writes every request to a new connection at once
+// and captures the responses in the order they are received. If the server
+// closes the connection early, e.g. because it doesn't support pipelining,
+// only the responses received until then are returned. An error will be
+// returned if the connection fails, if a response cannot be parsed or if no
+// response was received at all.
+func (d *DefaultRoundTripper) CapturePipelinedRoundTrip(request PipelinedRequest) ([]*CapturedRequest, []*CapturedResponse, error) {
+	conn, err := net.DialTimeout("tcp", request.Address, d.requestTimeout())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(d.requestTimeout())); err != nil {
+		return nil, nil, err
+	}
+
+	data := bytes.Join(request.Requests, nil)
+	if d.Debug {
+		fmt.Printf("Sending Pipelined Requests:\n%s\n\n", formatDump(data, "< "))
+	}
+
+	if _, err = conn.Write(data); err != nil {
+		return nil, nil, err
+	}
+
+	var cReqs []*CapturedRequest
+	var cRess []*CapturedResponse
+	reader := bufio.NewReader(conn)
+	for range request.Requests {
+		resp, err := http.ReadResponse(reader, nil)
+		if err != nil {
+			if len(cRess) > 0 && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+				break
+			}
+			return nil, nil, err
+		}
+
+		if d.Debug {
+			fmt.Printf("Received Pipelined Response Status: %s\n\n", resp.Status)
+		}
+
+		cReq, cRes, err := captureResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		cReqs = append(cReqs, cReq)
+		cRess = append(cRess, cRes)
+	}
+
+	return cReqs, cRess, nil
+}
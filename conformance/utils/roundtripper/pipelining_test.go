@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapturePipelinedRoundTripEarlyClose(t *testing.T) {
+	// The server answers the first request only and closes the connection.
+	addr, _ := rawServer(t, "HTTP/1.1 200 OK\r\nContent-type: application/json\r\nContent-Length: 18\r\nConnection: close\r\n\r\n{\"path\":\"/first\"}\n")
+
+	d := &DefaultRoundTripper{}
+	cReqs, cRess, err := d.CapturePipelinedRoundTrip(PipelinedRequest{
+		Address: addr,
+		Requests: [][]byte{
+			[]byte("GET /first HTTP/1.1\r\nHost: example.com\r\n\r\n"),
+			[]byte("GET /second HTTP/1.1\r\nHost: example.com\r\n\r\n"),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, cRess, 1, "expected only the response sent before the connection was closed")
+	require.Equal(t, 200, cRess[0].StatusCode)
+	require.Equal(t, "/first", cReqs[0].Path)
+}
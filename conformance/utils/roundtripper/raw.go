@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RawRoundTripper is implemented by RoundTrippers that are able to send a
+// request exactly as provided, bypassing the validation performed by HTTP
+// clients. This is used to verify how Gateways handle malformed requests.
+type RawRoundTripper interface {
+	CaptureRawRoundTrip(RawRequest) (*CapturedRequest, *CapturedResponse, error)
+}
+
+// RawResponseRoundTripper is implemented by RoundTrippers that are able to
+// send a raw request and return the response exactly as received. This is
+// used for requests Gateways aren't expected to respond to with a valid
+// HTTP/1.x response, such as HTTP/0.9 requests.
+type RawResponseRoundTripper interface {
+	CaptureRawResponse(RawRequest) ([]byte, error)
+}
+
+// RawRequest is the input for a raw round trip.
+type RawRequest struct {
+	// Address is the host:port to connect to.
+	Address string
+	// Data is written verbatim to the connection.
+	Data []byte
+}
+
+// CaptureRawRoundTrip writes the raw request to a new connection and captures
+// the response to it. An error will be returned if the connection fails or
+// the response cannot be parsed, but not if an HTTP error status code is
+// received.
+func (d *DefaultRoundTripper) CaptureRawRoundTrip(request RawRequest) (*CapturedRequest, *CapturedResponse, error) {
+	conn, err := net.DialTimeout("tcp", request.Address, d.requestTimeout())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(d.requestTimeout())); err != nil {
+		return nil, nil, err
+	}
+
+	if d.Debug {
+		fmt.Printf("Sending Raw Request:\n%s\n\n", formatDump(request.Data, "< "))
+	}
+
+	if _, err = conn.Write(request.Data); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if d.Debug {
+		fmt.Printf("Received Response Status: %s\n\n", resp.Status)
+	}
+
+	return captureResponse(resp)
+}
+
+// CaptureRawResponse writes the raw request to a new connection and returns
+// everything received until the connection is closed, unparsed. An empty
+// response means the connection was closed without a response. An error is
+// returned along with what was received if the connection isn't closed
+// before the deadline.
+func (d *DefaultRoundTripper) CaptureRawResponse(request RawRequest) ([]byte, error) {
+	conn, err := net.DialTimeout("tcp", request.Address, d.requestTimeout())
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(d.requestTimeout())); err != nil {
+		return nil, err
+	}
+
+	if d.Debug {
+		fmt.Printf("Sending Raw Request:\n%s\n\n", formatDump(request.Data, "< "))
+	}
+
+	if _, err = conn.Write(request.Data); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(conn)
+	// A reset closes the connection as well, typically when the Gateway
+	// closes it without reading everything that was sent.
+	if err != nil && !errors.Is(err, syscall.ECONNRESET) {
+		return data, fmt.Errorf("error reading raw response: %w", err)
+	}
+
+	if d.Debug {
+		fmt.Printf("Received Raw Response:\n%s\n\n", formatDump(data, "> "))
+	}
+
+	return data, nil
+}
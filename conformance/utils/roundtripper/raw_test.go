@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rawServer accepts a single connection, records everything written to it up
+// to the end of the request headers and replies with the provided response.
+func rawServer(t *testing.T, response string) (string, <-chan []byte) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var data bytes.Buffer
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadBytes('\n')
+			data.Write(line)
+			if err != nil || bytes.Equal(line, []byte("\r\n")) {
+				break
+			}
+		}
+		received <- data.Bytes()
+		conn.Write([]byte(response))
+	}()
+
+	return listener.Addr().String(), received
+}
+
+func TestCaptureRawRoundTrip(t *testing.T) {
+	addr, received := rawServer(t, "HTTP/1.1 200 OK\r\nContent-type: application/json\r\nContent-Length: 16\r\n\r\n{\"path\":\"/raw\"}\n")
+
+	data := []byte("GET /raw HTTP/1.1\r\nHost: example.com\r\nX-Raw: \xff\x00\x01\r\n\r\n")
+	d := &DefaultRoundTripper{}
+	cReq, cRes, err := d.CaptureRawRoundTrip(RawRequest{Address: addr, Data: data})
+	require.NoError(t, err)
+	require.Equal(t, data, <-received, "expected raw bytes to be delivered as constructed")
+	require.Equal(t, 200, cRes.StatusCode)
+	require.Equal(t, "/raw", cReq.Path)
+}
+
+func TestCaptureRawResponse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	// HTTP/0.9 requests are a single line, without headers.
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		line, _ := bufio.NewReader(conn).ReadBytes('\n')
+		received <- line
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+	}()
+
+	data := []byte("GET /\r\n")
+	d := &DefaultRoundTripper{}
+	response, err := d.CaptureRawResponse(RawRequest{Address: listener.Addr().String(), Data: data})
+	require.NoError(t, err)
+	require.Equal(t, data, <-received, "expected raw bytes to be delivered as constructed")
+	require.Equal(t, "HTTP/1.1 400 Bad Request\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", string(response))
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// RetryConfig configures CaptureRoundTripWithRetry. Zero fields are set to
+// their default.
+type RetryConfig struct {
+	// Budget is how long a request is retried for. Defaults to 30s.
+	Budget time.Duration
+	// InitialBackoff is how long to wait before the first retry, doubling
+	// for every retry up to MaxBackoff. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the longest wait between two retries. Defaults to 5s.
+	MaxBackoff time.Duration
+	// RetryableStatusCodes are the status codes of transient responses,
+	// received while a Gateway programs its routes. Defaults to 404 and 503.
+	RetryableStatusCodes []int
+	// IsRetryableError returns whether a request failing with an error is
+	// transient. Defaults to retrying refused connections.
+	IsRetryableError func(error) bool
+}
+
+// withDefaults returns the configuration with zero fields set to their
+// default.
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.Budget == 0 {
+		c.Budget = 30 * time.Second
+	}
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = 100 * time.Millisecond
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = 5 * time.Second
+	}
+	if c.RetryableStatusCodes == nil {
+		c.RetryableStatusCodes = []int{http.StatusNotFound, http.StatusServiceUnavailable}
+	}
+	if c.IsRetryableError == nil {
+		c.IsRetryableError = func(err error) bool {
+			return errors.Is(err, syscall.ECONNREFUSED)
+		}
+	}
+	return c
+}
+
+// retryableStatusCode returns true if the status code is one of the
+// RetryableStatusCodes.
+func (c RetryConfig) retryableStatusCode(statusCode int) bool {
+	for _, retryable := range c.RetryableStatusCodes {
+		if statusCode == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait before the retry following the provided
+// number of attempts: the exponential backoff is capped to MaxBackoff and
+// jittered to between half of it and all of it.
+func (c RetryConfig) backoff(attempts int) time.Duration {
+	backoff := c.InitialBackoff
+	for i := 1; i < attempts && backoff < c.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > c.MaxBackoff {
+		backoff = c.MaxBackoff
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// CaptureRoundTripWithRetry makes the request with r until the response has
+// the expected status code, retrying with exponential backoff and jitter
+// while requests fail transiently: with one of the RetryableStatusCodes or
+// with an error IsRetryableError accepts. An error is returned as soon as a
+// request fails otherwise, once the Budget is exhausted or once the context of
+// the request is cancelled, along with the last captured request and response
+// if any.
+func CaptureRoundTripWithRetry(r RoundTripper, request Request, expectedStatusCode int, config RetryConfig) (*CapturedRequest, *CapturedResponse, error) {
+	config = config.withDefaults()
+	deadline := time.Now().Add(config.Budget)
+
+	for attempts := 1; ; attempts++ {
+		cReq, cRes, err := r.CaptureRoundTrip(request)
+		switch {
+		case err != nil && !config.IsRetryableError(err):
+			return nil, nil, err
+		case err == nil && cRes.StatusCode == expectedStatusCode:
+			return cReq, cRes, nil
+		case err == nil && !config.retryableStatusCode(cRes.StatusCode):
+			return cReq, cRes, fmt.Errorf("expected status %d, got non-retryable status %d", expectedStatusCode, cRes.StatusCode)
+		}
+
+		backoff := config.backoff(attempts)
+		if time.Now().Add(backoff).After(deadline) {
+			if err != nil {
+				return nil, nil, fmt.Errorf("retry budget of %s exhausted after %d attempts: %w", config.Budget, attempts, err)
+			}
+			return cReq, cRes, fmt.Errorf("retry budget of %s exhausted after %d attempts, last status was %d", config.Budget, attempts, cRes.StatusCode)
+		}
+		select {
+		case <-request.context().Done():
+			return cReq, cRes, fmt.Errorf("retries aborted after %d attempts: %w", attempts, request.context().Err())
+		case <-time.After(backoff):
+		}
+	}
+}
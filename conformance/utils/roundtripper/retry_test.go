@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedRoundTripper responds to requests with the provided status codes in
+// turn, a status code of 0 standing for a refused connection. The last status
+// code is repeated once the script is exhausted.
+type scriptedRoundTripper struct {
+	statusCodes []int
+	attempts    int
+}
+
+func (s *scriptedRoundTripper) CaptureRoundTrip(request Request) (*CapturedRequest, *CapturedResponse, error) {
+	statusCode := s.statusCodes[len(s.statusCodes)-1]
+	if s.attempts < len(s.statusCodes) {
+		statusCode = s.statusCodes[s.attempts]
+	}
+	s.attempts++
+	if statusCode == 0 {
+		return nil, nil, fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)
+	}
+	return &CapturedRequest{Path: request.URL.Path}, &CapturedResponse{StatusCode: statusCode}, nil
+}
+
+func TestCaptureRoundTripWithRetry(t *testing.T) {
+	config := RetryConfig{Budget: 200 * time.Millisecond, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+	request := Request{Method: "GET"}
+
+	t.Run("transient responses and errors are retried", func(t *testing.T) {
+		r := &scriptedRoundTripper{statusCodes: []int{0, http.StatusNotFound, http.StatusServiceUnavailable, http.StatusOK}}
+		_, cRes, err := CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, cRes.StatusCode)
+		require.Equal(t, 4, r.attempts)
+	})
+
+	t.Run("non-retryable responses fail straight away", func(t *testing.T) {
+		r := &scriptedRoundTripper{statusCodes: []int{http.StatusNotFound, http.StatusInternalServerError}}
+		_, cRes, err := CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.Error(t, err)
+		require.Equal(t, http.StatusInternalServerError, cRes.StatusCode)
+		require.Equal(t, 2, r.attempts)
+	})
+
+	t.Run("retryable status codes are configurable", func(t *testing.T) {
+		r := &scriptedRoundTripper{statusCodes: []int{http.StatusInternalServerError, http.StatusOK}}
+		config := config
+		config.RetryableStatusCodes = []int{http.StatusInternalServerError}
+		_, _, err := CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.NoError(t, err)
+
+		r = &scriptedRoundTripper{statusCodes: []int{http.StatusNotFound, http.StatusOK}}
+		_, _, err = CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.Error(t, err, "expected 404 not to be retried")
+		require.Equal(t, 1, r.attempts)
+	})
+
+	t.Run("retryable errors are configurable", func(t *testing.T) {
+		r := &scriptedRoundTripper{statusCodes: []int{0, http.StatusOK}}
+		config := config
+		config.IsRetryableError = func(error) bool { return false }
+		_, _, err := CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.True(t, errors.Is(err, syscall.ECONNREFUSED))
+		require.Equal(t, 1, r.attempts)
+	})
+
+	t.Run("retries stop once the budget is exhausted", func(t *testing.T) {
+		r := &scriptedRoundTripper{statusCodes: []int{http.StatusServiceUnavailable}}
+		start := time.Now()
+		_, cRes, err := CaptureRoundTripWithRetry(r, request, http.StatusOK, config)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "budget")
+		require.Equal(t, http.StatusServiceUnavailable, cRes.StatusCode)
+		require.Greater(t, r.attempts, 2)
+		require.Less(t, int64(time.Since(start)), int64(config.Budget))
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	config := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+	for attempts, expected := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		5:  time.Second,
+		50: time.Second,
+	} {
+		for i := 0; i < 10; i++ {
+			backoff := config.backoff(attempts)
+			require.GreaterOrEqual(t, int64(backoff), int64(expected/2), "backoff after %d attempts", attempts)
+			require.LessOrEqual(t, int64(backoff), int64(expected), "backoff after %d attempts", attempts)
+		}
+	}
+}
+
+func TestCaptureRoundTripWithRetryCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &scriptedRoundTripper{statusCodes: []int{http.StatusServiceUnavailable}}
+	_, _, err := CaptureRoundTripWithRetry(r, Request{Method: "GET", Context: ctx}, http.StatusOK, RetryConfig{Budget: time.Minute})
+	require.True(t, errors.Is(err, context.Canceled), "expected retries to stop once the request context is cancelled, got %v", err)
+	require.Equal(t, 1, r.attempts)
+}
@@ -17,17 +17,31 @@ limitations under the License.
 package roundtripper
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"regexp"
+	"strconv"
 	"time"
+
+	"golang.org/x/net/http2"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
 )
 
+// H2CPriorKnowledgeProtocol can be set as the Protocol of a Request to send it
+// over cleartext HTTP/2 without upgrading from HTTP/1.1 first. The Host of
+// the Request is sent as the :authority pseudo-header.
+const H2CPriorKnowledgeProtocol = "H2C_PRIOR_KNOWLEDGE"
+
 // RoundTripper is an interface used to make requests within conformance tests.
 // This can be overridden with custom implementations whenever necessary.
 type RoundTripper interface {
@@ -41,6 +55,37 @@ type Request struct {
 	Protocol string
 	Method   string
 	Headers  map[string][]string
+	// Body, if set, is sent as the body of the request.
+	Body []byte
+	// Context, if set, aborts the request once cancelled. Defaults to a
+	// background context.
+	Context context.Context
+	// FollowRedirects makes the DefaultRoundTripper follow redirects, up to
+	// maxRedirects of them, capturing the response to the last request along
+	// with the chain of redirects. By default, a redirect response is
+	// captured as is.
+	FollowRedirects bool
+}
+
+// maxRedirects is how many redirects are followed for a request with
+// FollowRedirects set.
+const maxRedirects = 10
+
+// context returns the context of the request, defaulting to a background
+// context.
+func (r Request) context() context.Context {
+	if r.Context != nil {
+		return r.Context
+	}
+	return context.Background()
+}
+
+// body returns a reader for the body of the request, or nil if it has none.
+func (r Request) body() io.Reader {
+	if r.Body == nil {
+		return nil
+	}
+	return bytes.NewReader(r.Body)
 }
 
 // CapturedRequest contains request metadata captured from an echoserver
@@ -54,6 +99,10 @@ type CapturedRequest struct {
 
 	Namespace string `json:"namespace"`
 	Pod       string `json:"pod"`
+	// RemoteAddr is the address of the peer of the backend, reported by
+	// echoservers that support it. Backends accepting the PROXY protocol
+	// report the source address of the PROXY protocol header instead.
+	RemoteAddr string `json:"remoteAddr,omitempty"`
 }
 
 // CapturedResponse contains response metadata.
@@ -62,12 +111,59 @@ type CapturedResponse struct {
 	ContentLength int64
 	Protocol      string
 	Headers       map[string][]string
+	// TLS is set for responses received over TLS.
+	TLS *CapturedTLS
+	// URL is the URL of the request the response is for, which is the
+	// target of the last redirect if redirects were followed.
+	URL string
+	// Redirects are the redirects followed to get the response, in order.
+	Redirects []CapturedRedirect
+}
+
+// CapturedRedirect is a redirect response that was followed.
+type CapturedRedirect struct {
+	// URL is the URL of the request that was redirected.
+	URL        string
+	StatusCode int
+	// Location is the Location header of the redirect, verbatim.
+	Location string
+}
+
+// CapturedTLS contains the metadata of a negotiated TLS connection.
+type CapturedTLS struct {
+	// Version is the TLS version, e.g. "TLS 1.3".
+	Version string
+	// NegotiatedProtocol is the protocol negotiated through ALPN, e.g. "h2",
+	// if any.
+	NegotiatedProtocol string
+	// PeerCertificateSubject is the subject of the certificate presented by
+	// the server.
+	PeerCertificateSubject string
 }
 
 // DefaultRoundTripper is the default implementation of a RoundTripper. It will
 // be used if a custom implementation is not specified.
 type DefaultRoundTripper struct {
 	Debug bool
+	// TLSConfig configures requests to https:// URLs. If nil, the serving
+	// certificate is verified against the system roots.
+	TLSConfig *TLSConfig
+	// Metrics, if set, records the latency and status code of every
+	// CaptureRoundTrip and CaptureRoundTripTrace, for the recording of the
+	// Context of the request.
+	Metrics *Metrics
+	// TimeoutConfig bounds every request with its RequestTimeout, defaulting
+	// to the one of config.DefaultTimeoutConfig.
+	TimeoutConfig config.TimeoutConfig
+}
+
+// requestTimeout returns the RequestTimeout of the TimeoutConfig, or the
+// default one if unset.
+func (d *DefaultRoundTripper) requestTimeout() time.Duration {
+	if d.TimeoutConfig.RequestTimeout == 0 {
+		return config.DefaultTimeoutConfig().RequestTimeout
+	}
+	return d.TimeoutConfig.RequestTimeout
 }
 
 // CaptureRoundTrip makes a request with the provided parameters and returns the
@@ -75,27 +171,82 @@ type DefaultRoundTripper struct {
 // there is an error running the function but not if an HTTP error status code
 // is received.
 func (d *DefaultRoundTripper) CaptureRoundTrip(request Request) (*CapturedRequest, *CapturedResponse, error) {
-	cReq := &CapturedRequest{}
+	start := time.Now()
+	cReq, cRes, _, err := d.roundTrip(request)
+	d.observe(request, start, cRes)
+	return cReq, cRes, err
+}
+
+// observe records the round trip of the request started at start in the
+// Metrics, if set. Round trips that failed without a response are recorded
+// with status code 0.
+func (d *DefaultRoundTripper) observe(request Request, start time.Time, cRes *CapturedResponse) {
+	if d.Metrics == nil {
+		return
+	}
+	statusCode := 0
+	if cRes != nil {
+		statusCode = cRes.StatusCode
+	}
+	d.Metrics.Observe(request.context(), time.Since(start), statusCode)
+}
+
+// roundTrip makes a request with the provided parameters and returns the
+// captured request and response from echoserver, along with the body of the
+// response.
+func (d *DefaultRoundTripper) roundTrip(request Request) (*CapturedRequest, *CapturedResponse, []byte, error) {
 	client := http.DefaultClient
+	switch {
+	case request.Protocol == H2CPriorKnowledgeProtocol:
+		transport := h2cTransport()
+		defer transport.CloseIdleConnections()
+		client = &http.Client{Transport: transport}
+	case request.URL.Scheme == "https":
+		transport, err := d.httpsTransport(request)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		defer transport.CloseIdleConnections()
+		client = &http.Client{Transport: transport}
+	}
+
+	var redirects []CapturedRedirect
+	client = &http.Client{
+		Transport: client.Transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !request.FollowRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			redirects = append(redirects, CapturedRedirect{
+				URL:        via[len(via)-1].URL.String(),
+				StatusCode: req.Response.StatusCode,
+				Location:   req.Response.Header.Get("Location"),
+			})
+			return nil
+		},
+	}
 
 	method := "GET"
 	if request.Method != "" {
 		method = request.Method
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(request.context(), d.requestTimeout())
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, method, request.URL.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, method, request.URL.String(), request.body())
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	if request.Host != "" {
 		req.Host = request.Host
 	}
 
-	if request.Headers != nil {
-		for name, value := range request.Headers {
-			req.Header.Set(name, value[0])
+	for name, values := range request.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
 		}
 	}
 
@@ -103,7 +254,7 @@ func (d *DefaultRoundTripper) CaptureRoundTrip(request Request) (*CapturedReques
 		var dump []byte
 		dump, err = httputil.DumpRequestOut(req, true)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		fmt.Printf("Sending Request:\n%s\n\n", formatDump(dump, "< "))
@@ -111,7 +262,10 @@ func (d *DefaultRoundTripper) CaptureRoundTrip(request Request) (*CapturedReques
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, nil, err
+		if request.URL.Scheme == "https" && isTLSVerificationError(err) {
+			return nil, nil, nil, fmt.Errorf("TLS verification of %s failed: %w", request.URL.Host, err)
+		}
+		return nil, nil, nil, err
 	}
 	defer resp.Body.Close()
 
@@ -119,19 +273,63 @@ func (d *DefaultRoundTripper) CaptureRoundTrip(request Request) (*CapturedReques
 		var dump []byte
 		dump, err = httputil.DumpResponse(resp, true)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		fmt.Printf("Received Response:\n%s\n\n", formatDump(dump, "< "))
 	}
 
-	body, _ := ioutil.ReadAll(resp.Body)
+	cReq, cRes, body, err := captureResponseBody(resp)
+	if cRes != nil {
+		cRes.Redirects = redirects
+	}
+	return cReq, cRes, body, err
+}
+
+// GenerateHeaders returns count distinct headers named headerPrefix followed
+// by their index, e.g. X-Header-0, to be sent with a Request.
+func GenerateHeaders(headerPrefix string, count int) map[string][]string {
+	headers := make(map[string][]string, count)
+	for i := 0; i < count; i++ {
+		headers[fmt.Sprintf("%s%d", headerPrefix, i)] = []string{strconv.Itoa(i)}
+	}
+	return headers
+}
+
+// h2cTransport returns a transport speaking cleartext HTTP/2 with prior
+// knowledge. Its connections must be closed once the request is done.
+func h2cTransport() *http2.Transport {
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}
+}
+
+// captureResponse reads the body of the response, decoding the request
+// metadata reported by echoserver if present, and captures the response
+// metadata. An error is returned if the body is truncated, e.g. because the
+// connection was closed before all of it was received.
+func captureResponse(resp *http.Response) (*CapturedRequest, *CapturedResponse, error) {
+	cReq, cRes, _, err := captureResponseBody(resp)
+	return cReq, cRes, err
+}
+
+// captureResponseBody is like captureResponse but also returns the body of
+// the response.
+func captureResponseBody(resp *http.Response) (*CapturedRequest, *CapturedResponse, []byte, error) {
+	cReq := &CapturedRequest{}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error reading response body: %w", err)
+	}
 
 	// we cannot assume the response is JSON
 	if resp.Header.Get("Content-type") == "application/json" {
 		err = json.Unmarshal(body, cReq)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unexpected error reading response: %w", err)
+			return nil, nil, body, fmt.Errorf("unexpected error reading response: %w", err)
 		}
 	}
 
@@ -141,8 +339,14 @@ func (d *DefaultRoundTripper) CaptureRoundTrip(request Request) (*CapturedReques
 		Protocol:      resp.Proto,
 		Headers:       resp.Header,
 	}
+	if resp.Request != nil {
+		cRes.URL = resp.Request.URL.String()
+	}
+	if resp.TLS != nil {
+		cRes.TLS = captureTLS(resp.TLS)
+	}
 
-	return cReq, cRes, nil
+	return cReq, cRes, body, nil
 }
 
 var startLineRegex = regexp.MustCompile(`(?m)^`)
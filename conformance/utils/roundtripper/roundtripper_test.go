@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+func TestCaptureRoundTripRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			w.Header().Set("Location", "/moved?from=old")
+			w.WriteHeader(http.StatusMovedPermanently)
+		case "/moved":
+			w.Header().Set("Location", "/new")
+			w.WriteHeader(http.StatusTemporaryRedirect)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	oldURL, err := url.Parse(server.URL + "/old")
+	require.NoError(t, err)
+	d := &DefaultRoundTripper{}
+
+	t.Run("not followed", func(t *testing.T) {
+		_, cRes, err := d.CaptureRoundTrip(Request{URL: *oldURL})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusMovedPermanently, cRes.StatusCode)
+		require.Equal(t, []string{"/moved?from=old"}, cRes.Headers["Location"], "expected the Location header verbatim")
+		require.Equal(t, server.URL+"/old", cRes.URL)
+		require.Empty(t, cRes.Redirects)
+	})
+	t.Run("followed", func(t *testing.T) {
+		_, cRes, err := d.CaptureRoundTrip(Request{URL: *oldURL, FollowRedirects: true})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, cRes.StatusCode)
+		require.Equal(t, server.URL+"/new", cRes.URL)
+		require.Equal(t, []CapturedRedirect{
+			{URL: server.URL + "/old", StatusCode: http.StatusMovedPermanently, Location: "/moved?from=old"},
+			{URL: server.URL + "/moved?from=old", StatusCode: http.StatusTemporaryRedirect, Location: "/new"},
+		}, cRes.Redirects)
+	})
+}
+
+func TestCaptureRoundTripRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	u, err := url.Parse(server.URL + "/slow")
+	require.NoError(t, err)
+	d := &DefaultRoundTripper{TimeoutConfig: config.TimeoutConfig{RequestTimeout: 50 * time.Millisecond}}
+
+	start := time.Now()
+	_, _, err = d.CaptureRoundTrip(Request{URL: *u})
+	require.Error(t, err)
+	require.Less(t, int64(time.Since(start)), int64(time.Second), "expected the RequestTimeout to bound the request")
+}
+
+func TestCaptureRoundTripMethodHeadersAndBody(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(CapturedRequest{
+			Path:    r.URL.Path,
+			Method:  r.Method,
+			Headers: r.Header,
+		}))
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL + "/post")
+	require.NoError(t, err)
+	d := &DefaultRoundTripper{}
+
+	cReq, cRes, err := d.CaptureRoundTrip(Request{
+		URL:     *u,
+		Method:  "POST",
+		Headers: map[string][]string{"X-Echo": {"first", "second"}, "Content-Type": {"text/plain"}},
+		Body:    []byte("request body"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, cRes.StatusCode)
+	require.Equal(t, "POST", cReq.Method)
+	require.Equal(t, []string{"first", "second"}, cReq.Headers["X-Echo"], "expected every header value to be sent")
+	require.Equal(t, []string{"text/plain"}, cReq.Headers["Content-Type"])
+	require.Equal(t, []string{"12"}, cReq.Headers["Content-Length"])
+	require.Equal(t, "request body", string(body))
+
+	cReq, _, err = d.CaptureRoundTrip(Request{URL: *u})
+	require.NoError(t, err)
+	require.Equal(t, "GET", cReq.Method, "expected the method to default to GET")
+	require.Empty(t, body)
+}
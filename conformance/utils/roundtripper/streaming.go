@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"time"
+)
+
+// streamingTimeout bounds how long a streamed response may take as a whole.
+const streamingTimeout = 60 * time.Second
+
+// StreamingRoundTripper is implemented by RoundTrippers that are able to
+// capture when each part of a response body is received, rather than only
+// the body as a whole. This is used to verify Gateways don't buffer streamed
+// responses such as server-sent events.
+type StreamingRoundTripper interface {
+	CaptureStreamingRoundTrip(Request) (*CapturedStreamingResponse, error)
+}
+
+// CapturedStreamingResponse contains the response metadata and the chunks
+// of the body in the order they were received.
+type CapturedStreamingResponse struct {
+	StatusCode int
+	Headers    map[string][]string
+	Chunks     []StreamChunk
+}
+
+// StreamChunk is the data returned by a single read of a response body.
+type StreamChunk struct {
+	Data []byte
+	// Elapsed is the time since the request was sent.
+	Elapsed time.Duration
+}
+
+// CaptureStreamingRoundTrip makes a request with the provided parameters and
+// reads the response body as it arrives, recording when each chunk was
+// received. Compression is disabled so that chunks are captured as sent by
+// the Gateway. An error will be returned if the body is truncated, but not
+// if an HTTP error status code is received.
+func (d *DefaultRoundTripper) CaptureStreamingRoundTrip(request Request) (*CapturedStreamingResponse, error) {
+	transport := &http.Transport{}
+	if request.URL.Scheme == "https" {
+		var err error
+		if transport, err = d.httpsTransport(request); err != nil {
+			return nil, err
+		}
+	}
+	transport.DisableCompression = true
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+
+	method := "GET"
+	if request.Method != "" {
+		method = request.Method
+	}
+	ctx, cancel := context.WithTimeout(request.context(), streamingTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, request.URL.String(), request.body())
+	if err != nil {
+		return nil, err
+	}
+	if request.Host != "" {
+		req.Host = request.Host
+	}
+	for name, value := range request.Headers {
+		req.Header.Set(name, value[0])
+	}
+
+	if d.Debug {
+		var dump []byte
+		dump, err = httputil.DumpRequestOut(req, false)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Sending Streaming Request:\n%s\n\n", formatDump(dump, "< "))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	cRes := &CapturedStreamingResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			cRes.Chunks = append(cRes.Chunks, StreamChunk{
+				Data:    append([]byte{}, buf[:n]...),
+				Elapsed: time.Since(start),
+			})
+			if d.Debug {
+				fmt.Printf("Received Stream Chunk after %s:\n%s\n\n", time.Since(start), formatDump(buf[:n], "< "))
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return cRes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading streamed response body: %w", err)
+		}
+	}
+}
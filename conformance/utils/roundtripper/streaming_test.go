@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureStreamingRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			_, _ = w.Write([]byte("data: event\n\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	cRes, err := (&DefaultRoundTripper{}).CaptureStreamingRoundTrip(Request{URL: *serverURL})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, cRes.StatusCode)
+	require.Equal(t, "text/event-stream", cRes.Headers["Content-Type"][0])
+	require.Len(t, cRes.Chunks, 3, "expected every flushed event to be read separately")
+	for i, chunk := range cRes.Chunks {
+		require.Equal(t, "data: event\n\n", string(chunk.Data))
+		if i > 0 {
+			require.GreaterOrEqual(t, int64(chunk.Elapsed-cRes.Chunks[i-1].Elapsed), int64(40*time.Millisecond), "expected events to be received as they were flushed")
+		}
+	}
+}
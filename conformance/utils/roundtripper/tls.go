@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// TLSHandshakeRoundTripper is implemented by RoundTrippers that are able to
+// perform TLS handshakes and report the resulting connection state.
+type TLSHandshakeRoundTripper interface {
+	CaptureTLSHandshakes(TLSHandshakeRequest) ([]tls.ConnectionState, error)
+}
+
+// TLSHandshakeRequest is the input for capturing TLS handshakes.
+type TLSHandshakeRequest struct {
+	// Address is the host:port to connect to.
+	Address string
+	// ServerName is sent as SNI and used to verify the serving certificate.
+	ServerName string
+	// Config is the base client configuration, it is cloned before use. A
+	// ClientSessionCache is added if it doesn't have one.
+	Config *tls.Config
+	// Handshakes is the number of sequential connections to make, all
+	// sharing the same session cache. Defaults to 1.
+	Handshakes int
+	// RequireOCSPStaple fails handshakes in which the server doesn't staple
+	// an OCSP response, exposed as OCSPResponse in the connection state. Go
+	// clients always request OCSP stapling.
+	RequireOCSPStaple bool
+}
+
+// CaptureTLSHandshakes makes the requested number of TLS connections in a row
+// and returns the state of each connection once a request was made over it.
+// Making a request ensures session tickets sent after the handshake, as is
+// the case with TLS 1.3, are received before the connection is closed.
+func (d *DefaultRoundTripper) CaptureTLSHandshakes(request TLSHandshakeRequest) ([]tls.ConnectionState, error) {
+	config := &tls.Config{}
+	if request.Config != nil {
+		config = request.Config.Clone()
+	}
+	if request.ServerName != "" {
+		config.ServerName = request.ServerName
+	}
+	if config.ClientSessionCache == nil {
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	handshakes := request.Handshakes
+	if handshakes == 0 {
+		handshakes = 1
+	}
+
+	states := make([]tls.ConnectionState, 0, handshakes)
+	for i := 0; i < handshakes; i++ {
+		state, err := d.tlsHandshake(request.Address, config)
+		if err != nil {
+			return nil, fmt.Errorf("error performing TLS handshake %d: %w", i+1, err)
+		}
+		if request.RequireOCSPStaple && len(state.OCSPResponse) == 0 {
+			return nil, fmt.Errorf("no OCSP response stapled in TLS handshake %d", i+1)
+		}
+		if d.Debug {
+			fmt.Printf("TLS handshake %d with %s: version %x, resumed %t\n\n", i+1, request.Address, state.Version, state.DidResume)
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+func (d *DefaultRoundTripper) tlsHandshake(address string, config *tls.Config) (tls.ConnectionState, error) {
+	dialer := &net.Dialer{Timeout: d.requestTimeout()}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, config)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(d.requestTimeout())); err != nil {
+		return tls.ConnectionState{}, err
+	}
+
+	host := config.ServerName
+	if host == "" {
+		host = address
+	}
+	req, err := http.NewRequest("HEAD", "https://"+host+"/", nil)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	req.Close = true
+	if err = req.Write(conn); err != nil {
+		return tls.ConnectionState{}, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	resp.Body.Close()
+
+	return conn.ConnectionState(), nil
+}
+
+// TLSConfig configures how DefaultRoundTripper negotiates TLS with Gateways.
+// Certificates and keys are PEM encoded.
+type TLSConfig struct {
+	// CACertificates is the bundle of CA certificates trusted to issue the
+	// serving certificate of the Gateway. If empty, the system roots are
+	// trusted.
+	CACertificates []byte
+	// ServerName overrides the name sent as SNI and against which the
+	// serving certificate is verified. It defaults to the Host of the
+	// request, or to the host of its URL if the Host isn't set.
+	ServerName string
+	// ClientCertificate and ClientKey are presented to Gateways requiring
+	// mutual TLS, if set.
+	ClientCertificate []byte
+	ClientKey         []byte
+}
+
+// clientConfig returns the client configuration negotiating TLS with
+// serverName.
+func (c *TLSConfig) clientConfig(serverName string) (*tls.Config, error) {
+	config := &tls.Config{ServerName: serverName}
+	if c == nil {
+		return config, nil
+	}
+	if c.ServerName != "" {
+		config.ServerName = c.ServerName
+	}
+	if len(c.CACertificates) > 0 {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(c.CACertificates) {
+			return nil, errors.New("no CA certificate found in the CA bundle")
+		}
+	}
+	if len(c.ClientCertificate) > 0 || len(c.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(c.ClientCertificate, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// httpsTransport returns a transport negotiating TLS for the request as
+// configured by TLSConfig.
+func (d *DefaultRoundTripper) httpsTransport(request Request) (*http.Transport, error) {
+	serverName := request.Host
+	if host, _, err := net.SplitHostPort(serverName); err == nil {
+		serverName = host
+	}
+	config, err := d.TLSConfig.clientConfig(serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return transport, nil
+}
+
+// tlsVersions maps TLS versions to their name.
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// captureTLS captures the metadata of a TLS connection.
+func captureTLS(state *tls.ConnectionState) *CapturedTLS {
+	captured := &CapturedTLS{
+		Version:            tlsVersions[state.Version],
+		NegotiatedProtocol: state.NegotiatedProtocol,
+	}
+	if captured.Version == "" {
+		captured.Version = fmt.Sprintf("0x%04x", state.Version)
+	}
+	if len(state.PeerCertificates) > 0 {
+		captured.PeerCertificateSubject = state.PeerCertificates[0].Subject.String()
+	}
+	return captured
+}
+
+// isTLSVerificationError returns true if err is caused by the serving
+// certificate failing verification.
+func isTLSVerificationError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname)
+}
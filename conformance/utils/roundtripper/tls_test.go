@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSServer starts an HTTPS server with a certificate valid for
+// example.com and returns it along with its PEM encoded certificate and key.
+// If requireClientCert is true, the server rejects requests without a client
+// certificate.
+func newTLSServer(t *testing.T, requireClientCert bool) (*httptest.Server, []byte, []byte) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.EnableHTTP2 = true
+	if requireClientCert {
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	cert := server.TLS.Certificates[0]
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+	return server, certPEM, keyPEM
+}
+
+func TestCaptureRoundTripHTTPS(t *testing.T) {
+	server, certPEM, keyPEM := newTLSServer(t, false)
+	request := Request{
+		URL:  url.URL{Scheme: "https", Host: server.Listener.Addr().String(), Path: "/"},
+		Host: "example.com",
+	}
+
+	t.Run("verified", func(t *testing.T) {
+		d := &DefaultRoundTripper{TLSConfig: &TLSConfig{CACertificates: certPEM}}
+		_, cRes, err := d.CaptureRoundTrip(request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, cRes.StatusCode)
+		require.NotNil(t, cRes.TLS, "expected TLS metadata to be captured")
+		require.Equal(t, "TLS 1.3", cRes.TLS.Version)
+		require.Equal(t, "h2", cRes.TLS.NegotiatedProtocol)
+		require.Contains(t, cRes.TLS.PeerCertificateSubject, "Acme Co")
+	})
+
+	t.Run("untrusted", func(t *testing.T) {
+		d := &DefaultRoundTripper{}
+		_, _, err := d.CaptureRoundTrip(request)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "TLS verification of")
+	})
+
+	t.Run("server name override", func(t *testing.T) {
+		d := &DefaultRoundTripper{TLSConfig: &TLSConfig{CACertificates: certPEM, ServerName: "wrong.example.org"}}
+		_, _, err := d.CaptureRoundTrip(request)
+		require.Error(t, err, "expected certificate not to be valid for the overridden server name")
+		require.Contains(t, err.Error(), "TLS verification of")
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		mtlsServer, mtlsCertPEM, _ := newTLSServer(t, true)
+		mtlsRequest := request
+		mtlsRequest.URL.Host = mtlsServer.Listener.Addr().String()
+
+		d := &DefaultRoundTripper{TLSConfig: &TLSConfig{CACertificates: mtlsCertPEM}}
+		_, _, err := d.CaptureRoundTrip(mtlsRequest)
+		require.Error(t, err, "expected request without a client certificate to be rejected")
+
+		d.TLSConfig.ClientCertificate, d.TLSConfig.ClientKey = certPEM, keyPEM
+		_, cRes, err := d.CaptureRoundTrip(mtlsRequest)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, cRes.StatusCode)
+	})
+
+	t.Run("invalid CA bundle", func(t *testing.T) {
+		d := &DefaultRoundTripper{TLSConfig: &TLSConfig{CACertificates: []byte("not a certificate")}}
+		_, _, err := d.CaptureRoundTrip(request)
+		require.EqualError(t, err, "no CA certificate found in the CA bundle")
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// traceBodySnippetLength is how much of a response body is kept in a Trace.
+const traceBodySnippetLength = 512
+
+// TracingRoundTripper is implemented by RoundTrippers that are able to trace
+// what was sent and received in a round trip, including the body of the
+// response. Traces are logged when a test fails so that the actual request
+// and response can be compared with the expected ones.
+type TracingRoundTripper interface {
+	CaptureRoundTripTrace(Request) *Trace
+}
+
+// Trace records a round trip: the request as sent, the response received, if
+// any, and the backend that served it according to the echo response.
+type Trace struct {
+	Request Request
+	// CapturedRequest is the request reported by the backend, if the
+	// response was an echo response.
+	CapturedRequest *CapturedRequest
+	// CapturedResponse is nil if no response was received.
+	CapturedResponse *CapturedResponse
+	// BodySnippet is the start of the response body, if any.
+	BodySnippet string
+	// Err is the error the round trip failed with, if any.
+	Err error
+}
+
+// CaptureRoundTripTrace makes a request with the provided parameters and
+// returns its Trace. Errors making the request are recorded in the Trace.
+func (d *DefaultRoundTripper) CaptureRoundTripTrace(request Request) *Trace {
+	start := time.Now()
+	cReq, cRes, body, err := d.roundTrip(request)
+	d.observe(request, start, cRes)
+	trace := &Trace{Request: request, CapturedRequest: cReq, CapturedResponse: cRes, Err: err}
+	if len(body) > traceBodySnippetLength {
+		body = append(body[:traceBodySnippetLength:traceBodySnippetLength], "..."...)
+	}
+	trace.BodySnippet = string(body)
+	return trace
+}
+
+// String formats the trace for test logs.
+func (tr *Trace) String() string {
+	var b strings.Builder
+
+	method := tr.Request.Method
+	if method == "" {
+		method = "GET"
+	}
+	fmt.Fprintf(&b, "Request: %s %s", method, tr.Request.URL.String())
+	if tr.Request.Host != "" {
+		fmt.Fprintf(&b, " (Host: %s)", tr.Request.Host)
+	}
+	b.WriteString("\n")
+	writeTraceHeaders(&b, tr.Request.Headers)
+
+	if tr.Err != nil {
+		fmt.Fprintf(&b, "Error: %v\n", tr.Err)
+	}
+	if tr.CapturedResponse != nil {
+		fmt.Fprintf(&b, "Response: %s %d\n", tr.CapturedResponse.Protocol, tr.CapturedResponse.StatusCode)
+		writeTraceHeaders(&b, tr.CapturedResponse.Headers)
+	}
+	if tr.BodySnippet != "" {
+		fmt.Fprintf(&b, "Body: %s\n", strings.TrimSpace(tr.BodySnippet))
+	}
+	if tr.CapturedRequest != nil && tr.CapturedRequest.Pod != "" {
+		fmt.Fprintf(&b, "Backend: pod %s in namespace %s received %s %s\n", tr.CapturedRequest.Pod, tr.CapturedRequest.Namespace, tr.CapturedRequest.Method, tr.CapturedRequest.Path)
+	}
+	return b.String()
+}
+
+// writeTraceHeaders writes headers sorted by name, one per line.
+func writeTraceHeaders(b *strings.Builder, headers map[string][]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(b, "  %s: %s\n", name, strings.Join(headers[name], ", "))
+	}
+}
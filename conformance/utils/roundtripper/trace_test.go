@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureRoundTripTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/json")
+		_, _ = w.Write([]byte(`{"path": "/trace", "method": "GET", "namespace": "gateway-conformance-infra", "pod": "infra-backend-v2-7f9c8d6b5-x2x9z", "padding": "` + strings.Repeat("x", traceBodySnippetLength) + `"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL + "/trace")
+	require.NoError(t, err)
+
+	trace := (&DefaultRoundTripper{}).CaptureRoundTripTrace(Request{URL: *serverURL, Host: "example.com", Headers: map[string][]string{"X-Trace": {"1"}}})
+	require.NoError(t, trace.Err)
+	require.Equal(t, http.StatusOK, trace.CapturedResponse.StatusCode)
+	require.Equal(t, "infra-backend-v2-7f9c8d6b5-x2x9z", trace.CapturedRequest.Pod)
+	require.Len(t, trace.BodySnippet, traceBodySnippetLength+len("..."), "expected the body to be truncated")
+
+	formatted := trace.String()
+	require.Contains(t, formatted, "Request: GET "+server.URL+"/trace (Host: example.com)")
+	require.Contains(t, formatted, "  X-Trace: 1")
+	require.Contains(t, formatted, "Response: HTTP/1.1 200")
+	require.Contains(t, formatted, "Backend: pod infra-backend-v2-7f9c8d6b5-x2x9z in namespace gateway-conformance-infra received GET /trace")
+}
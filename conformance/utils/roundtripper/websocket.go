@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+)
+
+// websocketGUID is the fixed GUID used to compute Sec-WebSocket-Accept, as
+// defined by RFC 6455.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// MaxWebSocketFrameSize is the largest payload ReadWebSocketFrame accepts, so
+// that a broken Gateway announcing a huge frame can't exhaust the memory of
+// the suite.
+const MaxWebSocketFrameSize = 1 << 20
+
+// WebSocket frame opcodes used by the conformance tests.
+const (
+	WebSocketOpText  byte = 0x1
+	WebSocketOpClose byte = 0x8
+)
+
+// WebSocketRoundTripper is implemented by RoundTrippers that are able to
+// perform a WebSocket handshake and exchange messages over the upgraded
+// connection.
+type WebSocketRoundTripper interface {
+	CaptureWebSocketRoundTrip(WebSocketRequest) (*CapturedWebSocketResponse, error)
+}
+
+// WebSocketRequest is the input for a WebSocket round trip. The URL must use
+// the http scheme; each message is sent as a text frame and one frame is read
+// back for it.
+type WebSocketRequest struct {
+	URL      url.URL
+	Host     string
+	Headers  map[string][]string
+	Messages []string
+}
+
+// CapturedWebSocketResponse contains the handshake response metadata and the
+// messages received over the upgraded connection.
+type CapturedWebSocketResponse struct {
+	StatusCode int
+	Headers    map[string][]string
+	Messages   []string
+	// RequestHeaders are the headers of the handshake request as sent.
+	RequestHeaders map[string][]string
+	// BackendRequest is the request echoed by the backend when the
+	// connection wasn't upgraded, if any. It tells whether the upgrade
+	// headers made it through the Gateway.
+	BackendRequest *CapturedRequest
+}
+
+// CaptureWebSocketRoundTrip performs a WebSocket handshake with the provided
+// parameters, sends each message and captures the reply to it, all within the
+// RequestTimeout of the TimeoutConfig. An error will be returned if the
+// handshake response is malformed, but not if the server refuses the upgrade;
+// in that case no messages are exchanged.
+func (d *DefaultRoundTripper) CaptureWebSocketRoundTrip(request WebSocketRequest) (*CapturedWebSocketResponse, error) {
+	timeout := d.requestTimeout()
+	conn, err := net.DialTimeout("tcp", request.URL.Host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", request.URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if request.Host != "" {
+		req.Host = request.Host
+	}
+	for name, values := range request.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	key, err := newWebSocketKey()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	if d.Debug {
+		var dump []byte
+		dump, err = httputil.DumpRequestOut(req, false)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Sending WebSocket Handshake:\n%s\n\n", formatDump(dump, "< "))
+	}
+
+	if err = req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if d.Debug {
+		var dump []byte
+		dump, err = httputil.DumpResponse(resp, false)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Received WebSocket Handshake:\n%s\n\n", formatDump(dump, "< "))
+	}
+
+	cRes := &CapturedWebSocketResponse{
+		StatusCode:     resp.StatusCode,
+		Headers:        resp.Header,
+		RequestHeaders: req.Header,
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		cRes.BackendRequest = echoedRequest(resp)
+		return cRes, nil
+	}
+
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != WebSocketAccept(key) {
+		return nil, fmt.Errorf("unexpected Sec-WebSocket-Accept %q in handshake response", accept)
+	}
+
+	for _, msg := range request.Messages {
+		if err = WriteWebSocketFrame(conn, WebSocketOpText, []byte(msg), true); err != nil {
+			return nil, fmt.Errorf("error writing WebSocket frame: %w", err)
+		}
+		var opcode byte
+		var payload []byte
+		opcode, payload, err = ReadWebSocketFrame(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading WebSocket frame: %w", err)
+		}
+		if opcode == WebSocketOpClose {
+			return nil, errors.New("WebSocket connection closed before all messages were echoed")
+		}
+		cRes.Messages = append(cRes.Messages, string(payload))
+	}
+
+	// Closing politely is best effort, the connection is torn down regardless.
+	_ = WriteWebSocketFrame(conn, WebSocketOpClose, nil, true)
+
+	return cRes, nil
+}
+
+// echoedRequest returns the request echoed by the backend in the body of the
+// response, or nil if the response doesn't come from an echo backend.
+func echoedRequest(resp *http.Response) *CapturedRequest {
+	if resp.Header.Get("Content-Type") != "application/json" {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil
+	}
+	cReq := &CapturedRequest{}
+	if err := json.Unmarshal(body, cReq); err != nil {
+		return nil
+	}
+	return cReq
+}
+
+func newWebSocketKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// WebSocketAccept returns the Sec-WebSocket-Accept value a server must answer
+// with for the provided Sec-WebSocket-Key.
+func WebSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// WriteWebSocketFrame writes a single, final WebSocket frame. Clients must
+// mask the frames they send while servers must not.
+func WriteWebSocketFrame(w io.Writer, opcode byte, payload []byte, mask bool) error {
+	header := []byte{0x80 | opcode, 0}
+	length := len(payload)
+	switch {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header[1] = 127
+		header = append(header, make([]byte, 8)...)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	data := payload
+	if mask {
+		header[1] |= 0x80
+		maskKey := make([]byte, 4)
+		if _, err := rand.Read(maskKey); err != nil {
+			return err
+		}
+		header = append(header, maskKey...)
+		data = make([]byte, length)
+		for i := range payload {
+			data[i] = payload[i] ^ maskKey[i%4]
+		}
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadWebSocketFrame reads a single WebSocket frame, unmasking its payload if
+// required. Fragmented messages and payloads larger than
+// MaxWebSocketFrameSize are not supported.
+func ReadWebSocketFrame(r *bufio.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	if header[0]&0x80 == 0 {
+		return 0, nil, errors.New("fragmented WebSocket frames are not supported")
+	}
+	opcode := header[0] & 0x0F
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	if length > MaxWebSocketFrameSize {
+		return 0, nil, fmt.Errorf("WebSocket frame of %d bytes exceeds the limit of %d bytes", length, MaxWebSocketFrameSize)
+	}
+
+	var maskKey []byte
+	if header[1]&0x80 != 0 {
+		maskKey = make([]byte, 4)
+		if _, err := io.ReadFull(r, maskKey); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if maskKey != nil {
+		for i := range payload {
+			payload[i] ^= maskKey[i%4]
+		}
+	}
+
+	return opcode, payload, nil
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package roundtripper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+)
+
+// websocketEchoHandler upgrades the connection and echoes every text frame it
+// receives until the client closes the connection.
+func websocketEchoHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", WebSocketAccept(r.Header.Get("Sec-WebSocket-Key")))
+		require.NoError(t, rw.Flush())
+
+		for {
+			opcode, payload, err := ReadWebSocketFrame(rw.Reader)
+			if err != nil || opcode == WebSocketOpClose {
+				return
+			}
+			if err = WriteWebSocketFrame(conn, opcode, payload, false); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestCaptureWebSocketRoundTrip(t *testing.T) {
+	server := httptest.NewServer(websocketEchoHandler(t))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	d := &DefaultRoundTripper{}
+	cRes, err := d.CaptureWebSocketRoundTrip(WebSocketRequest{
+		URL:      url.URL{Scheme: "http", Host: serverURL.Host, Path: "/ws"},
+		Messages: []string{"hello", "world"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, cRes.StatusCode)
+	require.Equal(t, []string{"hello", "world"}, cRes.Messages)
+}
+
+func TestCaptureWebSocketRoundTripRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	d := &DefaultRoundTripper{}
+	cRes, err := d.CaptureWebSocketRoundTrip(WebSocketRequest{
+		URL:      url.URL{Scheme: "http", Host: serverURL.Host, Path: "/ws"},
+		Messages: []string{"hello"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, cRes.StatusCode)
+	require.Empty(t, cRes.Messages)
+}
+
+func TestCaptureWebSocketRoundTripUpgradeStripped(t *testing.T) {
+	// Echoes the request as a Gateway stripping hop-by-hop headers would
+	// forward it.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers := r.Header.Clone()
+		headers.Del("Upgrade")
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(CapturedRequest{Path: r.URL.Path, Method: r.Method, Headers: headers}))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	d := &DefaultRoundTripper{}
+	cRes, err := d.CaptureWebSocketRoundTrip(WebSocketRequest{
+		URL:      url.URL{Scheme: "http", Host: serverURL.Host, Path: "/ws"},
+		Messages: []string{"hello"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, cRes.StatusCode)
+	require.Equal(t, []string{"websocket"}, cRes.RequestHeaders["Upgrade"], "expected the sent handshake headers to be captured")
+	require.NotNil(t, cRes.BackendRequest, "expected the echoed request to be captured")
+	require.Equal(t, "/ws", cRes.BackendRequest.Path)
+	require.NotContains(t, cRes.BackendRequest.Headers, "Upgrade")
+}
+
+func TestCaptureWebSocketRoundTripHeaders(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	d := &DefaultRoundTripper{}
+	_, err = d.CaptureWebSocketRoundTrip(WebSocketRequest{
+		URL:     url.URL{Scheme: "http", Host: serverURL.Host, Path: "/ws"},
+		Headers: map[string][]string{"X-Multi": {"one", "two"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"one", "two"}, received["X-Multi"], "expected every value of multi-valued headers to be sent")
+}
+
+func TestCaptureWebSocketRoundTripTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		// Accepts the connection but never answers the handshake.
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+
+	d := &DefaultRoundTripper{TimeoutConfig: config.TimeoutConfig{RequestTimeout: 50 * time.Millisecond}}
+	start := time.Now()
+	_, err = d.CaptureWebSocketRoundTrip(WebSocketRequest{
+		URL: url.URL{Scheme: "http", Host: listener.Addr().String(), Path: "/ws"},
+	})
+	require.Error(t, err)
+	require.Less(t, int64(time.Since(start)), int64(time.Second), "expected the RequestTimeout to bound the handshake")
+}
+
+func TestReadWebSocketFrameTooLarge(t *testing.T) {
+	// A final binary frame announcing a 64-bit payload length of 1 GiB.
+	frame := []byte{0x82, 127, 0, 0, 0, 0, 0x40, 0, 0, 0}
+	_, _, err := ReadWebSocketFrame(bufio.NewReader(bytes.NewReader(frame)))
+	require.EqualError(t, err, "WebSocket frame of 1073741824 bytes exceeds the limit of 1048576 bytes")
+}
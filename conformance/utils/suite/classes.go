@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+)
+
+// NewPerGatewayClass returns a ConformanceTestSuite for each of the
+// GatewayClassNames of the options, to be run with RunPerGatewayClass. Every
+// suite is created by New with the options, except that it tests a single
+// GatewayClass, always cleans up its base resources so that the ones bound
+// to the next GatewayClass are created anew, and writes its report, if any,
+// to ReportOutput suffixed with the name of its GatewayClass. Likewise, the
+// failed tests it reruns are read from RerunFailedFrom suffixed with the name
+// of its GatewayClass. An error is returned if no or duplicate
+// GatewayClassNames are set.
+func NewPerGatewayClass(s Options) ([]*ConformanceTestSuite, error) {
+	if len(s.GatewayClassNames) == 0 {
+		return nil, errors.New("no GatewayClassNames set")
+	}
+
+	// Build the client once for all the suites.
+	c, err := optionsClient(s)
+	if err != nil {
+		return nil, err
+	}
+	s.Client = c
+
+	suites := make([]*ConformanceTestSuite, 0, len(s.GatewayClassNames))
+	for i, name := range s.GatewayClassNames {
+		if slices.Contains(s.GatewayClassNames[:i], name) {
+			return nil, fmt.Errorf("duplicate GatewayClass %q", name)
+		}
+
+		opts := s
+		opts.GatewayClassName = name
+		opts.GatewayClassNames = nil
+		opts.CleanupBaseResources = true
+		if s.ReportOutput != "" {
+			opts.ReportOutput = gatewayClassPath(s.ReportOutput, name)
+		}
+		if s.RerunFailedFrom != "" {
+			opts.RerunFailedFrom = gatewayClassPath(s.RerunFailedFrom, name)
+		}
+		suite, err := New(opts)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing suite for %s GatewayClass: %w", name, err)
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+// RunPerGatewayClass sets up and runs the tests for each of the suites in
+// turn, in a subtest named after the GatewayClass of the suite. As the base
+// resources of every GatewayClass share the same names, the namespaces of a
+// suite must be deleted before the next one is set up.
+func RunPerGatewayClass(ctx context.Context, t *testing.T, suites []*ConformanceTestSuite, tests []ConformanceTest) {
+	for _, suite := range suites {
+		suite := suite
+		t.Run(suite.GatewayClassName, func(t *testing.T) {
+			suite.SetupWithContext(ctx, t)
+			suite.RunWithContext(ctx, t, tests)
+		})
+		kubernetes.NamespacesMustBeDeletedWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.Namespaces)
+	}
+}
+
+// gatewayClassPath returns the path with the name of the GatewayClass
+// inserted before its extension, e.g. report-gateway-conformance.json.
+func gatewayClassPath(path, gatewayClassName string) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%s%s", strings.TrimSuffix(path, ext), gatewayClassName, ext)
+}
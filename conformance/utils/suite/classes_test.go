@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// readyGatewayClient stands in for the controllers of every GatewayClass,
+// marking created Gateways as ready and recording the GatewayClass of every
+// same-namespace Gateway created.
+type readyGatewayClient struct {
+	*recordingClient
+	gatewayClasses []string
+}
+
+func (c *readyGatewayClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if uObj, ok := obj.(*unstructured.Unstructured); ok && uObj.GetKind() == "Gateway" {
+		className, _, _ := unstructured.NestedString(uObj.Object, "spec", "gatewayClassName")
+		if uObj.GetName() == "same-namespace" {
+			c.gatewayClasses = append(c.gatewayClasses, className)
+		}
+		conditions := []interface{}{map[string]interface{}{
+			"type":               "Ready",
+			"status":             "True",
+			"reason":             "Ready",
+			"lastTransitionTime": "2022-01-01T00:00:00Z",
+		}}
+		if err := unstructured.SetNestedSlice(uObj.Object, conditions, "status", "conditions"); err != nil {
+			return err
+		}
+	}
+	return c.recordingClient.Create(ctx, obj, opts...)
+}
+
+func acceptedGatewayClass(name, controllerName string) *v1alpha2.GatewayClass {
+	return &v1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha2.GatewayClassSpec{ControllerName: v1alpha2.GatewayController(controllerName)},
+		Status: v1alpha2.GatewayClassStatus{
+			Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+		},
+	}
+}
+
+func TestNewPerGatewayClass(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.json")
+	suites, err := NewPerGatewayClass(Options{
+		GatewayClassNames:    []string{"tier-a", "tier-b"},
+		CleanupBaseResources: false,
+		ReportOutput:         output,
+		SupportedFeatures:    []SupportedFeature{SupportReferencePolicy},
+	})
+	require.NoError(t, err)
+	require.Len(t, suites, 2)
+
+	for i, name := range []string{"tier-a", "tier-b"} {
+		require.Equal(t, name, suites[i].GatewayClassName)
+		require.True(t, suites[i].Cleanup, "expected base resources to be cleaned up between GatewayClasses")
+		require.Equal(t, filepath.Join(filepath.Dir(output), "report-"+name+".json"), suites[i].ReportOutput)
+		require.Equal(t, []SupportedFeature{SupportReferencePolicy}, suites[i].SupportedFeatures)
+	}
+
+	_, err = NewPerGatewayClass(Options{})
+	require.Error(t, err, "expected an error without GatewayClassNames")
+	_, err = NewPerGatewayClass(Options{GatewayClassNames: []string{"tier-a", "tier-a"}})
+	require.Error(t, err, "expected an error for duplicate GatewayClassNames")
+}
+
+func TestRunPerGatewayClass(t *testing.T) {
+	c := &readyGatewayClient{recordingClient: newRecordingClient(t,
+		acceptedGatewayClass("tier-a", "example.com/tier-a"),
+		acceptedGatewayClass("tier-b", "example.com/tier-b"),
+	)}
+	suites, err := NewPerGatewayClass(Options{
+		Client:            c,
+		GatewayClassNames: []string{"tier-a", "tier-b"},
+		MinChannel:        StandardChannel,
+	})
+	require.NoError(t, err)
+
+	// Every test records the GatewayClass of the base Gateway it runs
+	// against, which must be the one of its suite.
+	ranAgainst := map[string]string{}
+	tests := []ConformanceTest{{
+		ShortName:  "BaseGateway",
+		MinChannel: StandardChannel,
+		Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			gw := &v1alpha2.Gateway{}
+			require.NoError(t, s.Client.Get(ctx, types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}, gw))
+			ranAgainst[s.GatewayClassName] = string(gw.Spec.GatewayClassName)
+		},
+	}}
+
+	t.Run("run", func(t *testing.T) {
+		RunPerGatewayClass(context.Background(), t, suites, tests)
+	})
+
+	require.Equal(t, map[string]string{"tier-a": "tier-a", "tier-b": "tier-b"}, ranAgainst)
+	require.Equal(t, []string{"tier-a", "tier-b"}, c.gatewayClasses, "expected the base Gateway to be created once per GatewayClass")
+	require.Equal(t, "example.com/tier-a", suites[0].ControllerName)
+	require.Equal(t, "example.com/tier-b", suites[1].ControllerName)
+	for _, suite := range suites {
+		report, err := suite.Report()
+		require.NoError(t, err)
+		require.Equal(t, suite.GatewayClassName, report.GatewayClassName)
+		require.Len(t, report.Tests, 1)
+	}
+
+	err = c.Get(context.Background(), types.NamespacedName{Name: "same-namespace", Namespace: "gateway-conformance-infra"}, &v1alpha2.Gateway{})
+	require.True(t, apierrors.IsNotFound(err), "expected base resources to be deleted once all GatewayClasses ran")
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// NewScheme returns a scheme with every Gateway API version registered, along
+// with the core Kubernetes types conformance tests use, such as Namespaces,
+// Services, Deployments and Secrets.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		v1alpha1.AddToScheme,
+		v1alpha2.AddToScheme,
+	} {
+		if err := addToScheme(scheme); err != nil {
+			return nil, fmt.Errorf("error building scheme: %w", err)
+		}
+	}
+	return scheme, nil
+}
+
+// NewClient returns a client for the cluster of restConfig that knows about
+// the types of NewScheme. It reads directly from the API server, so it can be
+// used as both the Client and the APIReader of a suite.
+func NewClient(restConfig *rest.Config) (client.Client, error) {
+	scheme, err := NewScheme()
+	if err != nil {
+		return nil, err
+	}
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing Kubernetes client: %w", err)
+	}
+	return c, nil
+}
+
+// optionsClient returns the Client of the options, built from their
+// RESTConfig with NewClient if unset.
+func optionsClient(s Options) (client.Client, error) {
+	if s.Client != nil || s.RESTConfig == nil {
+		return s.Client, nil
+	}
+	return NewClient(s.RESTConfig)
+}
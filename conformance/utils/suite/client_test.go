@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha1"
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestNewScheme(t *testing.T) {
+	scheme, err := NewScheme()
+	require.NoError(t, err)
+
+	for _, obj := range []runtime.Object{
+		&v1alpha2.GatewayClass{},
+		&v1alpha2.Gateway{},
+		&v1alpha2.HTTPRoute{},
+		&v1alpha2.TLSRoute{},
+		&v1alpha2.TCPRoute{},
+		&v1alpha2.UDPRoute{},
+		&v1alpha2.ReferencePolicy{},
+		&v1alpha1.Gateway{},
+		&v1.Namespace{},
+		&v1.Service{},
+		&v1.Secret{},
+		&v1.Pod{},
+		&appsv1.Deployment{},
+	} {
+		_, _, err := scheme.ObjectKinds(obj)
+		require.NoErrorf(t, err, "expected %T to be registered", obj)
+	}
+}
+
+func TestRESTConfig(t *testing.T) {
+	// Nothing listens on the port, so building a client for it fails.
+	unreachable := &rest.Config{Host: "http://127.0.0.1:1"}
+
+	_, err := New(Options{RESTConfig: unreachable})
+	require.Error(t, err)
+	require.True(t, strings.HasPrefix(err.Error(), "error initializing Kubernetes client"), "unexpected error: %v", err)
+
+	c := newRecordingClient(t)
+	cSuite := mustNew(t, Options{Client: c, RESTConfig: unreachable})
+	require.Equal(t, c, cSuite.Client, "expected Client to override RESTConfig")
+	require.Equal(t, c, cSuite.APIReader)
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import "testing"
+
+// TestDecision tells whether the suite would run a conformance test, and
+// why it would skip it otherwise.
+type TestDecision struct {
+	ShortName string
+	Run       bool
+	// SkipCategory and SkipReason are set for tests that would be skipped.
+	SkipCategory SkipCategory
+	SkipReason   string
+}
+
+// Plan returns whether Run would run each of the provided tests given the
+// options of the suite, without touching the cluster. Tests filtered out by
+// RunResources or RunTest are included with the SkipNotSelected category.
+func (suite *ConformanceTestSuite) Plan(tests []ConformanceTest) []TestDecision {
+	return suite.plan(tests, false)
+}
+
+// plan returns the decision of Run, or of Verify if verify is set, for each
+// of the tests.
+func (suite *ConformanceTestSuite) plan(tests []ConformanceTest, verify bool) []TestDecision {
+	decisions := make([]TestDecision, 0, len(tests))
+	for i := range tests {
+		test := &tests[i]
+		decision := TestDecision{ShortName: test.ShortName}
+		switch {
+		case !suite.runsResources(*test):
+			decision.SkipCategory, decision.SkipReason = SkipNotSelected, "not selected by RunResources"
+		case !suite.runsTest(*test):
+			decision.SkipCategory, decision.SkipReason = SkipNotSelected, "not selected by RunTest"
+		default:
+			decision.SkipCategory, decision.SkipReason = suite.skipReason(test, verify)
+		}
+		decision.Run = decision.SkipCategory == ""
+		decisions = append(decisions, decision)
+	}
+	return decisions
+}
+
+// logPlan logs every decision of a dry run.
+func (suite *ConformanceTestSuite) logPlan(t *testing.T, decisions []TestDecision) {
+	for _, decision := range decisions {
+		if decision.Run {
+			t.Logf("Dry run: %s would run", decision.ShortName)
+			continue
+		}
+		t.Logf("Dry run: %s would be skipped (%s): %s", decision.ShortName, decision.SkipCategory, decision.SkipReason)
+	}
+}
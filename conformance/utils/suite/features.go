@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+// SupportedFeaturesAnnotation can be set on the GatewayClass under test to
+// the comma-separated list of features its implementation supports, e.g.
+// "ReferenceGrant". It is read when AutoDetectFeatures is set.
+const SupportedFeaturesAnnotation = "gateway-api.sigs.k8s.io/conformance-supported-features"
+
+// detectFeatures adds the features published through the
+// SupportedFeaturesAnnotation of the GatewayClass to SupportedFeatures, if
+// AutoDetectFeatures is set. The explicitly supported features are kept, and
+// are used alone if the GatewayClass doesn't publish any.
+func (suite *ConformanceTestSuite) detectFeatures(ctx context.Context, t *testing.T) {
+	if !suite.AutoDetectFeatures {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, suite.timeouts().GatewayClassMustBeAccepted)
+	defer cancel()
+
+	gwc := &v1alpha2.GatewayClass{}
+	err := suite.APIReader.Get(ctx, types.NamespacedName{Name: suite.GatewayClassName}, gwc)
+	require.NoErrorf(t, err, "error fetching %s GatewayClass to detect supported features", suite.GatewayClassName)
+
+	var detected []string
+	for _, feature := range strings.Split(gwc.Annotations[SupportedFeaturesAnnotation], ",") {
+		feature = strings.TrimSpace(feature)
+		if feature == "" {
+			continue
+		}
+		detected = append(detected, feature)
+		if !hasFeature(suite.SupportedFeatures, SupportedFeature(feature)) {
+			suite.SupportedFeatures = append(suite.SupportedFeatures, SupportedFeature(feature))
+		}
+	}
+	suite.SupportedFeatures = expandFeatures(suite.SupportedFeatures)
+
+	if len(detected) == 0 {
+		t.Logf("No supported features detected from %s GatewayClass, using the explicitly supported features", suite.GatewayClassName)
+		return
+	}
+	t.Logf("Detected supported features from %s GatewayClass: %s", suite.GatewayClassName, strings.Join(detected, ", "))
+	require.NoErrorf(t, validateFeatures(suite.SupportedFeatures, suite.ExemptFeatures), "incoherent features detected from %s GatewayClass", suite.GatewayClassName)
+}
+
+// renamedFeatures maps the former names of renamed features to their current
+// name.
+var renamedFeatures = map[string]string{
+	string(SupportReferencePolicy): string(SupportReferenceGrant),
+}
+
+// canonicalFeature returns the current name of a feature, so that the former
+// names of renamed features match their current name.
+func canonicalFeature(feature string) string {
+	if renamed, ok := renamedFeatures[feature]; ok {
+		return renamed
+	}
+	return feature
+}
+
+// hasFeature returns true if features contains feature, under its current or
+// former name.
+func hasFeature(features []SupportedFeature, feature SupportedFeature) bool {
+	for _, f := range features {
+		if canonicalFeature(string(f)) == canonicalFeature(string(feature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasExemption returns true if exemptions contains feature, under its
+// current or former name.
+func hasExemption(exemptions []ExemptFeature, feature ExemptFeature) bool {
+	for _, f := range exemptions {
+		if canonicalFeature(string(f)) == canonicalFeature(string(feature)) {
+			return true
+		}
+	}
+	return false
+}
+
+// featureAggregates maps the coarse features to the finer features of the
+// same route type they expand into. Claiming an aggregate claims all of its
+// features, so that tests can be gated on the precise features they exercise.
+var featureAggregates = map[SupportedFeature][]SupportedFeature{
+	SupportHTTPRoute: {
+		SupportHTTPRouteMethodMatching,
+		SupportHTTPRouteQueryParamMatching,
+		SupportHTTPRouteRequestMirror,
+	},
+}
+
+// expandFeatures returns the features along with the features of the
+// aggregates among them, without duplicates.
+func expandFeatures(features []SupportedFeature) []SupportedFeature {
+	expanded := make([]SupportedFeature, 0, len(features))
+	for _, feature := range features {
+		if !hasFeature(expanded, feature) {
+			expanded = append(expanded, feature)
+		}
+		for _, subFeature := range featureAggregates[SupportedFeature(canonicalFeature(string(feature)))] {
+			if !hasFeature(expanded, subFeature) {
+				expanded = append(expanded, subFeature)
+			}
+		}
+	}
+	return expanded
+}
+
+// featureDependencies maps features to the features they build upon, which
+// must be supported as well. None of the current features has prerequisites:
+// the features of an aggregate don't, so that implementations can claim them
+// alone.
+var featureDependencies = map[SupportedFeature][]SupportedFeature{}
+
+// validateFeatures returns an error if a supported feature misses one of its
+// prerequisites, or if a feature is both supported and exempted.
+func validateFeatures(supported []SupportedFeature, exempt []ExemptFeature) error {
+	if err := checkDependencies(supported, featureDependencies); err != nil {
+		return err
+	}
+	for _, feature := range supported {
+		if hasExemption(exempt, ExemptFeature(feature)) {
+			return fmt.Errorf("feature %s can't be both supported and exempted", feature)
+		}
+	}
+	return nil
+}
+
+// checkDependencies returns an error if a supported feature misses one of
+// its prerequisites in dependencies.
+func checkDependencies(supported []SupportedFeature, dependencies map[SupportedFeature][]SupportedFeature) error {
+	for _, feature := range supported {
+		for _, dependency := range dependencies[SupportedFeature(canonicalFeature(string(feature)))] {
+			if !hasFeature(supported, dependency) {
+				return fmt.Errorf("supported feature %s requires %s, which must be supported as well", feature, dependency)
+			}
+		}
+	}
+	return nil
+}
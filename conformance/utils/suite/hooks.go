@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"fmt"
+	"testing"
+)
+
+// beforeEach calls the BeforeEach hook, if any, failing the test if it
+// panicked so that the test doesn't run without the setup of the hook.
+func (suite *ConformanceTestSuite) beforeEach(t *testing.T, test ConformanceTest) {
+	if suite.BeforeEach == nil {
+		return
+	}
+	if !callHook(t, "BeforeEach", func() { suite.BeforeEach(t, test) }) {
+		t.FailNow()
+	}
+}
+
+// afterEach calls the AfterEach hook, if any, with an error if the test
+// failed. It must be deferred so that it also runs for tests that were
+// skipped or called t.FailNow, before the manifests of the test are deleted
+// by the cleanup functions of the test.
+func (suite *ConformanceTestSuite) afterEach(t *testing.T, test ConformanceTest) {
+	if suite.AfterEach == nil {
+		return
+	}
+	var err error
+	if t.Failed() {
+		err = fmt.Errorf("%s failed", test.ShortName)
+	}
+	callHook(t, "AfterEach", func() { suite.AfterEach(t, test, err) })
+}
+
+// callHook calls the hook, reporting a panic as a test error rather than
+// letting it abort the test binary before the applied manifests are cleaned
+// up. It returns false if the hook panicked.
+func callHook(t errorer, name string, hook func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("%s hook panicked: %v", name, r)
+			ok = false
+		}
+	}()
+	hook()
+	return true
+}
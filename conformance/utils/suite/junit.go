@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// junitTestSuite is the <testsuite> element of a JUnit XML report.
+type junitTestSuite struct {
+	XMLName    xml.Name        `xml:"testsuite"`
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is the <testcase> element of a JUnit XML report.
+type junitTestCase struct {
+	Name       string          `xml:"name,attr"`
+	ClassName  string          `xml:"classname,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Failure    *junitMessage   `xml:"failure,omitempty"`
+	Skipped    *junitMessage   `xml:"skipped,omitempty"`
+}
+
+// junitProperty is the <property> element of a JUnit XML report.
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// junitMessage is the <failure> or <skipped> element of a JUnit XML report.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the results of the tests executed so far as a JUnit XML
+// <testsuite>, with one <testcase> per test named after its ShortName. Since
+// the messages logged by failed tests aren't available to the suite, failures
+// refer to the standard test output for details. An error is returned if
+// tests are still running.
+func (suite *ConformanceTestSuite) WriteJUnit(w io.Writer) error {
+	results, err := suite.finishedResults()
+	if err != nil {
+		return err
+	}
+
+	testSuite := junitTestSuite{
+		Name:  "gateway-api-conformance",
+		Tests: len(results),
+		Properties: []junitProperty{
+			{Name: "gatewayClassName", Value: suite.GatewayClassName},
+			{Name: "controllerName", Value: suite.ControllerName},
+			{Name: "minChannel", Value: suite.MinChannel.String()},
+		},
+	}
+	if len(suite.ConformanceProfiles) > 0 {
+		profiles := make([]string, len(suite.ConformanceProfiles))
+		for i, profile := range suite.ConformanceProfiles {
+			profiles[i] = string(profile)
+		}
+		testSuite.Properties = append(testSuite.Properties, junitProperty{Name: "conformanceProfiles", Value: strings.Join(profiles, ",")})
+	}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+		testCase := junitTestCase{
+			Name:       result.ShortName,
+			ClassName:  testSuite.Name,
+			Time:       junitSeconds(result.Duration),
+			Properties: []junitProperty{{Name: "description", Value: result.Description}},
+		}
+		if len(result.Features) > 0 {
+			features := make([]string, len(result.Features))
+			for i, feature := range result.Features {
+				features[i] = string(feature)
+			}
+			testCase.Properties = append(testCase.Properties, junitProperty{Name: "features", Value: strings.Join(features, ",")})
+		}
+
+		switch result.Outcome {
+		case TestFailed:
+			testSuite.Failures++
+			testCase.Failure = &junitMessage{Message: fmt.Sprintf("%s failed, see the test output for details", result.ShortName)}
+		case TestSkipped:
+			testSuite.Skipped++
+			testCase.Skipped = &junitMessage{Message: junitSkipMessage(result)}
+		}
+		testSuite.TestCases = append(testSuite.TestCases, testCase)
+	}
+	testSuite.Time = junitSeconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(testSuite); err != nil {
+		return fmt.Errorf("error encoding JUnit report: %w", err)
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// junitSkipMessage returns the message of the <skipped> element of a result,
+// prefixed with the category of the skip so that e.g. unsupported features
+// can be told apart from channel mismatches.
+func junitSkipMessage(result TestResult) string {
+	switch {
+	case result.SkipCategory == "":
+		return "skipped by the test"
+	case result.SkipReason == "":
+		return string(result.SkipCategory)
+	}
+	return fmt.Sprintf("%s: %s", result.SkipCategory, result.SkipReason)
+}
+
+// junitSeconds formats a duration as the seconds expected by time attributes.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// decodeJUnit writes the JUnit report of the suite and decodes it back,
+// verifying it is a well-formed <testsuite> document.
+func decodeJUnit(t *testing.T, cSuite *ConformanceTestSuite) junitTestSuite {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, cSuite.WriteJUnit(&buf))
+	require.True(t, strings.HasPrefix(buf.String(), xml.Header), "expected report to start with the XML header")
+
+	testSuite := junitTestSuite{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &testSuite))
+	require.Equal(t, "testsuite", testSuite.XMLName.Local)
+	require.Len(t, testSuite.TestCases, testSuite.Tests)
+	_, err := strconv.ParseFloat(testSuite.Time, 64)
+	require.NoErrorf(t, err, "expected time attribute of the suite to be in seconds, got %q", testSuite.Time)
+	for _, testCase := range testSuite.TestCases {
+		_, err := strconv.ParseFloat(testCase.Time, 64)
+		require.NoErrorf(t, err, "expected time attribute of %s to be in seconds, got %q", testCase.Name, testCase.Time)
+	}
+	return testSuite
+}
+
+func junitProperties(properties []junitProperty) map[string]string {
+	values := map[string]string{}
+	for _, property := range properties {
+		values[property.Name] = property.Value
+	}
+	return values
+}
+
+func TestWriteJUnit(t *testing.T) {
+	t.Run("all passing", func(t *testing.T) {
+		cSuite := mustNew(t, Options{GatewayClassName: "gateway-conformance", MinChannel: StandardChannel})
+
+		t.Run("run", func(t *testing.T) {
+			cSuite.Run(t, []ConformanceTest{{
+				ShortName:   "Slow",
+				Description: "A slow test",
+				MinChannel:  StandardChannel,
+				Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+					time.Sleep(100 * time.Millisecond)
+				},
+			}, {
+				ShortName:   "Fast",
+				Description: "A fast test",
+				MinChannel:  StandardChannel,
+				Test:        func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+			}})
+		})
+
+		testSuite := decodeJUnit(t, cSuite)
+		require.Equal(t, 2, testSuite.Tests)
+		require.Zero(t, testSuite.Failures)
+		require.Zero(t, testSuite.Skipped)
+		require.Equal(t, "gateway-conformance", junitProperties(testSuite.Properties)["gatewayClassName"])
+
+		slow, fast := testSuite.TestCases[0], testSuite.TestCases[1]
+		require.Equal(t, "Slow", slow.Name)
+		require.Equal(t, "A slow test", junitProperties(slow.Properties)["description"])
+		require.Nil(t, slow.Failure)
+		require.Nil(t, slow.Skipped)
+		require.Equal(t, "Fast", fast.Name)
+
+		slowTime, _ := strconv.ParseFloat(slow.Time, 64)
+		fastTime, _ := strconv.ParseFloat(fast.Time, 64)
+		require.GreaterOrEqual(t, slowTime, 0.1, "expected duration of the slow test to be timed")
+		require.Less(t, fastTime, 0.1, "expected durations to be per-test")
+	})
+
+	t.Run("failures and skips", func(t *testing.T) {
+		cSuite := mustNew(t, Options{GatewayClassName: "gateway-conformance", MinChannel: StandardChannel})
+
+		t.Run("run", func(t *testing.T) {
+			cSuite.Run(t, []ConformanceTest{{
+				ShortName:  "Unsupported",
+				Features:   []SupportedFeature{SupportReferencePolicy},
+				MinChannel: StandardChannel,
+				Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+			}, {
+				ShortName:  "Experimental",
+				MinChannel: ExperimentalChannel,
+				Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+			}})
+		})
+		// A failing test would fail this test too, so its result is injected.
+		cSuite.results.results = append(cSuite.results.results, TestResult{
+			ShortName:   "Failing",
+			Description: "A failing test",
+			Outcome:     TestFailed,
+			Duration:    time.Second,
+		})
+
+		testSuite := decodeJUnit(t, cSuite)
+		require.Equal(t, 3, testSuite.Tests)
+		require.Equal(t, 1, testSuite.Failures)
+		require.Equal(t, 2, testSuite.Skipped)
+
+		unsupported, experimental, failing := testSuite.TestCases[0], testSuite.TestCases[1], testSuite.TestCases[2]
+		require.NotNil(t, unsupported.Skipped)
+		require.Equal(t, "UnsupportedFeature: suite does not support ReferencePolicy", unsupported.Skipped.Message)
+		require.Equal(t, "ReferencePolicy", junitProperties(unsupported.Properties)["features"])
+
+		require.NotNil(t, experimental.Skipped)
+		require.True(t, strings.HasPrefix(experimental.Skipped.Message, "Channel: "), "expected channel mismatch to be preserved, got %q", experimental.Skipped.Message)
+
+		require.Nil(t, failing.Skipped)
+		require.NotNil(t, failing.Failure)
+		require.Contains(t, failing.Failure.Message, "Failing failed")
+		require.Equal(t, "1.000", failing.Time)
+	})
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+)
+
+// auditLeaks reports every Gateway API resource left behind in the base
+// namespaces once the tests are done. Resources deleted on cleanup are
+// waited for up to the NamespacesMustBeDeleted timeout, as their deletion
+// may take a while.
+func (suite *ConformanceTestSuite) auditLeaks(t *testing.T) {
+	timeoutConfig := suite.timeouts()
+	var leaks []kubernetes.LeakedResource
+	err := wait.PollImmediate(timeoutConfig.PollInterval, timeoutConfig.NamespacesMustBeDeleted, func() (bool, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		leaks, err = kubernetes.FindLeakedResources(ctx, suite.APIReader, suite.Applier.Tracker, suite.Namespaces)
+		if err != nil {
+			return false, err
+		}
+		for _, leak := range leaks {
+			if leak.Reason == kubernetes.LeakNotDeleted {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil && err != wait.ErrWaitTimeout {
+		t.Errorf("error auditing leaked resources: %v", err)
+		return
+	}
+
+	for _, leak := range leaks {
+		t.Errorf("Leaked %s: %s", leak.ResourceRef, leak.Reason)
+	}
+	if len(leaks) == 0 {
+		t.Logf("No leaked resources found in %d namespaces", len(suite.Namespaces))
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+)
+
+// testNamespaces keeps track of the namespace allocated to each running test
+// when a ReusableNamespace or Workers are configured.
+type testNamespaces struct {
+	mu            sync.Mutex
+	reusableReady bool
+	byTest        map[string]string
+}
+
+// TestNamespace returns the namespace allocated to the running test, or to
+// the test t is a subtest of. It is empty unless ReusableNamespace or Workers
+// are set.
+func (suite *ConformanceTestSuite) TestNamespace(t *testing.T) string {
+	suite.namespaces.mu.Lock()
+	defer suite.namespaces.mu.Unlock()
+
+	for name := t.Name(); name != ""; {
+		if ns, ok := suite.namespaces.byTest[name]; ok {
+			return ns
+		}
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return ""
+}
+
+// allocateNamespace returns the namespace the test runs in: the reusable
+// namespace, created on first use and left in place for the rest of the run,
+// or a dedicated namespace deleted once the test is done if the test requires
+// isolation. It returns an empty string if no ReusableNamespace is set.
+func (suite *ConformanceTestSuite) allocateNamespace(ctx context.Context, t *testing.T, test *ConformanceTest) string {
+	if suite.ReusableNamespace == "" {
+		return ""
+	}
+
+	var ns string
+	if test.RequiresIsolation {
+		ns = suite.createIsolatedNamespace(ctx, t)
+	} else {
+		suite.ensureReusableNamespace(ctx, t)
+		ns = suite.ReusableNamespace
+	}
+
+	suite.recordNamespace(t, ns)
+	return ns
+}
+
+// recordNamespace makes ns the namespace returned by TestNamespace for the
+// test until it is done. Empty namespaces aren't recorded.
+func (suite *ConformanceTestSuite) recordNamespace(t *testing.T, ns string) {
+	if ns == "" {
+		return
+	}
+
+	suite.namespaces.mu.Lock()
+	defer suite.namespaces.mu.Unlock()
+	if suite.namespaces.byTest == nil {
+		suite.namespaces.byTest = map[string]string{}
+	}
+	name := t.Name()
+	suite.namespaces.byTest[name] = ns
+	t.Cleanup(func() {
+		suite.namespaces.mu.Lock()
+		defer suite.namespaces.mu.Unlock()
+		delete(suite.namespaces.byTest, name)
+	})
+}
+
+// ensureReusableNamespace creates the reusable namespace unless it already
+// exists, e.g. from a previous run.
+func (suite *ConformanceTestSuite) ensureReusableNamespace(ctx context.Context, t *testing.T) {
+	suite.namespaces.mu.Lock()
+	defer suite.namespaces.mu.Unlock()
+	if suite.namespaces.reusableReady {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err := suite.Client.Get(ctx, types.NamespacedName{Name: suite.ReusableNamespace}, &v1.Namespace{})
+	if apierrors.IsNotFound(err) {
+		t.Logf("Creating reusable %s namespace", suite.ReusableNamespace)
+		err = suite.Client.Create(ctx, suite.newNamespace(suite.ReusableNamespace))
+		if apierrors.IsAlreadyExists(err) {
+			err = nil
+		}
+	}
+	require.NoErrorf(t, err, "error ensuring reusable %s namespace exists", suite.ReusableNamespace)
+	suite.namespaces.reusableReady = true
+}
+
+// createIsolatedNamespace creates a namespace derived from the reusable one
+// that no other test uses, skipping names that are already taken, and
+// registers its deletion once the test is done.
+func (suite *ConformanceTestSuite) createIsolatedNamespace(ctx context.Context, t *testing.T) string {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	for i := 0; ; i++ {
+		ns := suite.newNamespace(fmt.Sprintf("%s-isolated-%d", suite.ReusableNamespace, i))
+		err := suite.Client.Create(ctx, ns)
+		if apierrors.IsAlreadyExists(err) {
+			continue
+		}
+		require.NoErrorf(t, err, "error creating isolated %s namespace", ns.Name)
+
+		t.Logf("Created isolated %s namespace", ns.Name)
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			t.Logf("Deleting isolated %s namespace", ns.Name)
+			err := suite.Client.Delete(ctx, ns)
+			require.NoErrorf(t, err, "error deleting isolated %s namespace", ns.Name)
+		})
+		return ns.Name
+	}
+}
+
+// newNamespace returns a namespace with the given name, carrying the
+// configured namespace labels.
+func (suite *ConformanceTestSuite) newNamespace(name string) *v1.Namespace {
+	labels := map[string]string{kubernetes.OwnedLabel: "true"}
+	for k, v := range suite.Applier.NamespaceLabels {
+		labels[k] = v
+	}
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+)
+
+// acquireParallelSlot waits for one of the MaxParallel slots to be free and
+// returns a function releasing it. The test fails if ctx is cancelled first.
+// Without MaxParallel, it returns straight away.
+func (suite *ConformanceTestSuite) acquireParallelSlot(ctx context.Context, t *testing.T) func() {
+	if suite.parallelSlots == nil {
+		return func() {}
+	}
+
+	select {
+	case suite.parallelSlots <- struct{}{}:
+	case <-ctx.Done():
+		t.Fatalf("Aborted while waiting for one of the %d parallel test slots: %v", suite.MaxParallel, ctx.Err())
+	}
+	return func() { <-suite.parallelSlots }
+}
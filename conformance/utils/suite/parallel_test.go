@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxParallel(t *testing.T) {
+	_, err := New(Options{MaxParallel: -1})
+	require.EqualError(t, err, "MaxParallel must not be negative, got -1")
+
+	cSuite := mustNew(t, Options{MaxParallel: 2})
+
+	var (
+		mu                  sync.Mutex
+		running, maxRunning int
+		wg                  sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := cSuite.acquireParallelSlot(context.Background(), t)
+			defer release()
+
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 2, maxRunning, "expected at most MaxParallel tests to run at once")
+
+	release := mustNew(t, Options{}).acquireParallelSlot(context.Background(), t)
+	release()
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// ConformanceProfileName is the name of a conformance profile an
+// implementation can claim.
+type ConformanceProfileName string
+
+const (
+	// HTTPProfileName indicates support for HTTP routing through Gateways
+	// along with the features it relies on.
+	HTTPProfileName ConformanceProfileName = "HTTP"
+)
+
+// ConformanceProfile is a named set of features that implementations claim
+// as a whole rather than one at a time.
+type ConformanceProfile struct {
+	Name              ConformanceProfileName
+	SupportedFeatures []SupportedFeature
+}
+
+// conformanceProfiles holds every profile that can be claimed, keyed by name.
+var conformanceProfiles = map[ConformanceProfileName]ConformanceProfile{
+	HTTPProfileName: {
+		Name: HTTPProfileName,
+		SupportedFeatures: []SupportedFeature{
+			SupportHTTPRoute,
+			SupportReferenceGrant,
+		},
+	},
+}
+
+// resolveProfiles returns the explicitly supported features along with the
+// features of every named profile, without duplicates. An error is returned
+// if a profile is unknown.
+func resolveProfiles(features []SupportedFeature, profiles []ConformanceProfileName) ([]SupportedFeature, error) {
+	resolved := append([]SupportedFeature{}, features...)
+	for _, name := range profiles {
+		profile, ok := conformanceProfiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown conformance profile %q", name)
+		}
+		for _, feature := range profile.SupportedFeatures {
+			if !hasFeature(resolved, feature) {
+				resolved = append(resolved, feature)
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// relevant returns true if a test exercising the provided features is
+// covered by the profile, i.e. every feature is part of the profile or of one
+// of its aggregates.
+func (p ConformanceProfile) relevant(features []SupportedFeature) bool {
+	supported := expandFeatures(p.SupportedFeatures)
+	for _, feature := range features {
+		if !hasFeature(supported, feature) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProfileReport summarizes the outcome of the tests run for a single
+// conformance profile.
+type ProfileReport struct {
+	// Name is the name of the profile.
+	Name ConformanceProfileName `json:"name"`
+	// Passed lists the short names of the tests of the profile that passed.
+	Passed []string `json:"passed,omitempty"`
+	// Failed lists the short names of the tests of the profile that failed.
+	Failed []string `json:"failed,omitempty"`
+	// Skipped lists the short names of the tests of the profile that were
+	// skipped.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// record adds the provided test result to the report.
+func (r *ProfileReport) record(result TestResult) {
+	switch result.Outcome {
+	case TestFailed:
+		r.Failed = append(r.Failed, result.ShortName)
+	case TestSkipped:
+		r.Skipped = append(r.Skipped, result.ShortName)
+	default:
+		r.Passed = append(r.Passed, result.ShortName)
+	}
+}
+
+// RunProfiles is RunProfilesWithContext with a background context.
+//
+// Deprecated: use RunProfilesWithContext.
+func (suite *ConformanceTestSuite) RunProfiles(t *testing.T, tests []ConformanceTest, profiles []ConformanceProfileName) {
+	suite.RunProfilesWithContext(context.Background(), t, tests, profiles)
+}
+
+// RunProfilesWithContext runs the subset of the provided tests that is relevant to the
+// claimed conformance profiles, as RunWithContext does. The features of every
+// claimed profile are added to the SupportedFeatures of the suite. Tests
+// relevant to several profiles, e.g. the tests without features, run once,
+// and count for each of them in the ProfileReport recorded in ProfileReports
+// for every profile once all the tests have finished.
+func (suite *ConformanceTestSuite) RunProfilesWithContext(ctx context.Context, t *testing.T, tests []ConformanceTest, profiles []ConformanceProfileName) {
+	claimed, err := suite.claimProfiles(profiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relevant := make([]ConformanceTest, 0, len(tests))
+	for _, test := range tests {
+		for _, profile := range claimed {
+			if profile.relevant(test.Features) {
+				relevant = append(relevant, test)
+				break
+			}
+		}
+	}
+
+	suite.results.mu.Lock()
+	first := len(suite.results.results)
+	suite.results.mu.Unlock()
+
+	suite.run(ctx, t, relevant, false)
+	if suite.DryRun {
+		return
+	}
+	// Cleanups run last in first out, so the profile reports are recorded
+	// once all tests are done but before run writes the report and summary.
+	t.Cleanup(func() {
+		suite.recordProfileReports(t, claimed, suite.Results()[first:])
+	})
+}
+
+// claimProfiles adds the features of the named profiles to the
+// SupportedFeatures of the suite and returns the profiles. An error is
+// returned, leaving the suite untouched, if a profile is unknown or if the
+// resulting features are incoherent.
+func (suite *ConformanceTestSuite) claimProfiles(names []ConformanceProfileName) ([]ConformanceProfile, error) {
+	supported, err := resolveProfiles(suite.SupportedFeatures, names)
+	if err != nil {
+		return nil, err
+	}
+	supported = expandFeatures(supported)
+	if err := validateFeatures(supported, suite.ExemptFeatures); err != nil {
+		return nil, err
+	}
+
+	claimed := make([]ConformanceProfile, 0, len(names))
+	for _, name := range names {
+		claimed = append(claimed, conformanceProfiles[name])
+	}
+	suite.SupportedFeatures = supported
+	return claimed, nil
+}
+
+// recordProfileReports appends a ProfileReport for each of the profiles to
+// ProfileReports, made of the provided results of the tests relevant to it.
+func (suite *ConformanceTestSuite) recordProfileReports(t *testing.T, profiles []ConformanceProfile, results []TestResult) {
+	for _, profile := range profiles {
+		report := ProfileReport{Name: profile.Name}
+		for _, result := range results {
+			if profile.relevant(result.Features) {
+				report.record(result)
+			}
+		}
+		t.Logf("Conformance profile %s: %d passed, %d failed, %d skipped", profile.Name, len(report.Passed), len(report.Failed), len(report.Skipped))
+		suite.ProfileReports = append(suite.ProfileReports, report)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunProfiles(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	newTest := func(name string, features ...SupportedFeature) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			Features:   features,
+			MinChannel: StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+			},
+		}
+	}
+
+	tests := []ConformanceTest{
+		newTest("Core"),
+		newTest("ReferencePolicy", SupportReferencePolicy),
+		newTest("MethodMatching", SupportHTTPRouteMethodMatching),
+		newTest("Unrelated", SupportedFeature("Unrelated")),
+	}
+
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel})
+	t.Run("run", func(t *testing.T) {
+		cSuite.RunProfiles(t, tests, []ConformanceProfileName{HTTPProfileName})
+	})
+
+	require.ElementsMatch(t, []string{"Core", "ReferencePolicy", "MethodMatching"}, ran)
+	require.Contains(t, cSuite.SupportedFeatures, SupportReferenceGrant)
+	require.Contains(t, cSuite.SupportedFeatures, SupportHTTPRoute)
+	require.Equal(t, []ProfileReport{{
+		Name:   HTTPProfileName,
+		Passed: []string{"Core", "ReferencePolicy", "MethodMatching"},
+	}}, cSuite.ProfileReports)
+
+	var shortNames []string
+	for _, result := range cSuite.Results() {
+		shortNames = append(shortNames, result.ShortName)
+	}
+	require.Equal(t, []string{"Core", "ReferencePolicy", "MethodMatching"}, shortNames, "expected the results of the profile tests to be tracked")
+}
+
+func TestRunProfilesRunsTestsOnce(t *testing.T) {
+	var mu sync.Mutex
+	runs := map[string]int{}
+	newTest := func(name string, features ...SupportedFeature) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			Features:   features,
+			MinChannel: StandardChannel,
+			Parallel:   true,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				mu.Lock()
+				defer mu.Unlock()
+				runs[name]++
+			},
+		}
+	}
+	tests := []ConformanceTest{
+		newTest("Core"),
+		newTest("ReferencePolicy", SupportReferencePolicy),
+		newTest("Filtered"),
+	}
+
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, RunTest: "[CR]*"})
+	t.Run("run", func(t *testing.T) {
+		cSuite.RunProfiles(t, tests, []ConformanceProfileName{HTTPProfileName, HTTPProfileName})
+	})
+
+	require.Equal(t, map[string]int{"Core": 1, "ReferencePolicy": 1}, runs, "expected tests relevant to several profiles to run once and RunTest to apply")
+	require.Len(t, cSuite.ProfileReports, 2)
+	for _, report := range cSuite.ProfileReports {
+		require.Equal(t, HTTPProfileName, report.Name)
+		require.ElementsMatch(t, []string{"Core", "ReferencePolicy"}, report.Passed, "expected parallel tests to be recorded once done")
+	}
+}
+
+func TestClaimProfiles(t *testing.T) {
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, SupportedFeatures: []SupportedFeature{SupportReferencePolicy}})
+	claimed, err := cSuite.claimProfiles([]ConformanceProfileName{HTTPProfileName})
+	require.NoError(t, err)
+	require.Equal(t, []ConformanceProfile{conformanceProfiles[HTTPProfileName]}, claimed)
+	require.Equal(t, []SupportedFeature{SupportReferencePolicy, SupportHTTPRoute, SupportHTTPRouteMethodMatching, SupportHTTPRouteQueryParamMatching, SupportHTTPRouteRequestMirror}, cSuite.SupportedFeatures)
+
+	cSuite = mustNew(t, Options{MinChannel: StandardChannel, ExemptFeatures: []ExemptFeature{ExemptReferenceGrant}})
+	_, err = cSuite.claimProfiles([]ConformanceProfileName{HTTPProfileName})
+	require.EqualError(t, err, "feature ReferenceGrant can't be both supported and exempted")
+	require.Empty(t, cSuite.SupportedFeatures, "expected the features of the suite to be left untouched")
+
+	_, err = cSuite.claimProfiles([]ConformanceProfileName{"Mesh"})
+	require.EqualError(t, err, `unknown conformance profile "Mesh"`)
+}
+
+func TestConformanceProfilesOption(t *testing.T) {
+	cSuite := mustNew(t, Options{
+		MinChannel:          StandardChannel,
+		SupportedFeatures:   []SupportedFeature{"Explicit", SupportReferencePolicy},
+		ConformanceProfiles: []ConformanceProfileName{HTTPProfileName},
+	})
+	require.Equal(t, []SupportedFeature{"Explicit", SupportReferencePolicy, SupportHTTPRoute, SupportHTTPRouteMethodMatching, SupportHTTPRouteQueryParamMatching, SupportHTTPRouteRequestMirror}, cSuite.SupportedFeatures, "expected explicit and profile features to be merged without duplicates")
+
+	cSuite = mustNew(t, Options{MinChannel: StandardChannel, ConformanceProfiles: []ConformanceProfileName{HTTPProfileName}})
+	require.Equal(t, []SupportedFeature{SupportHTTPRoute, SupportHTTPRouteMethodMatching, SupportHTTPRouteQueryParamMatching, SupportHTTPRouteRequestMirror, SupportReferenceGrant}, cSuite.SupportedFeatures)
+	report, err := cSuite.Report()
+	require.NoError(t, err)
+	require.Equal(t, []ConformanceProfileName{HTTPProfileName}, report.ConformanceProfiles, "expected the report to record the claimed profiles")
+
+	_, err = New(Options{ConformanceProfiles: []ConformanceProfileName{HTTPProfileName, "Mesh"}})
+	require.EqualError(t, err, `unknown conformance profile "Mesh"`)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+)
+
+// ProvisionedGateway is a Gateway provisioned outside of the suite, which
+// tests run against when SkipBaseManifests is set.
+type ProvisionedGateway struct {
+	Name      string
+	Namespace string
+	// Address is the host:port requests are sent to. If empty, it is read
+	// from the status of the Gateway once it's ready, resolving Hostname
+	// addresses through DNS.
+	Address string
+	// AddressType is the type of the address read from the status of the
+	// Gateway, set by Setup.
+	AddressType v1alpha2.AddressType
+}
+
+// NamespacedName returns the name and namespace of the Gateway.
+func (gw *ProvisionedGateway) NamespacedName() types.NamespacedName {
+	return types.NamespacedName{Name: gw.Name, Namespace: gw.Namespace}
+}
+
+// ensureProvisionedGatewayReady waits for the ProvisionedGateway, and the
+// Gateways and Pods of every extra ReadyCheck, to be ready.
+func (suite *ConformanceTestSuite) ensureProvisionedGatewayReady(ctx context.Context, t *testing.T) {
+	address := kubernetes.GatewayMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.ProvisionedGateway.NamespacedName(), suite.AddressType)
+	suite.ProvisionedGateway.AddressType = address.Type
+	if suite.ProvisionedGateway.Address == "" {
+		suite.ProvisionedGateway.Address = address.Dial
+	}
+	t.Logf("%s Gateway is ready at %s (%s address %s)", suite.ProvisionedGateway.NamespacedName(), suite.ProvisionedGateway.Address, address.Type, address.Value)
+
+	for _, check := range suite.ExtraReadyChecks {
+		kubernetes.NamespacesMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), []string{check.Namespace})
+		if len(check.Deployments) > 0 {
+			kubernetes.DeploymentsMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), check.Namespace, check.Deployments)
+		}
+	}
+}
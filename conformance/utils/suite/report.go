@@ -0,0 +1,158 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// ConformanceReport is the machine-readable report of the tests executed by
+// the suite. It is marshaled to JSON with the field names below.
+type ConformanceReport struct {
+	// GatewayClassName is the name of the GatewayClass under test.
+	GatewayClassName string `json:"gatewayClassName"`
+	// ControllerName is the controller name of the GatewayClass.
+	ControllerName string `json:"controllerName"`
+	// MinChannel is the least stable release channel tests were run for,
+	// either "experimental" or "standard".
+	MinChannel string `json:"minChannel"`
+	// ConformanceProfiles lists the conformance profiles claimed for the
+	// run, if any.
+	ConformanceProfiles []ConformanceProfileName `json:"conformanceProfiles,omitempty"`
+	// SupportedFeatures lists the features claimed for the run, sorted,
+	// with the coarse features expanded into the finer features they
+	// aggregate.
+	SupportedFeatures []SupportedFeature `json:"supportedFeatures,omitempty"`
+	// ProfileReports holds the report of every conformance profile run
+	// through RunProfiles, if any.
+	ProfileReports []ProfileReport `json:"profileReports,omitempty"`
+	// Tests holds the report of every executed test, sorted by ShortName.
+	Tests []TestReport `json:"tests"`
+}
+
+// TestReport is the report of a single conformance test.
+type TestReport struct {
+	// ShortName is the short name of the test.
+	ShortName string `json:"shortName"`
+	// Description is the description of the test.
+	Description string `json:"description"`
+	// Features lists the features exercised by the test.
+	Features []SupportedFeature `json:"features,omitempty"`
+	// Status is one of "Passed", "Failed" or "Skipped".
+	Status TestOutcome `json:"status"`
+	// SkipCategory tells why the suite skipped the test, e.g.
+	// "UnsupportedFeature" or "Channel". It is empty for tests that skipped
+	// themselves.
+	SkipCategory SkipCategory `json:"skipCategory,omitempty"`
+	// SkipReason is the human-readable reason the suite skipped the test.
+	SkipReason string `json:"skipReason,omitempty"`
+	// DurationMillis is how long the test took, in milliseconds.
+	DurationMillis int64 `json:"durationMillis"`
+	// TotalDurationMillis is how long the test took including applying and
+	// cleaning up its manifests, in milliseconds.
+	TotalDurationMillis int64 `json:"totalDurationMillis"`
+	// Requests summarizes the requests the test made, if the suite collects
+	// metrics.
+	Requests *RequestsReport `json:"requests,omitempty"`
+}
+
+// RequestsReport summarizes the requests a conformance test made.
+type RequestsReport struct {
+	// Count is the number of requests.
+	Count int `json:"count"`
+	// P50Micros, P90Micros and MaxMicros are the 50th and 90th percentiles
+	// and the maximum of the latencies of the requests, in microseconds.
+	P50Micros int64 `json:"p50Micros"`
+	P90Micros int64 `json:"p90Micros"`
+	MaxMicros int64 `json:"maxMicros"`
+	// StatusCodes counts the requests by the status code of their response,
+	// 0 for requests that failed without a response.
+	StatusCodes map[int]int `json:"statusCodes,omitempty"`
+}
+
+// Report returns the report of the tests executed so far. An error is
+// returned if tests are still running, as their outcome isn't known yet.
+func (suite *ConformanceTestSuite) Report() (ConformanceReport, error) {
+	results, err := suite.finishedResults()
+	if err != nil {
+		return ConformanceReport{}, err
+	}
+
+	report := ConformanceReport{
+		GatewayClassName:    suite.GatewayClassName,
+		ControllerName:      suite.ControllerName,
+		MinChannel:          suite.MinChannel.String(),
+		ConformanceProfiles: suite.ConformanceProfiles,
+		SupportedFeatures:   append([]SupportedFeature(nil), suite.SupportedFeatures...),
+		ProfileReports:      append([]ProfileReport(nil), suite.ProfileReports...),
+		Tests:               make([]TestReport, 0, len(results)),
+	}
+	sort.Slice(report.SupportedFeatures, func(i, j int) bool {
+		return report.SupportedFeatures[i] < report.SupportedFeatures[j]
+	})
+	for _, result := range results {
+		report.Tests = append(report.Tests, TestReport{
+			ShortName:           result.ShortName,
+			Description:         result.Description,
+			Features:            result.Features,
+			Status:              result.Outcome,
+			SkipCategory:        result.SkipCategory,
+			SkipReason:          result.SkipReason,
+			DurationMillis:      result.Duration.Milliseconds(),
+			TotalDurationMillis: result.TotalDuration.Milliseconds(),
+			Requests:            requestsReport(result.RequestMetrics),
+		})
+	}
+	sort.SliceStable(report.Tests, func(i, j int) bool {
+		return report.Tests[i].ShortName < report.Tests[j].ShortName
+	})
+
+	return report, nil
+}
+
+// requestsReport returns the report of the request metrics, or nil if
+// metrics weren't collected.
+func requestsReport(metrics *roundtripper.RequestMetrics) *RequestsReport {
+	if metrics == nil {
+		return nil
+	}
+	return &RequestsReport{
+		Count:       metrics.Count,
+		P50Micros:   metrics.P50.Microseconds(),
+		P90Micros:   metrics.P90.Microseconds(),
+		MaxMicros:   metrics.Max.Microseconds(),
+		StatusCodes: metrics.StatusCodes,
+	}
+}
+
+// writeReport writes the report as indented JSON to the file at path.
+func (suite *ConformanceTestSuite) writeReport(path string) error {
+	report, err := suite.Report()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling conformance report: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformanceReport(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.json")
+	cSuite := mustNew(t, Options{GatewayClassName: "gateway-conformance", MinChannel: StandardChannel, ReportOutput: output})
+	cSuite.ControllerName = "example.com/gateway-controller"
+
+	var reportErr error
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{{
+			ShortName:   "Passing",
+			Description: "A passing test",
+			MinChannel:  StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				_, reportErr = s.Report()
+			},
+		}, {
+			ShortName:  "Unsupported",
+			Features:   []SupportedFeature{SupportReferencePolicy},
+			MinChannel: StandardChannel,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}, {
+			ShortName:  "Experimental",
+			MinChannel: ExperimentalChannel,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}})
+	})
+	require.Error(t, reportErr, "expected no report while tests are running")
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err, "expected the report to be written once tests are done")
+	written := ConformanceReport{}
+	require.NoError(t, json.Unmarshal(data, &written))
+
+	report, err := cSuite.Report()
+	require.NoError(t, err)
+	require.Equal(t, report, written)
+
+	require.Equal(t, "gateway-conformance", report.GatewayClassName)
+	require.Equal(t, "example.com/gateway-controller", report.ControllerName)
+	require.Equal(t, "standard", report.MinChannel)
+	require.Len(t, report.Tests, 3)
+
+	experimental, passing, unsupported := report.Tests[0], report.Tests[1], report.Tests[2]
+	require.Equal(t, "Passing", passing.ShortName)
+	require.Equal(t, "A passing test", passing.Description)
+	require.Equal(t, TestPassed, passing.Status)
+	require.Empty(t, passing.SkipCategory)
+
+	require.Equal(t, "Unsupported", unsupported.ShortName)
+	require.Equal(t, TestSkipped, unsupported.Status)
+	require.Equal(t, SkipUnsupportedFeature, unsupported.SkipCategory)
+	require.Equal(t, []SupportedFeature{SupportReferencePolicy}, unsupported.Features)
+
+	require.Equal(t, "Experimental", experimental.ShortName)
+	require.Equal(t, TestSkipped, experimental.Status)
+	require.Equal(t, SkipChannel, experimental.SkipCategory)
+
+	require.Contains(t, string(data), `"skipCategory": "UnsupportedFeature"`)
+	require.Contains(t, string(data), `"durationMillis": `)
+}
+
+func TestConformanceReportProfileReports(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "report.json")
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, ReportOutput: output})
+
+	t.Run("run", func(t *testing.T) {
+		cSuite.RunProfiles(t, []ConformanceTest{{
+			ShortName:  "Core",
+			MinChannel: StandardChannel,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}, {
+			ShortName:  "ReferencePolicy",
+			Features:   []SupportedFeature{SupportReferencePolicy},
+			MinChannel: StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				t.Skip("not applicable")
+			},
+		}}, []ConformanceProfileName{HTTPProfileName})
+	})
+
+	data, err := os.ReadFile(output)
+	require.NoError(t, err, "expected the report to be written once profile tests are done")
+	written := ConformanceReport{}
+	require.NoError(t, json.Unmarshal(data, &written))
+
+	expected := []ProfileReport{{
+		Name:    HTTPProfileName,
+		Passed:  []string{"Core"},
+		Skipped: []string{"ReferencePolicy"},
+	}}
+	require.Equal(t, expected, written.ProfileReports, "expected the written report to hold the profile reports")
+	require.Len(t, written.Tests, 2, "expected the profile tests to be reported")
+
+	report, err := cSuite.Report()
+	require.NoError(t, err)
+	require.Equal(t, expected, report.ProfileReports)
+	data, err = json.Marshal(report.ProfileReports)
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"name":"HTTP","passed":["Core"],"skipped":["ReferencePolicy"]}]`, string(data))
+}
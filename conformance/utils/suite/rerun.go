@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadRerunOutcomes returns the outcome of every test of the report at path,
+// keyed by the short name of the test. An error is returned if the report
+// can't be read or isn't a valid conformance report.
+func loadRerunOutcomes(path string) (map[string]TestOutcome, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading report to rerun failed tests from: %w", err)
+	}
+	var report ConformanceReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("error parsing report %s: %w", path, err)
+	}
+	if len(report.Tests) == 0 {
+		return nil, fmt.Errorf("report %s has no tests", path)
+	}
+
+	outcomes := make(map[string]TestOutcome, len(report.Tests))
+	for i, test := range report.Tests {
+		switch {
+		case test.ShortName == "":
+			return nil, fmt.Errorf("test %d of report %s has no shortName", i, path)
+		case test.Status != TestPassed && test.Status != TestFailed && test.Status != TestSkipped:
+			return nil, fmt.Errorf("test %s of report %s has unknown status %q", test.ShortName, path, test.Status)
+		}
+		outcomes[test.ShortName] = test.Status
+	}
+	return outcomes, nil
+}
+
+// validateRerun returns an error if a test of the report RerunFailedFrom
+// points at isn't one of the provided tests, e.g. because the report comes
+// from a different version of the suite.
+func (suite *ConformanceTestSuite) validateRerun(tests []ConformanceTest) error {
+	known := make(map[string]bool, len(tests))
+	for _, test := range tests {
+		known[test.ShortName] = true
+	}
+	var unknown []string
+	for shortName := range suite.rerunOutcomes {
+		if !known[shortName] {
+			unknown = append(unknown, shortName)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("report %s doesn't match the tests, unknown tests: %s", suite.RerunFailedFrom, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// rerunSkipReason returns why the test is skipped when rerunning the failed
+// tests of a previous report, or an empty string if it must run.
+func (suite *ConformanceTestSuite) rerunSkipReason(test *ConformanceTest) string {
+	if suite.rerunOutcomes == nil {
+		return ""
+	}
+	outcome, ok := suite.rerunOutcomes[test.ShortName]
+	switch {
+	case !ok:
+		return "not part of the previous report"
+	case outcome != TestFailed:
+		return fmt.Sprintf("didn't fail in the previous run (%s)", outcome)
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes the contents to a file in a temporary directory and
+// returns its path.
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestRerunFailedFromInvalidReports(t *testing.T) {
+	_, err := New(Options{RerunFailedFrom: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "error reading report to rerun failed tests from")
+
+	testCases := map[string]string{
+		"not json":       `tests: []`,
+		"no tests":       `{"tests": []}`,
+		"no short name":  `{"tests": [{"status": "Failed"}]}`,
+		"unknown status": `{"tests": [{"shortName": "HTTPRouteSimple", "status": "Errored"}]}`,
+	}
+	for name, contents := range testCases {
+		path := writeFile(t, "report.json", contents)
+		_, err := New(Options{RerunFailedFrom: path})
+		require.Errorf(t, err, "expected a report with %s to be rejected", name)
+	}
+}
+
+func TestRerunFailedFrom(t *testing.T) {
+	path := writeFile(t, "report.json", `{
+  "gatewayClassName": "gateway-conformance",
+  "tests": [
+    {"shortName": "Passed", "status": "Passed"},
+    {"shortName": "Failed", "status": "Failed"},
+    {"shortName": "Skipped", "status": "Skipped"},
+    {"shortName": "FailedExperimental", "status": "Failed"}
+  ]
+}`)
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, RerunFailedFrom: path})
+
+	var ran []string
+	test := func(shortName string, channel GatewayChannel) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  shortName,
+			MinChannel: channel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				ran = append(ran, shortName)
+			},
+		}
+	}
+	tests := []ConformanceTest{
+		test("Passed", StandardChannel),
+		test("Failed", StandardChannel),
+		test("Skipped", StandardChannel),
+		test("FailedExperimental", ExperimentalChannel),
+		test("New", StandardChannel),
+	}
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, tests)
+	})
+	require.Equal(t, []string{"Failed"}, ran)
+
+	expected := map[string][2]string{
+		"Passed":             {string(SkipRerun), "didn't fail in the previous run (Passed)"},
+		"Skipped":            {string(SkipRerun), "didn't fail in the previous run (Skipped)"},
+		"FailedExperimental": {string(SkipChannel), "only testing standard channel"},
+		"New":                {string(SkipRerun), "not part of the previous report"},
+	}
+	for shortName, skip := range expected {
+		result, ok := cSuite.GetTestResult(shortName)
+		require.True(t, ok)
+		require.Equalf(t, TestSkipped, result.Outcome, "expected %s to be skipped", shortName)
+		require.Equal(t, skip, [2]string{string(result.SkipCategory), result.SkipReason})
+	}
+
+	require.NoError(t, cSuite.validateRerun(tests))
+	err := cSuite.validateRerun(tests[1:3])
+	require.EqualError(t, err, "report "+path+" doesn't match the tests, unknown tests: FailedExperimental, Passed")
+}
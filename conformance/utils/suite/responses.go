@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/http"
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+const (
+	// stableWrongResponses is how many identical wrong responses in a row
+	// MustEventuallyGetResponse takes as final rather than programming lag.
+	stableWrongResponses = 3
+	// responseInitialBackoff and responseMaxBackoff bound the wait between
+	// two requests of MustEventuallyGetResponse.
+	responseInitialBackoff = 100 * time.Millisecond
+	responseMaxBackoff     = 2 * time.Second
+)
+
+// observedResponse is what MustEventuallyGetResponse compares to the
+// expected response.
+type observedResponse struct {
+	StatusCode int
+	Path       string
+	Method     string
+	Namespace  string
+	Backend    string
+	Err        string
+}
+
+// MustEventuallyGetResponse makes the request with the RoundTripper of the
+// suite until the response matches expected, backing off exponentially
+// between requests, and verifies it with http.ExpectResponse. 404 and 503
+// responses and failed requests are taken as routes still being programmed
+// until timeout elapses. Any other wrong response fails the test as soon as
+// it is received stableWrongResponses times in a row. If no matching response
+// is received in time, the test fails with a diff between the last and the
+// expected response.
+func (suite *ConformanceTestSuite) MustEventuallyGetResponse(ctx context.Context, t *testing.T, req roundtripper.Request, expected http.ExpectedResponse, timeout time.Duration) (*roundtripper.CapturedRequest, *roundtripper.CapturedResponse) {
+	t.Helper()
+
+	if expected.Request.Method == "" {
+		expected.Request.Method = "GET"
+	}
+	if expected.StatusCode == 0 {
+		expected.StatusCode = 200
+	}
+
+	cReq, cRes, last, err := suite.eventuallyGetResponse(ctx, t, req, expected, timeout)
+	if err != nil {
+		require.Equalf(t, expectedObservation(expected), last, "%v", err)
+		require.FailNow(t, err.Error())
+	}
+	http.ExpectResponse(t, cReq, cRes, expected)
+	return cReq, cRes
+}
+
+// eventuallyGetResponse does the polling of MustEventuallyGetResponse,
+// returning the last observed response along with an error if no matching
+// response was received.
+func (suite *ConformanceTestSuite) eventuallyGetResponse(ctx context.Context, t *testing.T, req roundtripper.Request, expected http.ExpectedResponse, timeout time.Duration) (*roundtripper.CapturedRequest, *roundtripper.CapturedResponse, observedResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if req.Context == nil {
+		req.Context = ctx
+	}
+
+	want := expectedObservation(expected)
+	var (
+		last   observedResponse
+		stable int
+	)
+	for backoff := responseInitialBackoff; ; backoff *= 2 {
+		cReq, cRes, err := suite.RoundTripper.CaptureRoundTrip(req)
+		observed := observe(cReq, cRes, err, expected.Backend, suite.Backends)
+		if observed == want {
+			return cReq, cRes, observed, nil
+		}
+
+		switch {
+		case observed.Err != "" || observed.StatusCode == nethttp.StatusNotFound || observed.StatusCode == nethttp.StatusServiceUnavailable:
+			stable = 0
+			t.Logf("Expected status %d, got %s, route may not be programmed yet", want.StatusCode, observed)
+		case observed == last:
+			stable++
+		default:
+			stable = 1
+		}
+		last = observed
+		if stable >= stableWrongResponses {
+			return cReq, cRes, last, fmt.Errorf("got the same wrong response %d times in a row: %s", stable, last)
+		}
+
+		if backoff > responseMaxBackoff {
+			backoff = responseMaxBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return cReq, cRes, last, fmt.Errorf("never got the expected response within %s, last response was %s", timeout, last)
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// expectedObservation returns the observedResponse matching expected.
+func expectedObservation(expected http.ExpectedResponse) observedResponse {
+	want := observedResponse{StatusCode: expected.StatusCode}
+	if expected.StatusCode == 200 {
+		want.Path, want.Method = expected.Request.Path, expected.Request.Method
+		want.Namespace, want.Backend = expected.Namespace, expected.Backend
+	}
+	return want
+}
+
+// observe returns what is compared of a round trip. The Pod reported by the
+// backend is reduced to backend if it's one of its Pods, or else to the
+// AppLabel of the backend of backends serving it, so that responses of
+// different Pods of a wrong backend compare equal.
+func observe(cReq *roundtripper.CapturedRequest, cRes *roundtripper.CapturedResponse, err error, backend string, backends []kubernetes.Backend) observedResponse {
+	if err != nil {
+		return observedResponse{Err: err.Error()}
+	}
+	observed := observedResponse{StatusCode: cRes.StatusCode}
+	if cRes.StatusCode == 200 {
+		observed.Path, observed.Method, observed.Namespace = cReq.Path, cReq.Method, cReq.Namespace
+		observed.Backend = cReq.Pod
+		if strings.HasPrefix(cReq.Pod, backend) {
+			observed.Backend = backend
+		} else if served, ok := kubernetes.BackendFor(backends, cReq.Namespace, cReq.Pod); ok {
+			observed.Backend = served.AppLabel
+		}
+	}
+	return observed
+}
+
+// String formats the observed response for logs.
+func (o observedResponse) String() string {
+	if o.Err != "" {
+		return fmt.Sprintf("error %q", o.Err)
+	}
+	if o.StatusCode != 200 {
+		return fmt.Sprintf("status %d", o.StatusCode)
+	}
+	return fmt.Sprintf("status %d from %s in %s for %s %s", o.StatusCode, o.Backend, o.Namespace, o.Method, o.Path)
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/http"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestMustEventuallyGetResponse(t *testing.T) {
+	// The fake Gateway responds with the provided status codes in turn,
+	// then echoes requests as infra-backend-v1.
+	fakeGateway := func(t *testing.T, statusCodes ...int) roundtripper.Request {
+		var requests int32
+		server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if i := int(atomic.AddInt32(&requests, 1)) - 1; i < len(statusCodes) {
+				w.WriteHeader(statusCodes[i])
+				return
+			}
+			w.Header().Set("Content-type", "application/json")
+			_ = json.NewEncoder(w).Encode(roundtripper.CapturedRequest{
+				Path:      r.URL.Path,
+				Method:    r.Method,
+				Namespace: "gateway-conformance-infra",
+				Pod:       "infra-backend-v1-7f9c8d6b5-x2x9z",
+			})
+		}))
+		t.Cleanup(server.Close)
+
+		serverURL, err := url.Parse(server.URL + "/")
+		require.NoError(t, err)
+		return roundtripper.Request{URL: *serverURL, Protocol: "HTTP"}
+	}
+	expected := http.ExpectedResponse{
+		Request:   http.ExpectedRequest{Path: "/", Method: "GET"},
+		Backend:   "infra-backend-v1",
+		Namespace: "gateway-conformance-infra",
+	}
+	cSuite := mustNew(t, Options{})
+
+	t.Run("programming lag", func(t *testing.T) {
+		req := fakeGateway(t, 404, 503, 404)
+		cReq, cRes := cSuite.MustEventuallyGetResponse(context.Background(), t, req, expected, 10*time.Second)
+		require.Equal(t, 200, cRes.StatusCode)
+		require.Equal(t, "infra-backend-v1-7f9c8d6b5-x2x9z", cReq.Pod)
+	})
+
+	t.Run("stable wrong response", func(t *testing.T) {
+		req := fakeGateway(t, 500, 500, 500, 500, 500)
+		start := time.Now()
+		_, _, last, err := cSuite.eventuallyGetResponse(context.Background(), t, req, http.ExpectedResponse{Request: expected.Request, StatusCode: 200}, 10*time.Second)
+		require.EqualError(t, err, "got the same wrong response 3 times in a row: status 500")
+		require.Equal(t, observedResponse{StatusCode: 500}, last)
+		require.Less(t, int64(time.Since(start)), int64(5*time.Second), "expected a stable wrong response to fail fast")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		req := fakeGateway(t, 404, 404, 404, 404, 404, 404, 404, 404)
+		_, _, last, err := cSuite.eventuallyGetResponse(context.Background(), t, req, http.ExpectedResponse{Request: expected.Request, StatusCode: 200}, 500*time.Millisecond)
+		require.EqualError(t, err, "never got the expected response within 500ms, last response was status 404")
+		require.Equal(t, observedResponse{StatusCode: 404}, last)
+	})
+}
+
+func TestObserveBackends(t *testing.T) {
+	cRes := &roundtripper.CapturedResponse{StatusCode: 200}
+	observed := func(namespace, pod string) string {
+		cReq := &roundtripper.CapturedRequest{Namespace: namespace, Pod: pod}
+		return observe(cReq, cRes, nil, "infra-backend-v1", DefaultBackends).Backend
+	}
+
+	require.Equal(t, "infra-backend-v1", observed("gateway-conformance-infra", "infra-backend-v1-7f9c8d6b5-x2x9z"))
+	// Pods of the same wrong backend are reported alike.
+	require.Equal(t, "infra-backend-v2", observed("gateway-conformance-infra", "infra-backend-v2-7f9c8d6b5-x2x9z"))
+	require.Equal(t, "infra-backend-v2", observed("gateway-conformance-infra", "infra-backend-v2-7f9c8d6b5-a1b2c"))
+	require.Equal(t, "infra-backend-v2-7f9c8d6b5-x2x9z", observed("other", "infra-backend-v2-7f9c8d6b5-x2x9z"), "expected Pods of unknown backends to be reported as is")
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// TestOutcome is the outcome of a conformance test.
+type TestOutcome string
+
+const (
+	TestPassed  TestOutcome = "Passed"
+	TestFailed  TestOutcome = "Failed"
+	TestSkipped TestOutcome = "Skipped"
+)
+
+// SkipCategory tells why the suite skipped a conformance test.
+type SkipCategory string
+
+const (
+	// SkipUnsupportedFeature is used for tests exercising a feature the
+	// suite does not support.
+	SkipUnsupportedFeature SkipCategory = "UnsupportedFeature"
+	// SkipExemptFeature is used for tests exercising a feature the suite
+	// exempts.
+	SkipExemptFeature SkipCategory = "ExemptFeature"
+	// SkipChannel is used for tests of a less stable release channel than
+	// the suite runs tests for.
+	SkipChannel SkipCategory = "Channel"
+	// SkipVerify is used for tests that can't run when verifying existing
+	// resources.
+	SkipVerify SkipCategory = "Verify"
+	// SkipExplicit is used for tests listed in SkipTests.
+	SkipExplicit SkipCategory = "Explicit"
+	// SkipFailFast is used for tests that didn't start before a test
+	// failed with FailFast set.
+	SkipFailFast SkipCategory = "FailFast"
+	// SkipRerun is used for tests that didn't fail in the report
+	// RerunFailedFrom points at.
+	SkipRerun SkipCategory = "Rerun"
+	// SkipNotSelected is used in dry-run plans for tests filtered out by
+	// RunResources or RunTest, which Run doesn't report.
+	SkipNotSelected SkipCategory = "NotSelected"
+)
+
+// TestResult holds the result of a conformance test executed by Run.
+type TestResult struct {
+	ShortName   string
+	Description string
+	Features    []SupportedFeature
+	Outcome     TestOutcome
+	// Duration is how long the Test function of the test took, or how long
+	// the test took to be skipped if it wasn't called.
+	Duration time.Duration
+	// TotalDuration is how long the test took as a whole, including
+	// applying its manifests and cleaning them up.
+	TotalDuration time.Duration
+	// Slow is the Slow field of the test.
+	Slow bool
+	// SkipReason and SkipCategory are set for tests skipped by the suite.
+	// Tests skipping themselves have no SkipCategory.
+	SkipReason   string
+	SkipCategory SkipCategory
+	// RequestMetrics summarizes the requests the test made, if the suite
+	// collects metrics.
+	RequestMetrics *roundtripper.RequestMetrics
+}
+
+// testResults accumulates the results of the tests executed by Run, in the
+// order they finish.
+type testResults struct {
+	mu      sync.Mutex
+	results []TestResult
+	running int
+	failed  bool
+	details map[string]*testDetails
+}
+
+// testDetails holds what the suite records about a running test, keyed by
+// the name of the test.
+type testDetails struct {
+	skipCategory SkipCategory
+	skipReason   string
+	duration     time.Duration
+	timed        bool
+	metrics      *roundtripper.RequestMetrics
+	// started is when the test started running, once resumed if it's
+	// parallel.
+	started time.Time
+}
+
+// trackResult records the result of the test once t and its subtests are
+// done.
+func (suite *ConformanceTestSuite) trackResult(t *testing.T, test ConformanceTest) {
+	suite.results.mu.Lock()
+	suite.results.running++
+	suite.results.mu.Unlock()
+
+	start := time.Now()
+	t.Cleanup(func() {
+		result := TestResult{
+			ShortName:   test.ShortName,
+			Description: test.Description,
+			Features:    test.Features,
+			Outcome:     TestPassed,
+			Duration:    time.Since(start),
+			Slow:        test.Slow,
+		}
+		result.TotalDuration = result.Duration
+
+		suite.results.mu.Lock()
+		defer suite.results.mu.Unlock()
+
+		suite.results.running--
+
+		details := suite.results.details[t.Name()]
+		delete(suite.results.details, t.Name())
+		if details == nil {
+			details = &testDetails{}
+		}
+		if !details.started.IsZero() {
+			result.TotalDuration = time.Since(details.started)
+		}
+		if details.timed {
+			result.Duration = details.duration
+		}
+		result.RequestMetrics = details.metrics
+
+		switch {
+		case t.Failed():
+			result.Outcome = TestFailed
+			suite.results.failed = true
+		case t.Skipped():
+			result.Outcome = TestSkipped
+			result.SkipReason, result.SkipCategory = details.skipReason, details.skipCategory
+		}
+		suite.results.results = append(suite.results.results, result)
+	})
+}
+
+// anyFailed returns true if any test tracked so far failed.
+func (suite *ConformanceTestSuite) anyFailed() bool {
+	suite.results.mu.Lock()
+	defer suite.results.mu.Unlock()
+	return suite.results.failed
+}
+
+// testDetails returns the details recorded for the running test. The results
+// lock must be held.
+func (suite *ConformanceTestSuite) testDetails(t *testing.T) *testDetails {
+	if suite.results.details == nil {
+		suite.results.details = map[string]*testDetails{}
+	}
+	details, ok := suite.results.details[t.Name()]
+	if !ok {
+		details = &testDetails{}
+		suite.results.details[t.Name()] = details
+	}
+	return details
+}
+
+// recordStart records that the test started running, so that time spent
+// waiting for parallel tests to resume doesn't count towards its
+// TotalDuration.
+func (suite *ConformanceTestSuite) recordStart(t *testing.T) {
+	suite.results.mu.Lock()
+	defer suite.results.mu.Unlock()
+	suite.testDetails(t).started = time.Now()
+}
+
+// runTimed calls the Test function of the test, recording how long it took
+// as the duration of the test. The test fails if it exceeds its timeout, at
+// which point the context passed to the Test function is cancelled.
+func (suite *ConformanceTestSuite) runTimed(ctx context.Context, t *testing.T, test *ConformanceTest) {
+	var stopMetrics func() roundtripper.RequestMetrics
+	if suite.Metrics != nil {
+		ctx, stopMetrics = suite.Metrics.Start(ctx)
+	}
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		suite.results.mu.Lock()
+		defer suite.results.mu.Unlock()
+
+		details := suite.testDetails(t)
+		details.duration, details.timed = duration, true
+		if stopMetrics != nil {
+			metrics := stopMetrics()
+			details.metrics = &metrics
+		}
+	}()
+
+	timeout := test.Timeout
+	if timeout == 0 {
+		timeout = suite.timeouts().TestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	defer watchTimeout(ctx, t, test.ShortName, timeout)()
+
+	test.Test(ctx, t, suite)
+}
+
+// errorer is the subset of *testing.T used to report timeouts.
+type errorer interface {
+	Errorf(format string, args ...interface{})
+}
+
+// watchTimeout reports a test timing out as soon as the context deadline is
+// exceeded, and returns a function stopping the watch. It waits for the
+// watch to stop so that it never outlives the test, and must be deferred
+// to run even when the test calls t.FailNow. It only marks the test as
+// failed: a Test function ignoring the cancellation of its context keeps
+// running until it returns on its own.
+func watchTimeout(ctx context.Context, t errorer, shortName string, timeout time.Duration) func() {
+	done, exited := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(exited)
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.Errorf("%s timed out after %s", shortName, timeout)
+			}
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// recordSkip records why the suite is about to skip the test, so it can be
+// reported.
+func (suite *ConformanceTestSuite) recordSkip(t *testing.T, category SkipCategory, reason string) {
+	suite.results.mu.Lock()
+	defer suite.results.mu.Unlock()
+
+	details := suite.testDetails(t)
+	details.skipCategory, details.skipReason = category, reason
+}
+
+// finishedResults returns a copy of the results of the tests executed so
+// far. An error is returned if tests are still running, as their outcome
+// isn't known yet.
+func (suite *ConformanceTestSuite) finishedResults() ([]TestResult, error) {
+	suite.results.mu.Lock()
+	defer suite.results.mu.Unlock()
+
+	if suite.results.running > 0 {
+		return nil, fmt.Errorf("%d tests are still running", suite.results.running)
+	}
+	results := make([]TestResult, len(suite.results.results))
+	copy(results, suite.results.results)
+	return results, nil
+}
+
+// Results returns a copy of the results of the tests executed by Run, in the
+// order they finished. Once Run returns, this includes every test it ran,
+// parallel ones included; while it runs, only the tests finished so far are
+// returned.
+func (suite *ConformanceTestSuite) Results() []TestResult {
+	suite.results.mu.Lock()
+	defer suite.results.mu.Unlock()
+
+	results := make([]TestResult, len(suite.results.results))
+	for i, result := range suite.results.results {
+		result.Features = append([]SupportedFeature(nil), result.Features...)
+		if result.RequestMetrics != nil {
+			metrics := *result.RequestMetrics
+			metrics.StatusCodes = make(map[int]int, len(metrics.StatusCodes))
+			for statusCode, count := range result.RequestMetrics.StatusCodes {
+				metrics.StatusCodes[statusCode] = count
+			}
+			result.RequestMetrics = &metrics
+		}
+		results[i] = result
+	}
+	return results
+}
+
+// GetTestResult returns the result of the test with the provided short name
+// executed by Run, if it finished. If the test ran several times, e.g.
+// through several calls to Run, the last result is returned.
+func (suite *ConformanceTestSuite) GetTestResult(shortName string) (TestResult, bool) {
+	results := suite.Results()
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].ShortName == shortName {
+			return results[i], true
+		}
+	}
+	return TestResult{}, false
+}
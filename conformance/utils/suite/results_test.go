@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+func TestResults(t *testing.T) {
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, SupportedFeatures: []SupportedFeature{SupportReferenceGrant}})
+	require.Empty(t, cSuite.Results(), "expected no results before Run")
+
+	test := func(shortName string, features ...SupportedFeature) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  shortName,
+			MinChannel: StandardChannel,
+			Features:   features,
+			Parallel:   true,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}
+	}
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{
+			test("Supported", SupportReferenceGrant),
+			test("Unsupported", SupportTCPRoute),
+			test("Plain"),
+		})
+	})
+
+	results := cSuite.Results()
+	require.Len(t, results, 3, "expected parallel tests to be tracked once Run returns")
+
+	supported, ok := cSuite.GetTestResult("Supported")
+	require.True(t, ok)
+	require.Equal(t, TestPassed, supported.Outcome)
+	require.Equal(t, []SupportedFeature{SupportReferenceGrant}, supported.Features)
+
+	unsupported, ok := cSuite.GetTestResult("Unsupported")
+	require.True(t, ok)
+	require.Equal(t, TestSkipped, unsupported.Outcome)
+	require.Equal(t, SkipUnsupportedFeature, unsupported.SkipCategory)
+	require.NotEmpty(t, unsupported.SkipReason)
+
+	_, ok = cSuite.GetTestResult("Missing")
+	require.False(t, ok)
+
+	for i := range results {
+		results[i].Outcome = TestFailed
+		if len(results[i].Features) > 0 {
+			results[i].Features[0] = SupportTCPRoute
+		}
+	}
+	supported, _ = cSuite.GetTestResult("Supported")
+	require.Equal(t, TestPassed, supported.Outcome, "expected Results to return a copy")
+	require.Equal(t, []SupportedFeature{SupportReferenceGrant}, supported.Features, "expected Results to copy features")
+}
+
+func TestRequestMetrics(t *testing.T) {
+	server := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(nethttp.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	request := func(ctx context.Context, t *testing.T, s *ConformanceTestSuite, path string) {
+		u, err := url.Parse(server.URL + path)
+		require.NoError(t, err)
+		_, _, err = s.RoundTripper.CaptureRoundTrip(roundtripper.Request{URL: *u, Context: ctx})
+		require.NoError(t, err)
+	}
+	tests := []ConformanceTest{{
+		ShortName:  "ThreeRequests",
+		MinChannel: StandardChannel,
+		Parallel:   true,
+		Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			request(ctx, t, s, "/")
+			request(ctx, t, s, "/")
+			request(ctx, t, s, "/missing")
+		},
+	}, {
+		ShortName:  "NoRequests",
+		MinChannel: StandardChannel,
+		Parallel:   true,
+		Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			// Requests made with another context don't count for the test.
+			request(context.Background(), t, s, "/")
+		},
+	}}
+
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel})
+	require.Nil(t, cSuite.Metrics, "expected metrics not to be collected by default")
+	t.Run("without metrics", func(t *testing.T) { cSuite.Run(t, tests) })
+	result, _ := cSuite.GetTestResult("ThreeRequests")
+	require.Nil(t, result.RequestMetrics)
+
+	cSuite = mustNew(t, Options{MinChannel: StandardChannel, CollectMetrics: true})
+	t.Run("with metrics", func(t *testing.T) { cSuite.Run(t, tests) })
+
+	result, _ = cSuite.GetTestResult("ThreeRequests")
+	require.NotNil(t, result.RequestMetrics)
+	require.Equal(t, 3, result.RequestMetrics.Count)
+	require.Equal(t, map[int]int{200: 2, 404: 1}, result.RequestMetrics.StatusCodes)
+
+	result, _ = cSuite.GetTestResult("NoRequests")
+	require.NotNil(t, result.RequestMetrics)
+	require.Zero(t, result.RequestMetrics.Count)
+
+	report, err := cSuite.Report()
+	require.NoError(t, err)
+	require.Equal(t, "NoRequests", report.Tests[0].ShortName)
+	require.Equal(t, 3, report.Tests[1].Requests.Count)
+	require.Equal(t, map[int]int{200: 2, 404: 1}, report.Tests[1].Requests.StatusCodes)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShuffle(t *testing.T) {
+	names := []string{"A", "B", "C", "D", "E", "F"}
+	runOrder := func(seed int64) []string {
+		var ran []string
+		tests := make([]ConformanceTest, 0, len(names))
+		for _, name := range names {
+			name := name
+			tests = append(tests, ConformanceTest{
+				ShortName:  name,
+				MinChannel: StandardChannel,
+				Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+					ran = append(ran, name)
+				},
+			})
+		}
+		cSuite := mustNew(t, Options{MinChannel: StandardChannel, Shuffle: true, Seed: seed})
+		t.Run("run", func(t *testing.T) {
+			cSuite.Run(t, tests)
+		})
+		return ran
+	}
+
+	first := runOrder(42)
+	require.ElementsMatch(t, names, first)
+	require.NotEqual(t, names, first, "expected tests to be shuffled")
+	require.Equal(t, first, runOrder(42), "expected the same seed to give the same order")
+
+	require.NotZero(t, mustNew(t, Options{Shuffle: true}).Seed, "expected a seed to be picked")
+	require.Zero(t, mustNew(t, Options{}).Seed)
+}
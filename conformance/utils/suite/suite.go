@@ -17,12 +17,21 @@ limitations under the License.
 package suite
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
 	"testing"
+	"time"
 
 	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
 	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
 	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
 )
@@ -33,8 +42,12 @@ type ExemptFeature string
 
 const (
 	// This option indicates the implementation is exempting itself from the
-	// requirement of a ReferencePolicy to allow cross-namesapce references,
+	// requirement of a ReferenceGrant to allow cross-namesapce references,
 	// and has instead implemented alternative safeguards.
+	ExemptReferenceGrant ExemptFeature = "ReferenceGrant"
+
+	// Deprecated: ReferencePolicy was renamed to ReferenceGrant, use
+	// ExemptReferenceGrant. The suite treats both the same.
 	ExemptReferencePolicy ExemptFeature = "ReferencePolicy"
 )
 
@@ -43,8 +56,39 @@ const (
 type SupportedFeature string
 
 const (
-	// This option indicates support for the ReferencePolicy object.
+	// This option indicates support for the ReferenceGrant object.
+	SupportReferenceGrant SupportedFeature = "ReferenceGrant"
+
+	// Deprecated: ReferencePolicy was renamed to ReferenceGrant, use
+	// SupportReferenceGrant. The suite treats both the same.
 	SupportReferencePolicy SupportedFeature = "ReferencePolicy"
+
+	// This option indicates support for TCPRoute. Since TCPRoute is part of
+	// the experimental channel, tests exercising it must also have their
+	// MinChannel set to ExperimentalChannel.
+	SupportTCPRoute SupportedFeature = "TCPRoute"
+
+	// This option indicates support for UDPRoute. Since UDPRoute is part of
+	// the experimental channel, tests exercising it must also have their
+	// MinChannel set to ExperimentalChannel.
+	SupportUDPRoute SupportedFeature = "UDPRoute"
+
+	// This option indicates support for every extended HTTPRoute feature
+	// below. It expands into them, while implementations supporting only
+	// some of them can claim those alone.
+	SupportHTTPRoute SupportedFeature = "HTTPRoute"
+
+	// This option indicates support for matching HTTPRoute requests by
+	// method.
+	SupportHTTPRouteMethodMatching SupportedFeature = "HTTPRouteMethodMatching"
+
+	// This option indicates support for matching HTTPRoute requests by
+	// query parameters.
+	SupportHTTPRouteQueryParamMatching SupportedFeature = "HTTPRouteQueryParamMatching"
+
+	// This option indicates support for the RequestMirror filter of
+	// HTTPRoute.
+	SupportHTTPRouteRequestMirror SupportedFeature = "HTTPRouteRequestMirror"
 )
 
 // GatewatChannel allows opting between experimental or standard conformance tests.
@@ -55,152 +99,933 @@ const (
 	StandardChannel     GatewayChannel = 2
 )
 
+// Valid returns true if the channel is one of the defined channels.
+func (c GatewayChannel) Valid() bool {
+	return c == ExperimentalChannel || c == StandardChannel
+}
+
+// String returns the name of the channel, e.g. "standard".
+func (c GatewayChannel) String() string {
+	switch c {
+	case ExperimentalChannel:
+		return "experimental"
+	case StandardChannel:
+		return "standard"
+	}
+	return fmt.Sprintf("unknown(%d)", int(c))
+}
+
+// ShouldRun returns true if the suite runs tests whose MinChannel is the
+// provided channel. Experimental suites run every test, while standard
+// suites only run standard tests:
+//
+//	suite \ test   experimental  standard
+//	experimental   runs          runs
+//	standard       skipped       runs
+//
+// Tests of an unknown channel are never run. A suite without a MinChannel
+// behaves as a standard one.
+func (suite *ConformanceTestSuite) ShouldRun(channel GatewayChannel) bool {
+	switch suite.MinChannel {
+	case ExperimentalChannel:
+		return channel == ExperimentalChannel || channel == StandardChannel
+	case StandardChannel, 0:
+		return channel == StandardChannel
+	}
+	return false
+}
+
+// DefaultNamespaces are the namespaces created by the default base manifests,
+// which Setup waits for unless Options.Namespaces is set.
+var DefaultNamespaces = []string{
+	"gateway-conformance-infra",
+	"gateway-conformance-app-backend",
+	"gateway-conformance-web-backend",
+}
+
+// DefaultBackends are the backends deployed by the default base manifests,
+// which Setup waits for unless Options.Backends or Options.Namespaces is set.
+var DefaultBackends = []kubernetes.Backend{
+	{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v1"},
+	{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v2"},
+	{Namespace: "gateway-conformance-infra", AppLabel: "infra-backend-v3"},
+	{Namespace: "gateway-conformance-app-backend", AppLabel: "app-backend-v1"},
+	{Namespace: "gateway-conformance-app-backend", AppLabel: "app-backend-v2"},
+	{Namespace: "gateway-conformance-web-backend", AppLabel: "web-backend"},
+}
+
+// ReadyCheck describes additional resources that Setup waits for, typically
+// resources shipped by custom base manifests.
+type ReadyCheck struct {
+	// Namespace in which all Gateways and Pods must be ready.
+	Namespace string
+	// Deployments optionally lists Deployments in Namespace that must have
+	// all of their replicas available.
+	Deployments []string
+}
+
 // ConformanceTestSuite defines the test suite used to run Gateway API
 // conformance tests.
 type ConformanceTestSuite struct {
 	Client            client.Client
+	APIReader         client.Reader
 	RoundTripper      roundtripper.RoundTripper
 	GatewayClassName  string
 	ControllerName    string
 	Debug             bool
 	Cleanup           bool
+	CleanupOnFailure  bool
+	WaitForCleanup    bool
 	BaseManifests     string
+	Namespaces        []string
+	Backends          []kubernetes.Backend
 	Applier           kubernetes.Applier
 	ExemptFeatures    []ExemptFeature
 	SupportedFeatures []SupportedFeature
+	// ConformanceProfiles lists the profiles claimed through Options, whose
+	// features are part of SupportedFeatures.
+	ConformanceProfiles []ConformanceProfileName
+	AutoDetectFeatures  bool
+	MinChannel          GatewayChannel
+	ExtraReadyChecks    []ReadyCheck
+	RunResources        []string
+	RunTest             string
+	SkipTests           []string
+	FailFast            bool
+	MaxParallel         int
+	Workers             int
+	Shuffle             bool
+	Seed                int64
+	DryRun              bool
+	AuditLeaks          bool
+	ReusableNamespace   string
+	SummaryOutput       io.Writer
+	ColorSummary        bool
+	SlowestTests        int
+	SlowTestThreshold   time.Duration
+	ReportOutput        string
+	TimeoutConfig       config.TimeoutConfig
+	RetryConfig         roundtripper.RetryConfig
+	BeforeEach          func(*testing.T, ConformanceTest)
+	AfterEach           func(*testing.T, ConformanceTest, error)
+
+	SkipBaseManifests bool
+	// ProvisionedGateway is the Gateway provisioned outside of the suite
+	// when SkipBaseManifests is set. Setup fills its Address in if empty.
+	ProvisionedGateway *ProvisionedGateway
+	AddressType        kubernetes.AddressFamily
+
+	// Metrics records the latency and status code of the round trips of
+	// the default RoundTripper when CollectMetrics is set. Custom
+	// RoundTrippers can record their round trips with it too.
+	Metrics *roundtripper.Metrics
+
+	// RerunFailedFrom is the path of the report whose failed tests are the
+	// only ones Run runs, if set.
+	RerunFailedFrom string
+
+	// ProfileReports holds a report for every conformance profile run
+	// through RunProfiles.
+	ProfileReports []ProfileReport
+
+	namespaces    testNamespaces
+	results       testResults
+	parallelSlots chan struct{}
+	rerunOutcomes map[string]TestOutcome
+	workers       chan *worker
+	workerList    []*worker
 }
 
 // Options can be used to initialize a ConformanceTestSuite.
 type Options struct {
-	Client           client.Client
+	Client client.Client
+	// RESTConfig, if set, is used to build the Client with NewClient when
+	// Client is nil. Client overrides it, e.g. for clients with a custom
+	// scheme.
+	RESTConfig *rest.Config
+	// APIReader, if set, is used instead of Client for the status reads
+	// readiness checks and tests poll on. Cached clients, such as the one
+	// of a controller-runtime manager, can lag status updates; when
+	// providing one as Client, set APIReader to an uncached reader, e.g.
+	// the one returned by the GetAPIReader method of the manager. Clients
+	// created with client.New read directly from the API server and can
+	// be used for both.
+	APIReader        client.Reader
 	GatewayClassName string
-	Debug            bool
-	RoundTripper     roundtripper.RoundTripper
-	BaseManifests    string
-	NamespaceLabels  map[string]string
+	// GatewayClassNames lists the GatewayClasses NewPerGatewayClass
+	// creates a suite for. It is ignored by New.
+	GatewayClassNames []string
+	Debug             bool
+	// RoundTripper makes the requests of tests, defaulting to a
+	// roundtripper.DefaultRoundTripper. A roundtripper.GRPCRoundTripper
+	// makes them as unary gRPC calls instead.
+	RoundTripper roundtripper.RoundTripper
+	// TLSConfig configures how the default RoundTripper negotiates TLS for
+	// https:// requests, e.g. with the PEM bundle of the CA that issued
+	// the serving certificates of Gateways. It is ignored if RoundTripper
+	// is set.
+	TLSConfig     *roundtripper.TLSConfig
+	BaseManifests string
+	// SkipBaseManifests makes Setup skip applying BaseManifests, e.g. where
+	// Gateways are provisioned outside of the suite. Setup then only waits
+	// for ProvisionedGateway, which must be set, to be ready. Test manifests
+	// are still applied, but tests with RequiresBaseManifests set fail.
+	SkipBaseManifests bool
+	// ProvisionedGateway is the Gateway the tests run against when
+	// SkipBaseManifests is set.
+	ProvisionedGateway *ProvisionedGateway
+	// AddressType forces the selection of IPv4 or IPv6 addresses of Gateways
+	// advertising both, including the addresses Hostname addresses resolve
+	// to. If IPv4 or IPv6, Setup also waits for every base Gateway to have
+	// such an address. PreferIPv6 selects IPv6 addresses where available. If
+	// empty, the first usable address is selected.
+	AddressType kubernetes.AddressFamily
+	// Namespaces lists the namespaces created by BaseManifests, whose
+	// Gateways and Pods Setup waits for. Defaults to DefaultNamespaces, the
+	// namespaces of the default base manifests; custom names require
+	// BaseManifests creating them. NamespaceLabels are set on every
+	// Namespace the manifests create, whatever its name.
+	Namespaces      []string
+	NamespaceLabels map[string]string
+	// Backends describes the backends deployed by BaseManifests. Setup
+	// waits for each of them to have ready Pods, and the responses of
+	// MustEventuallyGetResponse are attributed to them. Defaults to
+	// DefaultBackends unless Namespaces is set.
+	Backends []kubernetes.Backend
 	// ValidUniqueListenerPorts maps each listener port of each Gateway in the
 	// manifests to a valid, unique port. There must be as many
 	// ValidUniqueListenerPorts as there are listeners in the set of manifests.
 	// For example, given two Gateways, each with 2 listeners, there should be
-	// four ValidUniqueListenerPorts.
+	// four ValidUniqueListenerPorts. Applying manifests with more listeners
+	// than ports, or duplicate ports, fails before anything is applied.
 	// If empty or nil, ports are not modified.
 	ValidUniqueListenerPorts []v1alpha2.PortNumber
+	// ManifestSubstitutions, if not nil, makes the base and test manifests
+	// be rendered as text/template templates with these values before being
+	// applied, e.g. to customize the prefix of namespaces. The name of the
+	// GatewayClass under test is available as {{ .GatewayClassName }}.
+	// Manifests referencing undefined values fail to apply.
+	ManifestSubstitutions map[string]string
 
 	// CleanupBaseResources indicates whether or not the base test
 	// resources such as Gateways should be cleaned up after the run.
 	CleanupBaseResources bool
 	ExemptFeatures       []ExemptFeature
 	SupportedFeatures    []SupportedFeature
+
+	// CleanupOnFailure indicates whether or not the resources applied for a
+	// test are cleaned up when it fails. If false, they are kept in the
+	// cluster for inspection and their names are logged. If nil, defaults
+	// to true.
+	CleanupOnFailure *bool
+	// WaitForCleanup makes cleanup wait, up to the ResourcesMustBeDeleted
+	// timeout, for each deleted resource to be gone before deleting the
+	// next one, so that back-to-back runs don't trip over resources still
+	// being finalized. Resources still there afterwards are logged.
+	WaitForCleanup bool
+	// DiffApplied makes every applied resource be read back and logs how
+	// the object stored by the apiserver differs from the submitted one,
+	// which helps debugging defaults and mutating webhooks. It's implied by
+	// Debug.
+	DiffApplied bool
+
+	// ConformanceProfiles lists the names of the conformance profiles the
+	// implementation claims, e.g. "HTTP". The features of each profile are
+	// added to SupportedFeatures. New returns an error for unknown profiles.
+	ConformanceProfiles []ConformanceProfileName
+
+	// AutoDetectFeatures adds the features the GatewayClass publishes
+	// through its SupportedFeaturesAnnotation to SupportedFeatures during
+	// Setup and Verify.
+	AutoDetectFeatures bool
+
+	// MinChannel is the least stable release channel the suite runs tests
+	// for. Defaults to StandardChannel.
+	MinChannel GatewayChannel
+
+	// ExtraReadyChecks are appended to the readiness checks Setup performs
+	// for the base resources.
+	ExtraReadyChecks []ReadyCheck
+
+	// RunResources limits Run to tests that exercise at least one of the
+	// listed Gateway API resources, e.g. "HTTPRoute". If empty, all tests
+	// are run.
+	RunResources []string
+
+	// RunTest, if set, limits Run to the test with this ShortName. It may
+	// also be a glob pattern as supported by path.Match, e.g. "HTTPRoute*"
+	// to run every test whose ShortName starts with HTTPRoute.
+	RunTest string
+
+	// SkipTests lists the ShortNames of tests Run skips, taking precedence
+	// over any other reason to skip them. Entries may be glob patterns like
+	// RunTest. SkipTests is ignored if RunTest is set.
+	SkipTests []string
+
+	// FailFast stops Run from starting tests once a test failed. Parallel
+	// tests that already started are left to finish, the remaining tests
+	// are skipped and reported as such.
+	FailFast bool
+
+	// MaxParallel, if set, is how many Parallel tests may run at once. The
+	// tests still run in parallel as far as go test is concerned, but wait
+	// for a slot before applying their manifests, so the effective limit is
+	// the lowest of MaxParallel and the -parallel flag of go test. Serial
+	// tests don't take a slot.
+	MaxParallel int
+
+	// Workers, if greater than one, makes Run dispatch the Pooled tests to
+	// a pool of Workers, running them concurrently. Each worker has its own
+	// namespace, see WorkerNamespacePrefix, and an equal share of the
+	// ValidUniqueListenerPorts, so that the manifests of concurrent tests
+	// don't collide. The namespace of a worker is only used for resources
+	// without a namespace in the manifests of its tests: resources whose
+	// manifests set one are shared by every worker. None of the tests of
+	// this repository are Pooled, as their manifests set the namespaces of
+	// their resources, so Workers only benefits suites running their own
+	// tests. Run fails before running any test if
+	// the manifests of a Pooled test have more listeners than the share of
+	// a worker. Other tests run on their own, before the pool starts.
+	// Workers can't be combined with a ReusableNamespace.
+	Workers int
+
+	// Shuffle makes Run run tests in a random order, to surface tests
+	// depending on resources left behind by others. Parallel tests still
+	// run in parallel, only the order they start in changes.
+	Shuffle bool
+	// Seed is the seed of the order of shuffled tests, which Run logs so
+	// that it can be reproduced. If zero, a seed is picked at random.
+	Seed int64
+
+	// DryRun makes Setup and Verify return without touching the cluster,
+	// and Run log whether each test would run, or why it would be skipped,
+	// without running any. See Plan.
+	DryRun bool
+
+	// RerunFailedFrom, if set, is the path of the JSON report of a previous
+	// run, see ReportOutput. Run then only runs the tests that failed in
+	// that run, still skipping those the suite would skip anyway, and fails
+	// if the report has tests Run doesn't know about.
+	RerunFailedFrom string
+
+	// CollectMetrics makes the suite record the latency and status code of
+	// the requests of each test, which are summarized in its TestResult and
+	// report. Only the requests made with the context passed to the Test
+	// function, or one derived from it, count for the test. See
+	// ConformanceTestSuite.Metrics.
+	CollectMetrics bool
+
+	// AuditLeaks makes Run fail, once every test is done, for each Gateway
+	// API resource left behind in the base namespaces: resources deleted on
+	// cleanup that are still present, and resources the suite didn't apply.
+	AuditLeaks bool
+
+	// ReusableNamespace, if set, is created once and shared by every test
+	// that doesn't require isolation, while tests requiring isolation get a
+	// dedicated namespace. Resources without a namespace in test manifests
+	// are created in the namespace of the test, see TestNamespace.
+	ReusableNamespace string
+
+	// SummaryOutput, if set, is where Run prints a summary table of the
+	// executed tests once they are all done, see PrintSummary.
+	SummaryOutput io.Writer
+	// ColorSummary colorizes the outcomes in the summary table.
+	ColorSummary bool
+
+	// SlowestTests, if set, is how many of the slowest tests Run logs once
+	// all tests are done, see SlowestResults.
+	SlowestTests int
+	// SlowTestThreshold, if set, flags the tests in the slowest tests log
+	// that took longer than it in total without being marked Slow.
+	SlowTestThreshold time.Duration
+
+	// ReportOutput, if set, is the path of the file Run writes the JSON
+	// conformance report to once all tests are done, see Report.
+	ReportOutput string
+
+	// TimeoutConfig configures how long Setup and Verify wait for resources
+	// to become ready. Zero fields are set to their default, see
+	// config.TimeoutConfig.
+	TimeoutConfig config.TimeoutConfig
+
+	// RetryConfig configures how tests retry requests the Gateway responds
+	// to transiently with http.MakeRequestWithRetry, see
+	// roundtripper.RetryConfig.
+	RetryConfig roundtripper.RetryConfig
+
+	// BeforeEach, if set, is called for every test run by the suite before
+	// its manifests are applied, including tests the suite skips.
+	BeforeEach func(*testing.T, ConformanceTest)
+	// AfterEach, if set, is called for every test run by the suite once it
+	// is done, before its manifests are deleted. The
+	// error is set if the test failed; skipped tests can be told apart
+	// with t.Skipped. Hooks that panic fail the test.
+	AfterEach func(*testing.T, ConformanceTest, error)
 }
 
-// New returns a new ConformanceTestSuite.
-func New(s Options) *ConformanceTestSuite {
+// New returns a new ConformanceTestSuite. An error is returned if the options
+// are invalid.
+func New(s Options) (*ConformanceTestSuite, error) {
+	supportedFeatures, err := resolveProfiles(s.SupportedFeatures, s.ConformanceProfiles)
+	if err != nil {
+		return nil, err
+	}
+	supportedFeatures = expandFeatures(supportedFeatures)
+	if err := validateFeatures(supportedFeatures, s.ExemptFeatures); err != nil {
+		return nil, err
+	}
+	if s.MinChannel != 0 && !s.MinChannel.Valid() {
+		return nil, fmt.Errorf("invalid MinChannel %d, must be ExperimentalChannel (%d) or StandardChannel (%d)", int(s.MinChannel), int(ExperimentalChannel), int(StandardChannel))
+	}
+	if s.SkipBaseManifests && s.ProvisionedGateway == nil {
+		return nil, fmt.Errorf("SkipBaseManifests requires the ProvisionedGateway the tests run against")
+	}
+	if !s.AddressType.Valid() {
+		return nil, fmt.Errorf("invalid AddressType %q, must be %s, %s or %s", s.AddressType, kubernetes.IPv4AddressFamily, kubernetes.IPv6AddressFamily, kubernetes.PreferIPv6AddressFamily)
+	}
+	if s.MaxParallel < 0 {
+		return nil, fmt.Errorf("MaxParallel must not be negative, got %d", s.MaxParallel)
+	}
+	if s.Workers < 0 {
+		return nil, fmt.Errorf("Workers must not be negative, got %d", s.Workers)
+	}
+	if s.Workers > 1 && s.ReusableNamespace != "" {
+		return nil, errors.New("Workers can't be combined with a ReusableNamespace")
+	}
+
+	var metrics *roundtripper.Metrics
+	if s.CollectMetrics {
+		metrics = &roundtripper.Metrics{}
+	}
+	timeoutConfig := s.TimeoutConfig
+	config.SetupTimeoutConfig(&timeoutConfig)
+
 	roundTripper := s.RoundTripper
 	if roundTripper == nil {
-		roundTripper = &roundtripper.DefaultRoundTripper{Debug: s.Debug}
+		roundTripper = &roundtripper.DefaultRoundTripper{Debug: s.Debug, TLSConfig: s.TLSConfig, Metrics: metrics, TimeoutConfig: timeoutConfig}
 	}
 
-	MinChannel := s.MinChannel
-	if MinChannel == 0 {
-		MinChannel = StandardChannel
+	minChannel := s.MinChannel
+	if minChannel == 0 {
+		minChannel = StandardChannel
+	}
+
+	namespaces, backends := s.Namespaces, s.Backends
+	if len(namespaces) == 0 {
+		namespaces = DefaultNamespaces
+		if len(backends) == 0 {
+			backends = DefaultBackends
+		}
+	}
+
+	cleanupOnFailure := true
+	if s.CleanupOnFailure != nil {
+		cleanupOnFailure = *s.CleanupOnFailure
+	}
+
+	c, err := optionsClient(s)
+	if err != nil {
+		return nil, err
+	}
+	apiReader := s.APIReader
+	if apiReader == nil {
+		apiReader = c
 	}
 
 	suite := &ConformanceTestSuite{
-		Client:           s.Client,
-		RoundTripper:     roundTripper,
-		GatewayClassName: s.GatewayClassName,
-		Debug:            s.Debug,
-		Cleanup:          s.CleanupBaseResources,
-		BaseManifests:    s.BaseManifests,
+		Client:            c,
+		APIReader:         apiReader,
+		RoundTripper:      roundTripper,
+		GatewayClassName:  s.GatewayClassName,
+		Debug:             s.Debug,
+		Cleanup:           s.CleanupBaseResources,
+		CleanupOnFailure:  cleanupOnFailure,
+		WaitForCleanup:    s.WaitForCleanup,
+		BaseManifests:     s.BaseManifests,
+		SkipBaseManifests: s.SkipBaseManifests,
+		AddressType:       s.AddressType,
+		Metrics:           metrics,
+		RerunFailedFrom:   s.RerunFailedFrom,
+		Namespaces:        namespaces,
+		Backends:          backends,
 		Applier: kubernetes.Applier{
 			NamespaceLabels:          s.NamespaceLabels,
 			ValidUniqueListenerPorts: s.ValidUniqueListenerPorts,
+			RetryBudget:              timeoutConfig.ManifestsMustBeApplied,
+			Substitutions:            s.ManifestSubstitutions,
+			DiffApplied:              s.DiffApplied || s.Debug,
 		},
-		ExemptFeatures:    s.ExemptFeatures,
-		SupportedFeatures: s.SupportedFeatures,
-		MinChannel:        s.MinChannel,
+		ExemptFeatures:      s.ExemptFeatures,
+		SupportedFeatures:   supportedFeatures,
+		ConformanceProfiles: s.ConformanceProfiles,
+		AutoDetectFeatures:  s.AutoDetectFeatures,
+		MinChannel:          minChannel,
+		ExtraReadyChecks:    s.ExtraReadyChecks,
+		RunResources:        s.RunResources,
+		RunTest:             s.RunTest,
+		SkipTests:           s.SkipTests,
+		FailFast:            s.FailFast,
+		MaxParallel:         s.MaxParallel,
+		Workers:             s.Workers,
+		Shuffle:             s.Shuffle,
+		Seed:                s.Seed,
+		DryRun:              s.DryRun,
+		AuditLeaks:          s.AuditLeaks,
+		ReusableNamespace:   s.ReusableNamespace,
+		SummaryOutput:       s.SummaryOutput,
+		ColorSummary:        s.ColorSummary,
+		SlowestTests:        s.SlowestTests,
+		SlowTestThreshold:   s.SlowTestThreshold,
+		ReportOutput:        s.ReportOutput,
+		TimeoutConfig:       timeoutConfig,
+		RetryConfig:         s.RetryConfig,
+		BeforeEach:          s.BeforeEach,
+		AfterEach:           s.AfterEach,
 	}
 
 	// apply defaults
 	if suite.BaseManifests == "" {
 		suite.BaseManifests = "base/manifests.yaml"
 	}
+	if s.ProvisionedGateway != nil {
+		// Setup fills the address in, which mustn't leak into the options.
+		provisioned := *s.ProvisionedGateway
+		suite.ProvisionedGateway = &provisioned
+	}
+	if suite.AuditLeaks {
+		suite.Applier.Tracker = &kubernetes.ResourceTracker{}
+	}
+	if suite.WaitForCleanup {
+		suite.Applier.DeletionTimeout = timeoutConfig.ResourcesMustBeDeleted
+	}
+	if suite.RerunFailedFrom != "" {
+		suite.rerunOutcomes, err = loadRerunOutcomes(suite.RerunFailedFrom)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if suite.MaxParallel > 0 {
+		suite.parallelSlots = make(chan struct{}, suite.MaxParallel)
+	}
+	if suite.Workers > 1 {
+		suite.workerList, err = newWorkers(suite.Workers, suite.Applier.ValidUniqueListenerPorts)
+		if err != nil {
+			return nil, err
+		}
+		suite.workers = make(chan *worker, len(suite.workerList))
+		for _, w := range suite.workerList {
+			suite.workers <- w
+		}
+	}
+	if suite.Shuffle && suite.Seed == 0 {
+		suite.Seed = time.Now().UnixNano()
+	}
+
+	return suite, nil
+}
 
-	return suite
+// timeouts returns the TimeoutConfig of the suite with its unset timeouts
+// defaulted, for suites that weren't created by New.
+func (suite *ConformanceTestSuite) timeouts() config.TimeoutConfig {
+	timeoutConfig := suite.TimeoutConfig
+	config.SetupTimeoutConfig(&timeoutConfig)
+	return timeoutConfig
 }
 
-// Setup ensures the base resources required for conformance tests are installed
-// in the cluster. It also ensures that all relevant resources are ready.
+// Setup is SetupWithContext with a background context.
+//
+// Deprecated: use SetupWithContext.
 func (suite *ConformanceTestSuite) Setup(t *testing.T) {
+	suite.SetupWithContext(context.Background(), t)
+}
+
+// SetupWithContext ensures the base resources required for conformance tests
+// are installed in the cluster. It also ensures that all relevant resources
+// are ready. Cancelling ctx aborts applying and waiting for resources.
+func (suite *ConformanceTestSuite) SetupWithContext(ctx context.Context, t *testing.T) {
+	if suite.DryRun {
+		t.Logf("Test Setup: Skipped in dry-run mode")
+		return
+	}
+
 	t.Logf("Test Setup: Ensuring GatewayClass has been accepted")
-	suite.ControllerName = kubernetes.GWCMustBeAccepted(t, suite.Client, suite.GatewayClassName, 180)
+	suite.ControllerName = kubernetes.GWCMustBeAcceptedWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.GatewayClassName)
+	suite.detectFeatures(ctx, t)
+
+	if suite.SkipBaseManifests {
+		t.Logf("Test Setup: Skipping base manifests, ensuring %s Gateway is ready", suite.ProvisionedGateway.NamespacedName())
+		suite.ensureProvisionedGatewayReady(ctx, t)
+		return
+	}
 
 	t.Logf("Test Setup: Applying base manifests")
-	suite.Applier.MustApplyWithCleanup(t, suite.Client, suite.BaseManifests, suite.GatewayClassName, suite.Cleanup)
+	suite.Applier.MustApplyWithCleanupWithContext(ctx, t, suite.Client, suite.BaseManifests, suite.GatewayClassName, suite.Cleanup)
 
 	t.Logf("Test Setup: Ensuring Gateways and Pods from base manifests are ready")
-	namespaces := []string{
-		"gateway-conformance-infra",
-		"gateway-conformance-app-backend",
-		"gateway-conformance-web-backend",
+	suite.ensureReady(ctx, t)
+}
+
+// ensureReady waits for the Gateways and Pods of the base namespaces, of the
+// backends and of every extra ReadyCheck to be ready.
+func (suite *ConformanceTestSuite) ensureReady(ctx context.Context, t *testing.T) {
+	namespaces := append([]string{}, suite.Namespaces...)
+	for _, backend := range suite.Backends {
+		if !slices.Contains(namespaces, backend.Namespace) {
+			namespaces = append(namespaces, backend.Namespace)
+		}
+	}
+	for _, check := range suite.ExtraReadyChecks {
+		if !slices.Contains(namespaces, check.Namespace) {
+			namespaces = append(namespaces, check.Namespace)
+		}
 	}
-	kubernetes.NamespacesMustBeReady(t, suite.Client, namespaces, 300)
+	kubernetes.NamespacesMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), namespaces)
+	if len(suite.Backends) > 0 {
+		kubernetes.BackendsMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.Backends)
+	}
+	if suite.AddressType == kubernetes.IPv4AddressFamily || suite.AddressType == kubernetes.IPv6AddressFamily {
+		kubernetes.GatewaysMustHaveAddressWithContext(ctx, t, suite.APIReader, suite.timeouts(), namespaces, suite.AddressType)
+	}
+
+	for _, check := range suite.ExtraReadyChecks {
+		if len(check.Deployments) > 0 {
+			kubernetes.DeploymentsMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), check.Namespace, check.Deployments)
+		}
+	}
+}
+
+// GatewayAndHTTPRoutesMustBeReady is
+// GatewayAndHTTPRoutesMustBeReadyWithContext with a background context.
+//
+// Deprecated: use GatewayAndHTTPRoutesMustBeReadyWithContext.
+func (suite *ConformanceTestSuite) GatewayAndHTTPRoutesMustBeReady(t *testing.T, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
+	t.Helper()
+	return suite.GatewayAndHTTPRoutesMustBeReadyWithContext(context.Background(), t, gwNN, routeNNs...)
+}
+
+// GatewayAndHTTPRoutesMustBeReadyWithContext waits until the Gateway has an
+// address of the AddressType of the suite and the routes are accepted by it,
+// returning the address requests are sent to. Waiting stops once ctx is
+// cancelled, e.g. when the test times out.
+func (suite *ConformanceTestSuite) GatewayAndHTTPRoutesMustBeReadyWithContext(ctx context.Context, t *testing.T, gwNN types.NamespacedName, routeNNs ...types.NamespacedName) string {
+	t.Helper()
+	return kubernetes.GatewayAndHTTPRoutesMustBeReadyWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.ControllerName, suite.AddressType, gwNN, routeNNs...)
 }
 
-// Run runs the provided set of conformance tests.
+// Run is RunWithContext with a background context.
+//
+// Deprecated: use RunWithContext.
 func (suite *ConformanceTestSuite) Run(t *testing.T, tests []ConformanceTest) {
-	for _, test := range tests {
+	suite.RunWithContext(context.Background(), t, tests)
+}
+
+// RunWithContext runs the provided set of conformance tests. If RunResources
+// is set, only the tests exercising one of those resources are run. The
+// context passed to the Test functions is derived from ctx.
+func (suite *ConformanceTestSuite) RunWithContext(ctx context.Context, t *testing.T, tests []ConformanceTest) {
+	suite.run(ctx, t, tests, false)
+}
+
+// Verify is VerifyWithContext with a background context.
+//
+// Deprecated: use VerifyWithContext.
+func (suite *ConformanceTestSuite) Verify(t *testing.T, tests []ConformanceTest) {
+	suite.VerifyWithContext(context.Background(), t, tests)
+}
+
+// VerifyWithContext checks that the resources of a previous run are still healthy
+// without modifying anything in the cluster: the GatewayClass and the base
+// resources are only checked for readiness, and the provided tests are run
+// against the existing resources without applying or cleaning up their
+// manifests. Tests requiring isolation are skipped, as they need a dedicated
+// namespace to be created.
+func (suite *ConformanceTestSuite) VerifyWithContext(ctx context.Context, t *testing.T, tests []ConformanceTest) {
+	if suite.DryRun {
+		suite.logPlan(t, suite.plan(tests, true))
+		return
+	}
+
+	t.Logf("Verify: Ensuring GatewayClass has been accepted")
+	suite.ControllerName = kubernetes.GWCMustBeAcceptedWithContext(ctx, t, suite.APIReader, suite.timeouts(), suite.GatewayClassName)
+	suite.detectFeatures(ctx, t)
+
+	if suite.SkipBaseManifests {
+		t.Logf("Verify: Ensuring %s Gateway is ready", suite.ProvisionedGateway.NamespacedName())
+		suite.ensureProvisionedGatewayReady(ctx, t)
+	} else {
+		t.Logf("Verify: Ensuring Gateways and Pods from base manifests are ready")
+		suite.ensureReady(ctx, t)
+	}
+
+	suite.run(ctx, t, tests, true)
+}
+
+func (suite *ConformanceTestSuite) run(ctx context.Context, t *testing.T, tests []ConformanceTest, verify bool) {
+	if err := suite.validateRerun(tests); err != nil {
+		t.Fatal(err)
+	}
+
+	if suite.Shuffle {
+		t.Logf("Shuffling tests with seed %d", suite.Seed)
+		tests = shuffleTests(tests, suite.Seed)
+	}
+
+	if suite.DryRun {
+		suite.logPlan(t, suite.plan(tests, verify))
+		return
+	}
+
+	if suite.workers != nil {
+		if err := suite.checkWorkerPorts(ctx, tests); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if suite.SummaryOutput != nil {
+		// Cleanup runs once all subtests, including parallel ones, are done.
+		t.Cleanup(func() {
+			suite.PrintSummary(suite.SummaryOutput)
+		})
+	}
+	if suite.SlowestTests > 0 {
+		t.Cleanup(func() {
+			suite.logSlowestTests(t)
+		})
+	}
+	if suite.ReportOutput != "" {
+		t.Cleanup(func() {
+			if err := suite.writeReport(suite.ReportOutput); err != nil {
+				t.Errorf("error writing conformance report to %s: %v", suite.ReportOutput, err)
+			}
+		})
+	}
+	if suite.AuditLeaks {
+		t.Cleanup(func() {
+			suite.auditLeaks(t)
+		})
+	}
+	if suite.workers != nil {
+		t.Cleanup(func() {
+			suite.deleteWorkerNamespaces(t)
+		})
+	}
+
+	for i := range tests {
+		test := tests[i]
+		if !suite.runsResources(test) || !suite.runsTest(test) {
+			continue
+		}
 		t.Run(test.ShortName, func(t *testing.T) {
-			test.Run(t, suite)
+			suite.trackResult(t, test)
+			test.run(ctx, t, suite, verify)
 		})
 	}
 }
 
+// runsResources returns true if the test exercises one of the resources in
+// RunResources, or if RunResources is empty.
+func (suite *ConformanceTestSuite) runsResources(test ConformanceTest) bool {
+	if len(suite.RunResources) == 0 {
+		return true
+	}
+	for _, resource := range test.Resources {
+		if slices.Contains(suite.RunResources, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// runsTest returns true if RunTest is empty or matches the test.
+func (suite *ConformanceTestSuite) runsTest(test ConformanceTest) bool {
+	return suite.RunTest == "" || testNameMatches(suite.RunTest, test.ShortName)
+}
+
+// skipsTest returns true if RunTest is empty and SkipTests matches the test.
+func (suite *ConformanceTestSuite) skipsTest(test *ConformanceTest) bool {
+	if suite.RunTest != "" {
+		return false
+	}
+	for _, pattern := range suite.SkipTests {
+		if testNameMatches(pattern, test.ShortName) {
+			return true
+		}
+	}
+	return false
+}
+
+// testNameMatches returns true if the ShortName of a test is the provided
+// pattern or matches it as a glob pattern. Malformed patterns only match
+// exactly.
+func testNameMatches(pattern, shortName string) bool {
+	if pattern == shortName {
+		return true
+	}
+	matched, err := path.Match(pattern, shortName)
+	return err == nil && matched
+}
+
 // ConformanceTest is used to define each individual conformance test.
 type ConformanceTest struct {
 	ShortName   string
 	Description string
 	Exemptions  []ExemptFeature
 	Features    []SupportedFeature
-	Manifests   []string
-	Slow        bool
-	Parallel    bool
-	Test        func(*testing.T, *ConformanceTestSuite)
-	MinChannel  GatewayChannel
+	// Resources lists the kinds of the Gateway API resources the test
+	// exercises, e.g. "Gateway" or "HTTPRoute".
+	Resources []string
+	Manifests []string
+	Slow      bool
+	Parallel  bool
+	// Exclusive indicates the test must not run concurrently with other
+	// tests, e.g. because it modifies resources of the base manifests. It
+	// isn't dispatched to the Workers of the suite, nor run in parallel.
+	Exclusive bool
+	// Pooled indicates the test can be dispatched to the Workers of the
+	// suite, i.e. its manifests don't set the namespace of their resources
+	// and it doesn't modify resources other tests use.
+	Pooled bool
+	// RequiresIsolation indicates the test must not share its namespace
+	// with other tests when a ReusableNamespace is configured.
+	RequiresIsolation bool
+	// RequiresBaseManifests indicates the test relies on resources of the
+	// base manifests, such as their Gateways or backends. It fails when the
+	// suite has SkipBaseManifests set.
+	RequiresBaseManifests bool
+	// Timeout bounds how long the Test function may run for, defaulting to
+	// the TestTimeout of the TimeoutConfig of the suite. The context passed
+	// to the Test function is cancelled once it's exceeded.
+	Timeout time.Duration
+	// Test runs the test. It must not start parallel subtests: those only
+	// run once it returned, after its context is cancelled and outside of
+	// its Timeout.
+	Test       func(context.Context, *testing.T, *ConformanceTestSuite)
+	MinChannel GatewayChannel
 }
 
-// Run runs an individual tests, applying and cleaning up the required manifests
-// before calling the Test function.
+// Run is RunWithContext with a background context.
+//
+// Deprecated: use RunWithContext.
 func (test *ConformanceTest) Run(t *testing.T, suite *ConformanceTestSuite) {
+	test.RunWithContext(context.Background(), t, suite)
+}
+
+// RunWithContext runs an individual tests, applying and cleaning up the
+// required manifests before calling the Test function with a context derived
+// from ctx.
+func (test *ConformanceTest) RunWithContext(ctx context.Context, t *testing.T, suite *ConformanceTestSuite) {
+	test.run(ctx, t, suite, false)
+}
 
-	if test.Parallel {
+// run runs the test, only applying its manifests unless verifying existing
+// resources.
+func (test *ConformanceTest) run(ctx context.Context, t *testing.T, suite *ConformanceTestSuite, verify bool) {
+	pooled := suite.pooled(test)
+	if (test.Parallel && !test.Exclusive) || pooled {
 		t.Parallel()
 	}
+	suite.recordStart(t)
+
+	defer suite.afterEach(t, *test)
+	suite.beforeEach(t, *test)
+
+	if category, reason := suite.skipReason(test, verify); category != "" {
+		suite.recordSkip(t, category, reason)
+		skipTest(t, test, reason)
+	}
+
+	if test.RequiresBaseManifests && suite.SkipBaseManifests {
+		t.Fatalf("%s requires the base manifests, which weren't applied since SkipBaseManifests is set", test.ShortName)
+	}
+
+	if test.Parallel && !test.Exclusive {
+		defer suite.acquireParallelSlot(ctx, t)()
+	}
+	var w *worker
+	if pooled {
+		var release func()
+		w, release = suite.acquireWorker(ctx, t)
+		defer release()
+	}
+
+	if verify {
+		suite.recordNamespace(t, suite.ReusableNamespace)
+		suite.runTimed(ctx, t, test)
+		return
+	}
+
+	applier := suite.Applier
+	applier.RetainOnFailure = !suite.CleanupOnFailure
+	if ns := suite.allocateNamespace(ctx, t, test); ns != "" {
+		applier.Namespace = ns
+	}
+	if w != nil {
+		suite.ensureWorkerNamespace(ctx, t, w)
+		suite.recordNamespace(t, w.namespace)
+		applier.Namespace = w.namespace
+		if len(w.ports) > 0 {
+			applier.ValidUniqueListenerPorts = w.ports
+		}
+	}
+
+	for _, manifestLocation := range test.Manifests {
+		t.Logf("Applying %s", manifestLocation)
+		applier.MustApplyWithCleanupWithContext(ctx, t, suite.Client, manifestLocation, suite.GatewayClassName, true)
+	}
+
+	suite.runTimed(ctx, t, test)
+}
+
+// skipReason returns the category and the reason of the suite skipping the
+// test, or an empty category if the test runs.
+func (suite *ConformanceTestSuite) skipReason(test *ConformanceTest, verify bool) (SkipCategory, string) {
+	// Explicit skips take precedence so that they are reported as such.
+	if suite.skipsTest(test) {
+		return SkipExplicit, "skipped by SkipTests"
+	}
+
+	if reason := suite.rerunSkipReason(test); reason != "" {
+		return SkipRerun, reason
+	}
 
 	// Check that all features excerised by the test have been opted into by
 	// the suite.
 	for _, feature := range test.Features {
-		if !slices.Contains(suite.SupportedFeatures, feature) {
-			t.Skip("Skipping %s: suite does not support %s", test.ShortName, feature)
+		if !hasFeature(suite.SupportedFeatures, feature) {
+			return SkipUnsupportedFeature, fmt.Sprintf("suite does not support %s", feature)
 		}
 	}
 
 	// Check that no features excerised by the test have been opted out of by
 	// the suite.
 	for _, feature := range test.Exemptions {
-		if !slices.Contains(suite.ExemptFeatures, feature) {
-			t.Skip("Skipping %s: suite exempts %s", test.ShortName, feature)
+		if hasExemption(suite.ExemptFeatures, feature) {
+			return SkipExemptFeature, fmt.Sprintf("suite exempts %s", feature)
 		}
 	}
 
-	if test.MinChannel < suite.MinChannel {
-		t.Skipf("Skipping %s: only testing %s channel", test.ShortName, suite.MinChannel)
+	if !suite.ShouldRun(test.MinChannel) {
+		return SkipChannel, fmt.Sprintf("only testing %s channel", suite.MinChannel)
 	}
 
-	for _, manifestLocation := range test.Manifests {
-		t.Logf("Applying %s", manifestLocation)
-		suite.Applier.MustApplyWithCleanup(t, suite.Client, manifestLocation, suite.GatewayClassName, true)
+	if verify && test.RequiresIsolation && suite.ReusableNamespace != "" {
+		return SkipVerify, "isolated namespaces can't be created when verifying"
 	}
 
-	test.Test(t, suite)
+	// Checked last so that tests the suite wouldn't run anyway are reported
+	// with their actual reason.
+	if suite.FailFast && suite.anyFailed() {
+		return SkipFailFast, "a previous test failed"
+	}
+	return "", ""
+}
+
+// skipper is the subset of *testing.T used to skip tests.
+type skipper interface {
+	Skipf(format string, args ...interface{})
+}
+
+// skipTest skips the test with a message giving the reason.
+func skipTest(t skipper, test *ConformanceTest, reason string) {
+	t.Skipf("Skipping %s: %s", test.ShortName, reason)
 }
@@ -0,0 +1,1056 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/config"
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+	"sigs.k8s.io/gateway-api/conformance/utils/roundtripper"
+)
+
+// recordingClient wraps a client and records the namespaces it lists objects
+// in, the keys of the objects it gets and the number of writes.
+type recordingClient struct {
+	client.Client
+
+	mu         sync.Mutex
+	listed     map[string]bool
+	fetchedKey map[client.ObjectKey]bool
+	writes     int
+}
+
+// newRecordingClient returns a recording client populated with the provided
+// objects, along with a ready Pod for each of the DefaultBackends.
+func newRecordingClient(t *testing.T, objs ...client.Object) *recordingClient {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha2.AddToScheme(scheme))
+
+	objs = append(readyBackendPods(DefaultBackends), objs...)
+	return &recordingClient{
+		Client:     fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		listed:     map[string]bool{},
+		fetchedKey: map[client.ObjectKey]bool{},
+	}
+}
+
+func (c *recordingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	c.mu.Lock()
+	c.fetchedKey[key] = true
+	c.mu.Unlock()
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *recordingClient) recordWrite() {
+	c.mu.Lock()
+	c.writes++
+	c.mu.Unlock()
+}
+
+func (c *recordingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.recordWrite()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *recordingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.recordWrite()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *recordingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.recordWrite()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *recordingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.recordWrite()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *recordingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	c.mu.Lock()
+	c.listed[listOpts.Namespace] = true
+	c.mu.Unlock()
+	return c.Client.List(ctx, list, opts...)
+}
+
+// mustNew returns a new suite, failing the test if the options are invalid.
+func mustNew(t *testing.T, options Options) *ConformanceTestSuite {
+	t.Helper()
+	cSuite, err := New(options)
+	require.NoError(t, err)
+	return cSuite
+}
+
+// readyBackendPods returns a ready Pod for each of the backends.
+func readyBackendPods(backends []kubernetes.Backend) []client.Object {
+	pods := make([]client.Object, 0, len(backends))
+	for _, backend := range backends {
+		pods = append(pods, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: backend.AppLabel + "-7f9c8d6b5-x2x9z", Namespace: backend.Namespace, Labels: map[string]string{"app": backend.AppLabel}},
+			Status:     v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}},
+		})
+	}
+	return pods
+}
+
+func TestEnsureReadyExtraReadyChecks(t *testing.T) {
+	replicas := int32(1)
+	c := newRecordingClient(t, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "extra-backend", Namespace: "extra"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	})
+
+	cSuite := mustNew(t, Options{
+		Client: c,
+		ExtraReadyChecks: []ReadyCheck{{
+			Namespace:   "extra",
+			Deployments: []string{"extra-backend"},
+		}},
+	})
+	cSuite.ensureReady(context.Background(), t)
+
+	for _, ns := range []string{"gateway-conformance-infra", "gateway-conformance-app-backend", "gateway-conformance-web-backend", "extra"} {
+		require.Truef(t, c.listed[ns], "expected readiness of %s namespace to be checked", ns)
+	}
+	require.True(t, c.fetchedKey[client.ObjectKey{Namespace: "extra", Name: "extra-backend"}], "expected extra-backend Deployment to be checked")
+}
+
+func TestEnsureReadyCustomNamespaces(t *testing.T) {
+	c := newRecordingClient(t)
+
+	cSuite := mustNew(t, Options{
+		Client:     c,
+		Namespaces: []string{"team-a-infra", "team-a-backend"},
+	})
+	cSuite.ensureReady(context.Background(), t)
+
+	require.True(t, c.listed["team-a-infra"], "expected readiness of team-a-infra namespace to be checked")
+	require.True(t, c.listed["team-a-backend"], "expected readiness of team-a-backend namespace to be checked")
+	for _, ns := range DefaultNamespaces {
+		require.Falsef(t, c.listed[ns], "expected readiness of default %s namespace not to be checked", ns)
+	}
+	require.Empty(t, cSuite.Backends, "expected default backends not to be waited for in custom namespaces")
+}
+
+func TestEnsureReadyBackends(t *testing.T) {
+	require.Equal(t, DefaultBackends, mustNew(t, Options{}).Backends)
+
+	split := kubernetes.Backend{Namespace: "gateway-conformance-split", AppLabel: "split-backend-v3"}
+	backends := append(append([]kubernetes.Backend{}, DefaultBackends...), split)
+	c := newRecordingClient(t, readyBackendPods([]kubernetes.Backend{split})...)
+
+	cSuite := mustNew(t, Options{Client: c, Backends: backends})
+	cSuite.ensureReady(context.Background(), t)
+
+	require.True(t, c.listed["gateway-conformance-split"], "expected readiness of the namespace of the extra backend to be checked")
+}
+
+func TestRunResources(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	newTest := func(name string, resources ...string) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			Resources:  resources,
+			MinChannel: StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+			},
+		}
+	}
+
+	tests := []ConformanceTest{
+		newTest("HTTPRoute", "HTTPRoute"),
+		newTest("GRPCRoute", "GRPCRoute"),
+		newTest("GRPCRouteAndGateway", "Gateway", "GRPCRoute"),
+		newTest("Untagged"),
+	}
+
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, RunResources: []string{"GRPCRoute"}})
+	cSuite.Run(t, tests)
+
+	require.ElementsMatch(t, []string{"GRPCRoute", "GRPCRouteAndGateway"}, ran)
+}
+
+func TestRunTestAndSkipTests(t *testing.T) {
+	testCases := []struct {
+		name      string
+		runTest   string
+		skipTests []string
+		executed  []string
+		skipped   map[string]SkipCategory
+	}{{
+		name:     "exact RunTest",
+		runTest:  "HTTPRouteMatching",
+		executed: []string{"HTTPRouteMatching"},
+	}, {
+		name:     "RunTest pattern",
+		runTest:  "HTTPRoute*",
+		executed: []string{"HTTPRouteMatching", "HTTPRouteReferencePolicy"},
+		skipped:  map[string]SkipCategory{"HTTPRouteReferencePolicy": SkipUnsupportedFeature},
+	}, {
+		name:      "SkipTests ignored with RunTest",
+		runTest:   "HTTPRouteMatching",
+		skipTests: []string{"HTTPRouteMatching"},
+		executed:  []string{"HTTPRouteMatching"},
+	}, {
+		name:      "SkipTests",
+		skipTests: []string{"GatewayAddress", "HTTPRoute*"},
+		executed:  []string{"GatewayAddress", "HTTPRouteMatching", "HTTPRouteReferencePolicy", "TLSRouteSimple"},
+		skipped: map[string]SkipCategory{
+			"GatewayAddress":           SkipExplicit,
+			"HTTPRouteMatching":        SkipExplicit,
+			"HTTPRouteReferencePolicy": SkipExplicit,
+		},
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			newTest := func(name string, features ...SupportedFeature) ConformanceTest {
+				return ConformanceTest{
+					ShortName:  name,
+					Features:   features,
+					MinChannel: StandardChannel,
+					Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+				}
+			}
+			tests := []ConformanceTest{
+				newTest("GatewayAddress"),
+				newTest("HTTPRouteMatching"),
+				newTest("HTTPRouteReferencePolicy", SupportReferencePolicy),
+				newTest("TLSRouteSimple"),
+			}
+
+			cSuite := mustNew(t, Options{MinChannel: StandardChannel, RunTest: tc.runTest, SkipTests: tc.skipTests})
+			t.Run("run", func(t *testing.T) {
+				cSuite.Run(t, tests)
+			})
+
+			var executed []string
+			skipped := map[string]SkipCategory{}
+			for _, result := range cSuite.results.results {
+				executed = append(executed, result.ShortName)
+				if result.Outcome == TestSkipped {
+					skipped[result.ShortName] = result.SkipCategory
+				}
+			}
+			require.ElementsMatch(t, tc.executed, executed)
+			if tc.skipped == nil {
+				tc.skipped = map[string]SkipCategory{}
+			}
+			require.Equal(t, tc.skipped, skipped, "expected explicit skips to take precedence")
+		})
+	}
+}
+
+func TestFeaturesAndExemptions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		supportedFeatures []SupportedFeature
+		exemptFeatures    []ExemptFeature
+		outcome           TestOutcome
+		skipCategory      SkipCategory
+	}{{
+		name:              "feature supported and not exempted",
+		supportedFeatures: []SupportedFeature{SupportTCPRoute},
+		outcome:           TestPassed,
+	}, {
+		name:              "feature exempted by suite",
+		supportedFeatures: []SupportedFeature{SupportTCPRoute},
+		exemptFeatures:    []ExemptFeature{ExemptReferencePolicy},
+		outcome:           TestSkipped,
+		skipCategory:      SkipExemptFeature,
+	}, {
+		name:         "feature neither supported nor exempted",
+		outcome:      TestSkipped,
+		skipCategory: SkipUnsupportedFeature,
+	}}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ran := false
+			cSuite := mustNew(t, Options{
+				MinChannel:        StandardChannel,
+				SupportedFeatures: tc.supportedFeatures,
+				ExemptFeatures:    tc.exemptFeatures,
+			})
+			t.Run("run", func(t *testing.T) {
+				cSuite.Run(t, []ConformanceTest{{
+					ShortName:  "TCPRouteReferencePolicy",
+					Features:   []SupportedFeature{SupportTCPRoute},
+					Exemptions: []ExemptFeature{ExemptReferencePolicy},
+					MinChannel: StandardChannel,
+					Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+						ran = true
+					},
+				}})
+			})
+
+			require.Len(t, cSuite.results.results, 1)
+			result := cSuite.results.results[0]
+			require.Equal(t, tc.outcome, result.Outcome)
+			require.Equal(t, tc.skipCategory, result.SkipCategory)
+			require.Equal(t, tc.outcome == TestPassed, ran)
+		})
+	}
+}
+
+// skipRecorder records the message tests are skipped with.
+type skipRecorder struct {
+	message string
+}
+
+func (r *skipRecorder) Skipf(format string, args ...interface{}) {
+	r.message = fmt.Sprintf(format, args...)
+}
+
+func TestSkipMessages(t *testing.T) {
+	test := &ConformanceTest{
+		ShortName:  "TCPRouteReferencePolicy",
+		Features:   []SupportedFeature{SupportTCPRoute},
+		Exemptions: []ExemptFeature{ExemptReferencePolicy},
+		MinChannel: StandardChannel,
+	}
+	testCases := []struct {
+		name     string
+		options  Options
+		expected string
+	}{{
+		name:     "unsupported feature",
+		options:  Options{MinChannel: StandardChannel},
+		expected: "Skipping TCPRouteReferencePolicy: suite does not support TCPRoute",
+	}, {
+		name: "exempt feature",
+		options: Options{
+			MinChannel:        StandardChannel,
+			SupportedFeatures: []SupportedFeature{SupportTCPRoute},
+			ExemptFeatures:    []ExemptFeature{ExemptReferencePolicy},
+		},
+		expected: "Skipping TCPRouteReferencePolicy: suite exempts ReferencePolicy",
+	}}
+
+	for _, tc := range testCases {
+		cSuite := mustNew(t, tc.options)
+		category, reason := cSuite.skipReason(test, false)
+		require.NotEmptyf(t, category, "expected %s to be skipped", tc.name)
+
+		recorder := &skipRecorder{}
+		skipTest(recorder, test, reason)
+		require.Equal(t, tc.expected, recorder.message)
+		require.NotContains(t, recorder.message, "%")
+	}
+}
+
+func TestReusableNamespace(t *testing.T) {
+	// A leftover namespace from a previous run must not be reused by an
+	// isolated test.
+	c := newRecordingClient(t, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared-isolated-0"}})
+
+	var mu sync.Mutex
+	namespaces := map[string]string{}
+	newTest := func(name string, isolated bool) ConformanceTest {
+		return ConformanceTest{
+			ShortName:         name,
+			MinChannel:        StandardChannel,
+			RequiresIsolation: isolated,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				mu.Lock()
+				defer mu.Unlock()
+				namespaces[name] = s.TestNamespace(t)
+			},
+		}
+	}
+
+	cSuite := mustNew(t, Options{Client: c, MinChannel: StandardChannel, ReusableNamespace: "shared"})
+	cSuite.Run(t, []ConformanceTest{
+		newTest("SharedA", false),
+		newTest("SharedB", false),
+		newTest("IsolatedA", true),
+		newTest("IsolatedB", true),
+	})
+
+	require.Equal(t, "shared", namespaces["SharedA"])
+	require.Equal(t, "shared", namespaces["SharedB"])
+	// Tests run sequentially, so the name freed by the first isolated test
+	// is available again for the second one.
+	require.Equal(t, "shared-isolated-1", namespaces["IsolatedA"])
+	require.Equal(t, "shared-isolated-1", namespaces["IsolatedB"])
+
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: "shared"}, &v1.Namespace{}), "expected reusable namespace to be kept")
+	err := c.Get(context.Background(), client.ObjectKey{Name: "shared-isolated-1"}, &v1.Namespace{})
+	require.Truef(t, apierrors.IsNotFound(err), "expected isolated namespace to be deleted, got %v", err)
+}
+
+func TestVerify(t *testing.T) {
+	c := newRecordingClient(t, &v1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance"},
+		Spec:       v1alpha2.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+		Status: v1alpha2.GatewayClassStatus{
+			Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+		},
+	})
+
+	ran := false
+	cSuite := mustNew(t, Options{Client: c, GatewayClassName: "gateway-conformance", MinChannel: StandardChannel})
+	cSuite.Verify(t, []ConformanceTest{{
+		ShortName:  "HTTPRouteMatching",
+		Manifests:  []string{"tests/httproute-matching.yaml"},
+		MinChannel: StandardChannel,
+		Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			ran = true
+		},
+	}})
+
+	require.True(t, ran, "expected test to run against existing resources")
+	require.Equal(t, "example.com/gateway-controller", cSuite.ControllerName)
+	require.Zero(t, c.writes, "expected Verify not to modify the cluster")
+}
+
+func TestAutoDetectFeatures(t *testing.T) {
+	gatewayClass := func(annotations map[string]string) *v1alpha2.GatewayClass {
+		return &v1alpha2.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance", Annotations: annotations},
+			Spec:       v1alpha2.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+			Status: v1alpha2.GatewayClassStatus{
+				Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+			},
+		}
+	}
+
+	t.Run("merges detected features with explicit ones", func(t *testing.T) {
+		c := newRecordingClient(t, gatewayClass(map[string]string{
+			SupportedFeaturesAnnotation: "ReferencePolicy, Mesh,",
+		}))
+		cSuite := mustNew(t, Options{
+			Client:             c,
+			GatewayClassName:   "gateway-conformance",
+			MinChannel:         StandardChannel,
+			SupportedFeatures:  []SupportedFeature{SupportReferencePolicy},
+			AutoDetectFeatures: true,
+		})
+		cSuite.Verify(t, nil)
+
+		require.Equal(t, []SupportedFeature{SupportReferencePolicy, "Mesh"}, cSuite.SupportedFeatures)
+	})
+
+	t.Run("falls back to explicit features without the annotation", func(t *testing.T) {
+		c := newRecordingClient(t, gatewayClass(nil))
+		cSuite := mustNew(t, Options{
+			Client:             c,
+			GatewayClassName:   "gateway-conformance",
+			MinChannel:         StandardChannel,
+			SupportedFeatures:  []SupportedFeature{SupportReferencePolicy},
+			AutoDetectFeatures: true,
+		})
+		cSuite.Verify(t, nil)
+
+		require.Equal(t, []SupportedFeature{SupportReferencePolicy}, cSuite.SupportedFeatures)
+	})
+
+	t.Run("ignores the annotation unless enabled", func(t *testing.T) {
+		c := newRecordingClient(t, gatewayClass(map[string]string{
+			SupportedFeaturesAnnotation: "ReferencePolicy",
+		}))
+		cSuite := mustNew(t, Options{Client: c, GatewayClassName: "gateway-conformance", MinChannel: StandardChannel})
+		cSuite.Verify(t, nil)
+
+		require.Empty(t, cSuite.SupportedFeatures)
+	})
+}
+
+func TestAPIReaderUsedForStatusReads(t *testing.T) {
+	// Only the reader knows about the accepted GatewayClass, as the client
+	// would if its cache lagged.
+	c := newRecordingClient(t)
+	reader := newRecordingClient(t, &v1alpha2.GatewayClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gateway-conformance"},
+		Spec:       v1alpha2.GatewayClassSpec{ControllerName: "example.com/gateway-controller"},
+		Status: v1alpha2.GatewayClassStatus{
+			Conditions: []metav1.Condition{{Type: "Accepted", Status: metav1.ConditionTrue}},
+		},
+	})
+
+	cSuite := mustNew(t, Options{Client: c, APIReader: reader, GatewayClassName: "gateway-conformance", MinChannel: StandardChannel})
+	cSuite.Verify(t, nil)
+
+	require.Equal(t, "example.com/gateway-controller", cSuite.ControllerName)
+	require.True(t, reader.fetchedKey[client.ObjectKey{Name: "gateway-conformance"}], "expected GatewayClass status to be read through the APIReader")
+	require.True(t, reader.listed["gateway-conformance-infra"], "expected readiness to be checked through the APIReader")
+	require.Empty(t, c.fetchedKey, "expected no status reads through the client")
+	require.Empty(t, c.listed, "expected no status reads through the client")
+}
+
+func TestReferenceGrantFeatureSpellings(t *testing.T) {
+	supported := []SupportedFeature{SupportReferenceGrant, SupportReferencePolicy}
+	exempt := []ExemptFeature{ExemptReferenceGrant, ExemptReferencePolicy}
+
+	for _, suiteFeature := range supported {
+		for _, testFeature := range supported {
+			cSuite := mustNew(t, Options{SupportedFeatures: []SupportedFeature{suiteFeature}})
+			category, _ := cSuite.skipReason(&ConformanceTest{ShortName: "HTTPRouteReferenceGrant", Features: []SupportedFeature{testFeature}, MinChannel: StandardChannel}, false)
+			require.Emptyf(t, category, "expected a suite supporting %s to run a test exercising %s", suiteFeature, testFeature)
+		}
+	}
+
+	for _, suiteExemption := range exempt {
+		for _, testExemption := range exempt {
+			cSuite := mustNew(t, Options{ExemptFeatures: []ExemptFeature{suiteExemption}})
+			category, _ := cSuite.skipReason(&ConformanceTest{ShortName: "HTTPRouteCrossNamespace", Exemptions: []ExemptFeature{testExemption}, MinChannel: StandardChannel}, false)
+			require.Equalf(t, SkipExemptFeature, category, "expected a suite exempting %s to skip a test exempted by %s", suiteExemption, testExemption)
+		}
+	}
+
+	cSuite := mustNew(t, Options{SupportedFeatures: []SupportedFeature{SupportReferencePolicy}, ConformanceProfiles: []ConformanceProfileName{HTTPProfileName}})
+	require.Equal(t, []SupportedFeature{SupportReferencePolicy, SupportHTTPRoute, SupportHTTPRouteMethodMatching, SupportHTTPRouteQueryParamMatching, SupportHTTPRouteRequestMirror}, cSuite.SupportedFeatures, "expected both spellings to be merged as one feature")
+}
+
+func TestMinChannelDefault(t *testing.T) {
+	cSuite := mustNew(t, Options{MinChannel: 0})
+	require.Equal(t, StandardChannel, cSuite.MinChannel, "expected MinChannel to default to StandardChannel")
+
+	category, _ := cSuite.skipReason(&ConformanceTest{ShortName: "Experimental", MinChannel: ExperimentalChannel}, false)
+	require.Equal(t, SkipChannel, category, "expected experimental tests to be skipped by default")
+}
+
+func TestCleanupOnFailureDefault(t *testing.T) {
+	require.True(t, mustNew(t, Options{}).CleanupOnFailure, "expected CleanupOnFailure to default to true")
+
+	cleanupOnFailure := false
+	require.False(t, mustNew(t, Options{CleanupOnFailure: &cleanupOnFailure}).CleanupOnFailure)
+}
+
+func TestInvalidAddressType(t *testing.T) {
+	_, err := New(Options{AddressType: "IPv5"})
+	require.EqualError(t, err, `invalid AddressType "IPv5", must be IPv4, IPv6 or PreferIPv6`)
+
+	cSuite := mustNew(t, Options{AddressType: kubernetes.IPv6AddressFamily})
+	require.Equal(t, kubernetes.IPv6AddressFamily, cSuite.AddressType)
+}
+
+func TestAuditLeaksTracksResources(t *testing.T) {
+	require.Nil(t, mustNew(t, Options{}).Applier.Tracker, "expected resources not to be tracked by default")
+
+	cSuite := mustNew(t, Options{AuditLeaks: true})
+	require.True(t, cSuite.AuditLeaks)
+	require.NotNil(t, cSuite.Applier.Tracker, "expected resources to be tracked for the audit")
+}
+
+func TestDiffApplied(t *testing.T) {
+	require.False(t, mustNew(t, Options{}).Applier.DiffApplied, "expected applied resources not to be diffed by default")
+	require.True(t, mustNew(t, Options{DiffApplied: true}).Applier.DiffApplied)
+	require.True(t, mustNew(t, Options{Debug: true}).Applier.DiffApplied, "expected Debug to imply DiffApplied")
+}
+
+func TestShouldRun(t *testing.T) {
+	testCases := []struct {
+		suiteChannel GatewayChannel
+		testChannel  GatewayChannel
+		expected     bool
+	}{
+		{suiteChannel: ExperimentalChannel, testChannel: ExperimentalChannel, expected: true},
+		{suiteChannel: ExperimentalChannel, testChannel: StandardChannel, expected: true},
+		{suiteChannel: StandardChannel, testChannel: ExperimentalChannel, expected: false},
+		{suiteChannel: StandardChannel, testChannel: StandardChannel, expected: true},
+	}
+
+	for _, tc := range testCases {
+		cSuite := mustNew(t, Options{MinChannel: tc.suiteChannel})
+		require.Equalf(t, tc.expected, cSuite.ShouldRun(tc.testChannel), "%s suite running a %s test", tc.suiteChannel, tc.testChannel)
+
+		category, _ := cSuite.skipReason(&ConformanceTest{ShortName: "Test", MinChannel: tc.testChannel}, false)
+		require.Equalf(t, !tc.expected, category == SkipChannel, "%s suite skipping a %s test", tc.suiteChannel, tc.testChannel)
+	}
+
+	require.False(t, mustNew(t, Options{MinChannel: ExperimentalChannel}).ShouldRun(0), "expected tests without a channel not to run")
+}
+
+func TestInvalidMinChannel(t *testing.T) {
+	_, err := New(Options{MinChannel: 3})
+	require.EqualError(t, err, "invalid MinChannel 3, must be ExperimentalChannel (1) or StandardChannel (2)")
+
+	require.True(t, ExperimentalChannel.Valid())
+	require.True(t, StandardChannel.Valid())
+	require.Equal(t, "experimental", ExperimentalChannel.String())
+	require.Equal(t, "unknown(3)", GatewayChannel(3).String())
+}
+
+func TestTimeoutConfigDefaults(t *testing.T) {
+	cSuite := mustNew(t, Options{})
+	require.Equal(t, config.DefaultTimeoutConfig(), cSuite.TimeoutConfig)
+
+	cSuite = mustNew(t, Options{TimeoutConfig: config.TimeoutConfig{
+		NamespacesMustBeReady: 30 * time.Second,
+		PollInterval:          100 * time.Millisecond,
+	}})
+	require.Equal(t, config.TimeoutConfig{
+		GatewayClassMustBeAccepted: 180 * time.Second,
+		NamespacesMustBeReady:      30 * time.Second,
+		NamespacesMustBeDeleted:    300 * time.Second,
+		ResourcesMustBeDeleted:     60 * time.Second,
+		GatewayMustHaveAddress:     180 * time.Second,
+		RouteMustHaveParents:       60 * time.Second,
+		ManifestsMustBeApplied:     60 * time.Second,
+		DeploymentsMustBeReady:     300 * time.Second,
+		PollInterval:               100 * time.Millisecond,
+		TestTimeout:                600 * time.Second,
+		RequestTimeout:             10 * time.Second,
+	}, cSuite.TimeoutConfig, "expected only unset timeouts to be defaulted")
+}
+
+func TestTimeoutsOfSuiteLiteral(t *testing.T) {
+	cSuite := &ConformanceTestSuite{TimeoutConfig: config.TimeoutConfig{GatewayClassMustBeAccepted: 5 * time.Second}}
+	expected := config.DefaultTimeoutConfig()
+	expected.GatewayClassMustBeAccepted = 5 * time.Second
+	require.Equal(t, expected, cSuite.timeouts(), "expected unset timeouts of suites not created by New to be defaulted")
+}
+
+func TestTLSConfigPassedToDefaultRoundTripper(t *testing.T) {
+	tlsConfig := &roundtripper.TLSConfig{CACertificates: []byte("-----BEGIN CERTIFICATE-----")}
+	cSuite := mustNew(t, Options{Debug: true, TLSConfig: tlsConfig})
+	require.Equal(t, &roundtripper.DefaultRoundTripper{Debug: true, TLSConfig: tlsConfig, TimeoutConfig: config.DefaultTimeoutConfig()}, cSuite.RoundTripper)
+}
+
+func TestTestTimeout(t *testing.T) {
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, TimeoutConfig: config.TimeoutConfig{TestTimeout: time.Hour}})
+
+	deadlines := map[string]time.Duration{}
+	recordDeadline := func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+		deadline, ok := ctx.Deadline()
+		require.True(t, ok, "expected the test context to have a deadline")
+		deadlines[t.Name()] = time.Until(deadline)
+	}
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{{
+			ShortName:  "Default",
+			MinChannel: StandardChannel,
+			Test:       recordDeadline,
+		}, {
+			ShortName:  "Custom",
+			MinChannel: StandardChannel,
+			Timeout:    time.Minute,
+			Test:       recordDeadline,
+		}})
+	})
+	require.InDelta(t, float64(time.Hour), float64(deadlines["TestTestTimeout/run/Default"]), float64(time.Minute))
+	require.InDelta(t, float64(time.Minute), float64(deadlines["TestTestTimeout/run/Custom"]), float64(time.Second))
+}
+
+func TestTestTimeoutOfSuiteLiteral(t *testing.T) {
+	cSuite := &ConformanceTestSuite{}
+	var remaining time.Duration
+	cSuite.runTimed(context.Background(), t, &ConformanceTest{
+		ShortName: "Literal",
+		Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			require.NoError(t, ctx.Err(), "expected the test context not to be done")
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok, "expected the test context to have a deadline")
+			remaining = time.Until(deadline)
+		},
+	})
+	require.InDelta(t, float64(config.DefaultTimeoutConfig().TestTimeout), float64(remaining), float64(time.Minute))
+}
+
+// errorRecorder records the errors tests report.
+type errorRecorder struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestWatchTimeout(t *testing.T) {
+	t.Run("timed out", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		recorder := &errorRecorder{}
+		stop := watchTimeout(ctx, recorder, "HTTPRouteSlow", 10*time.Millisecond)
+		<-ctx.Done()
+		stop()
+		require.Equal(t, []string{"HTTPRouteSlow timed out after 10ms"}, recorder.errors)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		recorder := &errorRecorder{}
+		stop := watchTimeout(ctx, recorder, "HTTPRouteFast", time.Minute)
+		stop()
+		cancel()
+		require.Empty(t, recorder.errors)
+	})
+}
+
+func TestRunWithContext(t *testing.T) {
+	type contextKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "run"))
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel})
+
+	var value interface{}
+	var cancelled bool
+	t.Run("run", func(t *testing.T) {
+		cSuite.RunWithContext(ctx, t, []ConformanceTest{{
+			ShortName:  "Cancelled",
+			MinChannel: StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				value = ctx.Value(contextKey{})
+				cancel()
+				select {
+				case <-ctx.Done():
+					cancelled = true
+				case <-time.After(time.Second):
+				}
+			},
+		}})
+	})
+	require.Equal(t, "run", value, "expected the test context to be derived from the run context")
+	require.True(t, cancelled, "expected cancelling the run context to cancel the test context")
+}
+
+func TestFailFast(t *testing.T) {
+	for _, failFast := range []bool{true, false} {
+		cSuite := mustNew(t, Options{MinChannel: StandardChannel, FailFast: failFast})
+		// Stands in for a test that failed earlier in the run, as a failing
+		// test would fail this one too.
+		cSuite.results.failed = true
+
+		var ran []string
+		var mu sync.Mutex
+		record := func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+			mu.Lock()
+			defer mu.Unlock()
+			ran = append(ran, t.Name())
+		}
+		t.Run(fmt.Sprintf("fail fast %t", failFast), func(t *testing.T) {
+			cSuite.Run(t, []ConformanceTest{{
+				ShortName:  "Sequential",
+				MinChannel: StandardChannel,
+				Test:       record,
+			}, {
+				ShortName:  "Parallel",
+				MinChannel: StandardChannel,
+				Parallel:   true,
+				Test:       record,
+			}, {
+				ShortName:  "Experimental",
+				MinChannel: ExperimentalChannel,
+				Test:       record,
+			}})
+		})
+
+		results := map[string]TestResult{}
+		for _, result := range cSuite.results.results {
+			results[result.ShortName] = result
+		}
+		require.Equal(t, SkipChannel, results["Experimental"].SkipCategory, "expected tests skipped anyway to keep their reason")
+		if !failFast {
+			require.Len(t, ran, 2, "expected tests to run without FailFast")
+			continue
+		}
+		require.Empty(t, ran, "expected no test to start once a test failed")
+		for _, shortName := range []string{"Sequential", "Parallel"} {
+			require.Equal(t, TestSkipped, results[shortName].Outcome)
+			require.Equal(t, SkipFailFast, results[shortName].SkipCategory)
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	var calls []string
+	cSuite := mustNew(t, Options{
+		MinChannel: StandardChannel,
+		BeforeEach: func(t *testing.T, test ConformanceTest) {
+			calls = append(calls, "before "+test.ShortName)
+		},
+		AfterEach: func(t *testing.T, test ConformanceTest, err error) {
+			require.NoError(t, err)
+			calls = append(calls, fmt.Sprintf("after %s skipped=%t", test.ShortName, t.Skipped()))
+		},
+	})
+
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{{
+			ShortName:  "Passing",
+			MinChannel: StandardChannel,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				calls = append(calls, "test Passing")
+			},
+		}, {
+			ShortName:  "Experimental",
+			MinChannel: ExperimentalChannel,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}})
+	})
+
+	require.Equal(t, []string{
+		"before Passing",
+		"test Passing",
+		"after Passing skipped=false",
+		"before Experimental",
+		"after Experimental skipped=true",
+	}, calls)
+}
+
+// hookErrors records the errors reported for panicking hooks.
+type hookErrors []string
+
+func (e *hookErrors) Errorf(format string, args ...interface{}) {
+	*e = append(*e, fmt.Sprintf(format, args...))
+}
+
+func TestCallHookRecoversPanics(t *testing.T) {
+	errs := &hookErrors{}
+	require.True(t, callHook(errs, "BeforeEach", func() {}))
+	require.Empty(t, *errs)
+
+	require.False(t, callHook(errs, "AfterEach", func() { panic("boom") }))
+	require.Equal(t, []string{"AfterEach hook panicked: boom"}, []string(*errs))
+}
+
+func TestDryRun(t *testing.T) {
+	c := newRecordingClient(t)
+	cSuite := mustNew(t, Options{
+		Client:     c,
+		MinChannel: StandardChannel,
+		RunTest:    "HTTPRoute*",
+		DryRun:     true,
+	})
+
+	ran := false
+	tests := []ConformanceTest{{
+		ShortName:  "HTTPRouteSimple",
+		Manifests:  []string{"tests/httproute-simple-same-namespace.yaml"},
+		MinChannel: StandardChannel,
+		Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) { ran = true },
+	}, {
+		ShortName:  "HTTPRouteReferencePolicy",
+		Features:   []SupportedFeature{SupportReferencePolicy},
+		MinChannel: StandardChannel,
+		Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) { ran = true },
+	}, {
+		ShortName:  "HTTPRouteExperimental",
+		MinChannel: ExperimentalChannel,
+		Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) { ran = true },
+	}, {
+		ShortName:  "GatewaySecret",
+		MinChannel: StandardChannel,
+		Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) { ran = true },
+	}}
+
+	cSuite.Setup(t)
+	cSuite.Run(t, tests)
+
+	require.False(t, ran, "expected no test to run in dry-run mode")
+	require.Zero(t, c.writes, "expected dry-run mode not to modify the cluster")
+	require.Empty(t, c.fetchedKey, "expected dry-run mode not to read from the cluster")
+	require.Empty(t, c.listed, "expected dry-run mode not to read from the cluster")
+	require.Equal(t, []TestDecision{
+		{ShortName: "HTTPRouteSimple", Run: true},
+		{ShortName: "HTTPRouteReferencePolicy", SkipCategory: SkipUnsupportedFeature, SkipReason: "suite does not support ReferencePolicy"},
+		{ShortName: "HTTPRouteExperimental", SkipCategory: SkipChannel, SkipReason: "only testing standard channel"},
+		{ShortName: "GatewaySecret", SkipCategory: SkipNotSelected, SkipReason: "not selected by RunTest"},
+	}, cSuite.Plan(tests))
+}
+
+func TestSkipBaseManifests(t *testing.T) {
+	_, err := New(Options{SkipBaseManifests: true})
+	require.EqualError(t, err, "SkipBaseManifests requires the ProvisionedGateway the tests run against")
+
+	ipAddress := v1alpha2.IPAddressType
+	gw := &v1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "provisioned", Namespace: "gateway-pipeline"},
+		Spec: v1alpha2.GatewaySpec{
+			GatewayClassName: "gateway-conformance",
+			Listeners:        []v1alpha2.Listener{{Name: "http", Port: 8080, Protocol: v1alpha2.HTTPProtocolType}},
+		},
+		Status: v1alpha2.GatewayStatus{
+			Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}},
+			Addresses:  []v1alpha2.GatewayAddress{{Type: &ipAddress, Value: "192.0.2.10"}},
+		},
+	}
+	c := newRecordingClient(t, acceptedGatewayClass("gateway-conformance", "example.com/gateway-controller"), gw)
+
+	provisioned := &ProvisionedGateway{Name: "provisioned", Namespace: "gateway-pipeline"}
+	cSuite := mustNew(t, Options{
+		Client:             c,
+		GatewayClassName:   "gateway-conformance",
+		SkipBaseManifests:  true,
+		ProvisionedGateway: provisioned,
+	})
+	cSuite.Setup(t)
+
+	require.Zero(t, c.writes, "expected base manifests not to be applied")
+	require.Equal(t, "192.0.2.10:8080", cSuite.ProvisionedGateway.Address)
+	require.Equal(t, v1alpha2.IPAddressType, cSuite.ProvisionedGateway.AddressType)
+	require.Empty(t, provisioned.Address, "expected the options not to be modified")
+	require.False(t, c.listed["gateway-conformance-infra"], "expected the base namespaces not to be waited for")
+}
+
+func TestFeatureValidation(t *testing.T) {
+	testCases := []struct {
+		name              string
+		supportedFeatures []SupportedFeature
+		exemptFeatures    []ExemptFeature
+		expectedErr       string
+	}{{
+		name:              "feature of an aggregate supported alone",
+		supportedFeatures: []SupportedFeature{SupportHTTPRouteRequestMirror},
+	}, {
+		name:              "feature without prerequisites",
+		supportedFeatures: []SupportedFeature{SupportTCPRoute},
+		exemptFeatures:    []ExemptFeature{ExemptReferenceGrant},
+	}, {
+		name:              "feature supported and exempted",
+		supportedFeatures: []SupportedFeature{SupportReferenceGrant},
+		exemptFeatures:    []ExemptFeature{ExemptReferenceGrant},
+		expectedErr:       "feature ReferenceGrant can't be both supported and exempted",
+	}, {
+		name:              "renamed feature supported and exempted",
+		supportedFeatures: []SupportedFeature{SupportReferencePolicy},
+		exemptFeatures:    []ExemptFeature{ExemptReferenceGrant},
+		expectedErr:       "feature ReferencePolicy can't be both supported and exempted",
+	}}
+
+	for _, tc := range testCases {
+		_, err := New(Options{SupportedFeatures: tc.supportedFeatures, ExemptFeatures: tc.exemptFeatures})
+		if tc.expectedErr == "" {
+			require.NoErrorf(t, err, "expected %s to be valid", tc.name)
+			continue
+		}
+		require.EqualErrorf(t, err, tc.expectedErr, "unexpected error for %s", tc.name)
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	dependencies := map[SupportedFeature][]SupportedFeature{
+		"HTTPRouteExtension": {SupportHTTPRoute},
+	}
+	testCases := []struct {
+		name              string
+		supportedFeatures []SupportedFeature
+		expectedErr       string
+	}{{
+		name:              "prerequisite supported",
+		supportedFeatures: []SupportedFeature{SupportHTTPRoute, "HTTPRouteExtension"},
+	}, {
+		name:              "feature without prerequisites",
+		supportedFeatures: []SupportedFeature{SupportTCPRoute},
+	}, {
+		name:              "prerequisite missing",
+		supportedFeatures: []SupportedFeature{"HTTPRouteExtension"},
+		expectedErr:       "supported feature HTTPRouteExtension requires HTTPRoute, which must be supported as well",
+	}, {
+		name:              "prerequisite missing despite features of its aggregate",
+		supportedFeatures: []SupportedFeature{SupportHTTPRouteMethodMatching, "HTTPRouteExtension"},
+		expectedErr:       "supported feature HTTPRouteExtension requires HTTPRoute, which must be supported as well",
+	}}
+
+	for _, tc := range testCases {
+		err := checkDependencies(tc.supportedFeatures, dependencies)
+		if tc.expectedErr == "" {
+			require.NoErrorf(t, err, "expected %s to be valid", tc.name)
+			continue
+		}
+		require.EqualErrorf(t, err, tc.expectedErr, "unexpected error for %s", tc.name)
+	}
+}
+
+func TestFeatureAggregates(t *testing.T) {
+	require.Equal(t, []SupportedFeature{
+		SupportHTTPRoute,
+		SupportHTTPRouteMethodMatching,
+		SupportHTTPRouteQueryParamMatching,
+		SupportHTTPRouteRequestMirror,
+		SupportReferenceGrant,
+	}, expandFeatures([]SupportedFeature{SupportHTTPRoute, SupportHTTPRouteMethodMatching, SupportReferenceGrant}))
+
+	newTest := func(name string, features ...SupportedFeature) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			Features:   features,
+			MinChannel: StandardChannel,
+			Test:       func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {},
+		}
+	}
+	tests := []ConformanceTest{
+		newTest("MethodMatching", SupportHTTPRouteMethodMatching),
+		newTest("QueryParamMatching", SupportHTTPRouteQueryParamMatching),
+		newTest("MethodAndQueryParamMatching", SupportHTTPRouteMethodMatching, SupportHTTPRouteQueryParamMatching),
+	}
+	outcomes := func(supported ...SupportedFeature) (map[string]TestOutcome, ConformanceReport) {
+		cSuite := mustNew(t, Options{SupportedFeatures: supported, MinChannel: StandardChannel})
+		t.Run(fmt.Sprint(supported), func(t *testing.T) {
+			cSuite.Run(t, tests)
+		})
+
+		byName := map[string]TestOutcome{}
+		for _, result := range cSuite.Results() {
+			byName[result.ShortName] = result.Outcome
+		}
+		report, err := cSuite.Report()
+		require.NoError(t, err)
+		return byName, report
+	}
+
+	byName, report := outcomes(SupportHTTPRouteMethodMatching)
+	require.Equal(t, map[string]TestOutcome{
+		"MethodMatching":              TestPassed,
+		"QueryParamMatching":          TestSkipped,
+		"MethodAndQueryParamMatching": TestSkipped,
+	}, byName)
+	require.Equal(t, []SupportedFeature{SupportHTTPRouteMethodMatching}, report.SupportedFeatures)
+
+	byName, report = outcomes(SupportHTTPRoute)
+	require.Equal(t, map[string]TestOutcome{
+		"MethodMatching":              TestPassed,
+		"QueryParamMatching":          TestPassed,
+		"MethodAndQueryParamMatching": TestPassed,
+	}, byName)
+	require.Equal(t, []SupportedFeature{
+		SupportHTTPRoute,
+		SupportHTTPRouteMethodMatching,
+		SupportHTTPRouteQueryParamMatching,
+		SupportHTTPRouteRequestMirror,
+	}, report.SupportedFeatures, "expected the report to list the expanded features")
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ANSI escape sequences used to colorize outcomes.
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+)
+
+var outcomeColors = map[TestOutcome]string{
+	TestPassed:  colorGreen,
+	TestFailed:  colorRed,
+	TestSkipped: colorYellow,
+}
+
+// PrintSummary renders the results of the tests executed so far as an
+// aligned table with one row per test. Outcomes are colorized if
+// ColorSummary is set.
+func (suite *ConformanceTestSuite) PrintSummary(w io.Writer) {
+	suite.results.mu.Lock()
+	results := make([]TestResult, len(suite.results.results))
+	copy(results, suite.results.results)
+	suite.results.mu.Unlock()
+
+	header := []string{"TEST", "OUTCOME", "DURATION", "SKIP REASON"}
+	rows := [][]string{header}
+	for _, result := range results {
+		rows = append(rows, []string{
+			result.ShortName,
+			string(result.Outcome),
+			result.Duration.Round(time.Millisecond).String(),
+			result.SkipReason,
+		})
+	}
+
+	// Widths are computed by hand rather than with text/tabwriter as escape
+	// sequences would otherwise count towards the width of the outcomes.
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = fmt.Sprintf("%-*s", widths[j], cell)
+		}
+		if color, ok := outcomeColors[TestOutcome(row[1])]; ok && suite.ColorSummary && i > 0 {
+			cells[1] = color + cells[1] + colorReset
+		}
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintSummary(t *testing.T) {
+	out := &bytes.Buffer{}
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, SummaryOutput: out})
+
+	noop := func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {}
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{
+			{ShortName: "Passing", MinChannel: StandardChannel, Test: noop},
+			{ShortName: "Unsupported", MinChannel: StandardChannel, Features: []SupportedFeature{SupportReferencePolicy}, Test: noop},
+			{ShortName: "Experimental", MinChannel: ExperimentalChannel, Test: noop},
+		})
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4, "expected a header and a row per executed test:\n%s", out)
+	require.Regexp(t, `^TEST\s+OUTCOME\s+DURATION\s+SKIP REASON$`, lines[0])
+	require.Regexp(t, `^Passing\s+Passed\s+\S+$`, lines[1])
+	require.Regexp(t, `^Unsupported\s+Skipped\s+\S+\s+suite does not support ReferencePolicy$`, lines[2])
+	require.Regexp(t, `^Experimental\s+Skipped\s+\S+\s+only testing standard channel$`, lines[3])
+
+	// Columns are aligned.
+	column := strings.Index(lines[0], "OUTCOME")
+	for _, line := range lines[1:] {
+		require.Equalf(t, "  ", line[column-2:column], "misaligned row %q", line)
+		require.NotEqualf(t, byte(' '), line[column], "misaligned row %q", line)
+	}
+
+	colored := &bytes.Buffer{}
+	cSuite.ColorSummary = true
+	cSuite.PrintSummary(colored)
+	require.Contains(t, colored.String(), colorGreen+"Passed ")
+	require.Contains(t, colored.String(), colorYellow+"Skipped")
+}
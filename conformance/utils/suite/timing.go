@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+// SlowestResults returns the results of at most n of the tests executed so
+// far, sorted from the slowest to the fastest by TotalDuration. Since every
+// test is timed on its own, this is meaningful whether or not tests ran in
+// parallel.
+func (suite *ConformanceTestSuite) SlowestResults(n int) []TestResult {
+	suite.results.mu.Lock()
+	results := make([]TestResult, len(suite.results.results))
+	copy(results, suite.results.results)
+	suite.results.mu.Unlock()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].TotalDuration > results[j].TotalDuration
+	})
+	if len(results) > n {
+		results = results[:n]
+	}
+	return results
+}
+
+// logSlowestTests logs the SlowestTests slowest tests, suggesting to mark as
+// Slow those exceeding SlowTestThreshold.
+func (suite *ConformanceTestSuite) logSlowestTests(t *testing.T) {
+	results := suite.SlowestResults(suite.SlowestTests)
+	if len(results) == 0 {
+		return
+	}
+
+	t.Logf("Slowest %d tests:", len(results))
+	for _, result := range results {
+		t.Logf("  %s: %s (test function: %s)", result.ShortName, result.TotalDuration.Round(time.Millisecond), result.Duration.Round(time.Millisecond))
+		if suite.SlowTestThreshold > 0 && result.TotalDuration > suite.SlowTestThreshold && !result.Slow {
+			t.Logf("  %s took longer than %s, consider marking it Slow", result.ShortName, suite.SlowTestThreshold)
+		}
+	}
+}
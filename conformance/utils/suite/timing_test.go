@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowestResults(t *testing.T) {
+	cSuite := mustNew(t, Options{MinChannel: StandardChannel, SlowestTests: 2, SlowTestThreshold: 50 * time.Millisecond})
+
+	sleepingTest := func(shortName string, d time.Duration, slow bool) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  shortName,
+			MinChannel: StandardChannel,
+			Slow:       slow,
+			Parallel:   true,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				time.Sleep(d)
+			},
+		}
+	}
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, []ConformanceTest{
+			sleepingTest("Fast", 0, false),
+			sleepingTest("Slowest", 150*time.Millisecond, true),
+			sleepingTest("Slower", 100*time.Millisecond, false),
+		})
+	})
+
+	slowest := cSuite.SlowestResults(2)
+	require.Len(t, slowest, 2)
+	require.Equal(t, "Slowest", slowest[0].ShortName)
+	require.True(t, slowest[0].Slow)
+	require.Equal(t, "Slower", slowest[1].ShortName)
+	for _, result := range slowest {
+		require.GreaterOrEqual(t, int64(result.TotalDuration), int64(result.Duration), "expected the total duration of %s to include its Test function", result.ShortName)
+	}
+	require.Len(t, cSuite.SlowestResults(10), 3)
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+	"sigs.k8s.io/gateway-api/conformance/utils/kubernetes"
+)
+
+// WorkerNamespacePrefix prefixes the namespaces of the Workers, which are
+// suffixed with the index of the worker, e.g. gateway-conformance-worker-0.
+const WorkerNamespacePrefix = "gateway-conformance-worker"
+
+// worker is one of the Workers tests are dispatched to. The tests running on
+// a worker get its namespace for resources without a namespace in their
+// manifests and its share of the ValidUniqueListenerPorts, so that they
+// don't collide with the tests running on other workers.
+type worker struct {
+	namespace string
+	ports     []v1alpha2.PortNumber
+	// created is set once the namespace of the worker was created. Only
+	// the test holding the worker accesses it.
+	created bool
+}
+
+// newWorkers returns a pool of n workers, sharing the ports between them.
+// An error is returned if there are fewer ports than workers.
+func newWorkers(n int, ports []v1alpha2.PortNumber) ([]*worker, error) {
+	if len(ports) > 0 && len(ports) < n {
+		return nil, fmt.Errorf("%d Workers need at least as many ValidUniqueListenerPorts, got %d", n, len(ports))
+	}
+
+	workers := make([]*worker, n)
+	share := len(ports) / n
+	for i := range workers {
+		workers[i] = &worker{namespace: fmt.Sprintf("%s-%d", WorkerNamespacePrefix, i)}
+		if share > 0 {
+			workers[i].ports = ports[i*share : (i+1)*share]
+		}
+	}
+	return workers, nil
+}
+
+// pooled returns true if the test is dispatched to one of the Workers.
+func (suite *ConformanceTestSuite) pooled(test *ConformanceTest) bool {
+	return suite.workers != nil && test.Pooled && !test.Exclusive
+}
+
+// checkWorkerPorts returns an error if a manifest of one of the pooled tests
+// the suite runs has more listeners than the ValidUniqueListenerPorts of a
+// worker.
+func (suite *ConformanceTestSuite) checkWorkerPorts(ctx context.Context, tests []ConformanceTest) error {
+	share := len(suite.workerList[0].ports)
+	if share == 0 {
+		return nil
+	}
+
+	for i := range tests {
+		test := &tests[i]
+		if !suite.pooled(test) || !suite.runsResources(*test) || !suite.runsTest(*test) {
+			continue
+		}
+		if category, _ := suite.skipReason(test, false); category != "" {
+			continue
+		}
+		for _, manifest := range test.Manifests {
+			listeners, err := suite.Applier.CountListeners(ctx, manifest, suite.GatewayClassName)
+			if err != nil {
+				return fmt.Errorf("error counting the listeners of %s: %w", manifest, err)
+			}
+			if listeners > share {
+				return fmt.Errorf("%s needs %d ValidUniqueListenerPorts for %s, but each of the %d Workers only gets %d", test.ShortName, listeners, manifest, suite.Workers, share)
+			}
+		}
+	}
+	return nil
+}
+
+// acquireWorker waits for one of the Workers to be free and returns it along
+// with a function releasing it. The test fails if ctx is cancelled first.
+func (suite *ConformanceTestSuite) acquireWorker(ctx context.Context, t *testing.T) (*worker, func()) {
+	select {
+	case w := <-suite.workers:
+		t.Logf("Running on worker %s", w.namespace)
+		return w, func() { suite.workers <- w }
+	case <-ctx.Done():
+		t.Fatalf("Aborted while waiting for one of the %d workers: %v", suite.Workers, ctx.Err())
+		return nil, nil
+	}
+}
+
+// ensureWorkerNamespace creates the namespace of the worker unless it was
+// already created, or exists from a previous run, within the
+// NamespacesMustBeReady timeout.
+func (suite *ConformanceTestSuite) ensureWorkerNamespace(ctx context.Context, t *testing.T, w *worker) {
+	if w.created {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, suite.timeouts().NamespacesMustBeReady)
+	defer cancel()
+
+	err := suite.Client.Get(ctx, types.NamespacedName{Name: w.namespace}, &v1.Namespace{})
+	if apierrors.IsNotFound(err) {
+		t.Logf("Creating %s worker namespace", w.namespace)
+		err = suite.Client.Create(ctx, suite.newNamespace(w.namespace))
+		if apierrors.IsAlreadyExists(err) {
+			err = nil
+		}
+	}
+	require.NoErrorf(t, err, "error ensuring %s worker namespace exists", w.namespace)
+	w.created = true
+}
+
+// deleteWorkerNamespaces deletes the namespaces created for the Workers, once
+// every test is done, and waits for them to be gone up to the
+// NamespacesMustBeDeleted timeout.
+func (suite *ConformanceTestSuite) deleteWorkerNamespaces(t *testing.T) {
+	timeoutConfig := suite.timeouts()
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutConfig.NamespacesMustBeDeleted)
+	defer cancel()
+
+	var deleted []string
+	for _, w := range suite.workerList {
+		if !w.created {
+			continue
+		}
+		t.Logf("Deleting %s worker namespace", w.namespace)
+		err := suite.Client.Delete(ctx, suite.newNamespace(w.namespace))
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("error deleting %s worker namespace: %v", w.namespace, err)
+			continue
+		}
+		deleted = append(deleted, w.namespace)
+		w.created = false
+	}
+	if len(deleted) > 0 {
+		kubernetes.NamespacesMustBeDeletedWithContext(ctx, t, suite.APIReader, timeoutConfig, deleted)
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package suite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
+
+func TestWorkersOptions(t *testing.T) {
+	_, err := New(Options{Workers: -1})
+	require.EqualError(t, err, "Workers must not be negative, got -1")
+
+	_, err = New(Options{Workers: 2, ReusableNamespace: "shared"})
+	require.EqualError(t, err, "Workers can't be combined with a ReusableNamespace")
+
+	_, err = New(Options{Workers: 3, ValidUniqueListenerPorts: []v1alpha2.PortNumber{8080, 8081}})
+	require.EqualError(t, err, "3 Workers need at least as many ValidUniqueListenerPorts, got 2")
+
+	require.Nil(t, mustNew(t, Options{Workers: 1}).workers, "expected a single worker not to start a pool")
+}
+
+func TestNewWorkers(t *testing.T) {
+	workers, err := newWorkers(2, []v1alpha2.PortNumber{8080, 8081, 8082, 8083, 8084})
+	require.NoError(t, err)
+	require.Len(t, workers, 2)
+	require.Equal(t, "gateway-conformance-worker-0", workers[0].namespace)
+	require.Equal(t, []v1alpha2.PortNumber{8080, 8081}, workers[0].ports)
+	require.Equal(t, "gateway-conformance-worker-1", workers[1].namespace)
+	require.Equal(t, []v1alpha2.PortNumber{8082, 8083}, workers[1].ports)
+
+	workers, err = newWorkers(2, nil)
+	require.NoError(t, err)
+	require.Nil(t, workers[0].ports)
+}
+
+func TestAcquireWorker(t *testing.T) {
+	cSuite := mustNew(t, Options{Workers: 2})
+
+	var (
+		mu     sync.Mutex
+		inUse  = map[*worker]bool{}
+		shared bool
+		wg     sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w, release := cSuite.acquireWorker(context.Background(), t)
+			defer release()
+
+			mu.Lock()
+			shared = shared || inUse[w]
+			inUse[w] = true
+			mu.Unlock()
+
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			inUse[w] = false
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.False(t, shared, "expected a worker to run a single test at once")
+	require.Len(t, inUse, 2, "expected the tests to share the Workers")
+}
+
+func TestRunWithWorkers(t *testing.T) {
+	c := newRecordingClient(t)
+
+	var (
+		mu                  sync.Mutex
+		running, maxRunning int
+		exclusiveAlone      = true
+		namespaces          = map[string]string{}
+	)
+	newTest := func(name string, pooled, exclusive bool) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			MinChannel: StandardChannel,
+			Pooled:     pooled,
+			Exclusive:  exclusive,
+			Test: func(ctx context.Context, t *testing.T, s *ConformanceTestSuite) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				if !pooled && running > 1 {
+					exclusiveAlone = false
+				}
+				namespaces[name] = s.TestNamespace(t)
+				mu.Unlock()
+
+				time.Sleep(20 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+			},
+		}
+	}
+
+	var tests []ConformanceTest
+	for i := 0; i < 4; i++ {
+		tests = append(tests, newTest(fmt.Sprintf("Pooled%d", i), true, false))
+	}
+	tests = append(tests, newTest("NotPooled", false, false), newTest("Exclusive", true, true))
+
+	cSuite := mustNew(t, Options{Client: c, MinChannel: StandardChannel, Workers: 2})
+	t.Run("run", func(t *testing.T) {
+		cSuite.Run(t, tests)
+	})
+
+	// How many tests actually run at once also depends on the -parallel flag
+	// of go test.
+	require.LessOrEqual(t, maxRunning, 2, "expected at most Workers tests to run at once")
+	require.True(t, exclusiveAlone, "expected the tests not pooled to run on their own")
+	require.Empty(t, namespaces["NotPooled"], "expected the test not opting in to pooling not to run on a worker")
+	require.Empty(t, namespaces["Exclusive"], "expected the exclusive test not to run on a worker")
+	for i := 0; i < 4; i++ {
+		require.Contains(t, []string{"gateway-conformance-worker-0", "gateway-conformance-worker-1"}, namespaces[fmt.Sprintf("Pooled%d", i)])
+	}
+
+	results := cSuite.Results()
+	require.Len(t, results, 6)
+	for _, result := range results {
+		require.Equalf(t, TestPassed, result.Outcome, "unexpected outcome of %s", result.ShortName)
+	}
+
+	for _, name := range []string{"gateway-conformance-worker-0", "gateway-conformance-worker-1"} {
+		err := c.Get(context.Background(), client.ObjectKey{Name: name}, &v1.Namespace{})
+		require.Truef(t, apierrors.IsNotFound(err), "expected %s namespace to be deleted, got %v", name, err)
+	}
+}
+
+func TestCheckWorkerPorts(t *testing.T) {
+	cSuite := mustNew(t, Options{
+		MinChannel:               StandardChannel,
+		Workers:                  2,
+		ValidUniqueListenerPorts: []v1alpha2.PortNumber{8080, 8081, 8082, 8083},
+	})
+	newTest := func(name string, pooled bool, features ...SupportedFeature) ConformanceTest {
+		return ConformanceTest{
+			ShortName:  name,
+			MinChannel: StandardChannel,
+			Features:   features,
+			Pooled:     pooled,
+			Manifests:  []string{"tests/httproute-listener-hostname-matching.yaml"},
+		}
+	}
+
+	require.NoError(t, cSuite.checkWorkerPorts(context.Background(), []ConformanceTest{
+		newTest("NotPooled", false),
+		newTest("Unsupported", true, SupportTCPRoute),
+	}), "expected only the pooled tests the suite runs to be checked")
+
+	err := cSuite.checkWorkerPorts(context.Background(), []ConformanceTest{newTest("HostnameMatching", true)})
+	require.EqualError(t, err, "HostnameMatching needs 3 ValidUniqueListenerPorts for tests/httproute-listener-hostname-matching.yaml, but each of the 2 Workers only gets 2")
+
+	cSuite = mustNew(t, Options{MinChannel: StandardChannel, Workers: 2})
+	require.NoError(t, cSuite.checkWorkerPorts(context.Background(), []ConformanceTest{newTest("HostnameMatching", true)}), "expected ports not to be checked without ValidUniqueListenerPorts")
+}